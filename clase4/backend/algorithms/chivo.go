@@ -18,6 +18,7 @@ type SoftmaxRegression struct {
 	Lr        float64
 	NIter     int
 	RegLambda float64
+	Seed      int64 // de los pesos iniciales; 0 = Fit elige una y la deja aca
 }
 
 // NewSoftmaxRegression crea el modelo con hiperparámetros
@@ -98,7 +99,8 @@ func (m *SoftmaxRegression) forward(X *mat.Dense) (*mat.Dense, *mat.Dense) {
 	return scores, probs
 }
 
-// Fit entrena el modelo sobre X (n x d) y y (n,)
+// Fit entrena el modelo sobre X (n x d) y y (n,). Con la misma Seed y los
+// mismos datos da los mismos pesos.
 func (m *SoftmaxRegression) Fit(X *mat.Dense, y []int) {
 	nSamples, nFeatures := X.Dims()
 	if nSamples == 0 {
@@ -117,12 +119,15 @@ func (m *SoftmaxRegression) Fit(X *mat.Dense, y []int) {
 	}
 
 	// inicializar W y B
-	rand.Seed(time.Now().UnixNano())
+	if m.Seed == 0 {
+		m.Seed = time.Now().UnixNano()
+	}
+	rng := rand.New(rand.NewSource(m.Seed))
 
 	if m.W == nil {
 		dataW := make([]float64, nFeatures*nClasses)
 		for i := range dataW {
-			dataW[i] = 0.01 * rand.NormFloat64()
+			dataW[i] = 0.01 * rng.NormFloat64()
 		}
 		m.W = mat.NewDense(nFeatures, nClasses, dataW)
 	}
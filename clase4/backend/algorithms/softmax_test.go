@@ -0,0 +1,46 @@
+package algorithms
+
+import (
+	"testing"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// fitConSeed entrena con 3 clases en 2D, como cmd/softmaxtoy
+func fitConSeed(seed int64) *SoftmaxRegression {
+	X := mat.NewDense(6, 2, []float64{
+		-1.0, -1.2,
+		-0.8, -0.9,
+		0.0, 1.0,
+		0.2, 0.8,
+		2.0, 2.1,
+		1.8, 1.9,
+	})
+	y := []int{0, 0, 1, 1, 2, 2}
+	m := NewSoftmaxRegression(0.1, 50, 1e-3)
+	m.Seed = seed
+	m.Fit(X, y)
+	return m
+}
+
+func TestFitMismaSeed(t *testing.T) {
+	a, b := fitConSeed(7), fitConSeed(7)
+	if !mat.Equal(a.W, b.W) {
+		t.Errorf("misma seed, distinto W:\n%v\n%v", mat.Formatted(a.W), mat.Formatted(b.W))
+	}
+	if !mat.Equal(a.B, b.B) {
+		t.Errorf("misma seed, distinto B: %v %v", a.B.RawVector().Data, b.B.RawVector().Data)
+	}
+}
+
+func TestFitOtraSeed(t *testing.T) {
+	if a, b := fitConSeed(7), fitConSeed(8); mat.Equal(a.W, b.W) {
+		t.Error("las seeds 7 y 8 dieron el mismo W")
+	}
+}
+
+func TestFitEligeSeed(t *testing.T) {
+	if m := fitConSeed(0); m.Seed == 0 {
+		t.Error("Fit no guardo la seed que eligio")
+	}
+}
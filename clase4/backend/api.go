@@ -2,60 +2,28 @@ package main
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
-	"github.com/mndrix/golog"
-)
-
-func recomendarCarreras(m golog.Machine, aptitud, habilidad1, interes1, habilidad2, interes2 string) []CarreraRecomendada {
-	query := "carrera(Fac, Carr, Apt, Hab, Int)."
-	solutions := m.ProveAll(query)
-
-	results := []CarreraRecomendada{}
-
-	for _, sol := range solutions {
-		apt := sol.ByName_("Apt").String()
-		hab := sol.ByName_("Hab").String()
-		ints := sol.ByName_("Int").String()
-
-		matchCount := 0
-		if apt == aptitud {
-			matchCount++
-		}
-		if hab == habilidad1 || hab == habilidad2 {
-			matchCount++
-		}
-		if ints == interes1 || ints == interes2 {
-			matchCount++
-		}
-		if habilidad1 == ints || habilidad2 == apt || interes1 == hab || interes2 == hab {
-			matchCount++ // peso extra si hay cruce interesante
-		}
 
-		matchPercent := float64(matchCount) / 5.0 * 100.0
-
-		results = append(results, CarreraRecomendada{
-			Facultad: sol.ByName_("Fac").String(),
-			Carrera:  sol.ByName_("Carr").String(),
-			Match:    matchPercent,
-		})
-	}
-	return results
-}
-
-// Agregar numeros para recomendacion
-func cargarProlog(path string) string {
-	data, err := os.ReadFile(path)
-	if err != nil {
-		panic(err)
-	}
-	return string(data)
-}
+	"unmatch/backend/cuestionario"
+	"unmatch/backend/expedientes"
+	"unmatch/backend/feedback"
+	"unmatch/backend/hibrido"
+	"unmatch/backend/inferencia"
+	"unmatch/backend/lote"
+	"unmatch/backend/reporte"
+)
 
 type PerfilEstudiante struct {
 	Aptitud    string `json:"aptitud"`
@@ -63,18 +31,93 @@ type PerfilEstudiante struct {
 	Interes    string `json:"interes"`
 	Habilidad2 string `json:"habilidad2"`
 	Interes2   string `json:"interes2"`
+	// Opcionales para el ranking
+	TopN     int     `json:"top_n"`     // 0 = todas
+	MinMatch float64 `json:"min_match"` // porcentaje minimo
+	Facultad string  `json:"facultad"`  // solo carreras de esta facultad
+	// Filtros sobre la ficha de la carrera
+	Sede      string             `json:"sede"`      // solo carreras dictadas en esta sede
+	Modalidad string             `json:"modalidad"` // presencial | semipresencial | virtual
+	Notas     map[string]float64 `json:"notas"`     // materia -> nota (0 a 100)
+	// Modo "hibrido" mezcla las reglas con el modelo softmax
+	Modo   string   `json:"modo"`   // reglas (por defecto) | hibrido
+	Mezcla *float64 `json:"mezcla"` // peso del modelo, 0 a 1
+	// Si se indica, la recomendacion se guarda en el historial
+	EstudianteID string `json:"estudiante_id"`
 }
 
-type CarreraRecomendada struct {
-	Facultad string  `json:"facultad"`
-	Carrera  string  `json:"carrera"`
-	Match    float64 `json:"match"`
+// FiltroFicha son los filtros de sede, modalidad y notas minimas
+func (p PerfilEstudiante) FiltroFicha() inferencia.FiltroFicha {
+	return inferencia.FiltroFicha{Sede: p.Sede, Modalidad: p.Modalidad, Notas: p.Notas}
+}
+
+// PerfilCarrera son los campos que usa el puntaje de match
+func (p PerfilEstudiante) PerfilCarrera() inferencia.PerfilCarrera {
+	return inferencia.PerfilCarrera{
+		Aptitud: p.Aptitud, Habilidad: p.Habilidad, Interes: p.Interes,
+		Habilidad2: p.Habilidad2, Interes2: p.Interes2,
+	}
+}
+
+// validarPerfil normaliza los campos del perfil y los revisa contra el
+// vocabulario de carrera/5 y las sedes de las fichas; devuelve un error
+// por cada valor desconocido o nota fuera de rango.
+func validarPerfil(perfil *PerfilEstudiante, cat inferencia.Catalogo) []inferencia.ErrorCampo {
+	campos := []struct {
+		nombre  string
+		valor   *string
+		validos []string
+	}{
+		{"aptitud", &perfil.Aptitud, cat.Vocabulario.Aptitudes},
+		{"habilidad", &perfil.Habilidad, cat.Vocabulario.Habilidades},
+		{"interes", &perfil.Interes, cat.Vocabulario.Intereses},
+		{"habilidad2", &perfil.Habilidad2, cat.Vocabulario.Habilidades},
+		{"interes2", &perfil.Interes2, cat.Vocabulario.Intereses},
+		{"facultad", &perfil.Facultad, cat.Facultades},
+		{"sede", &perfil.Sede, cat.Sedes},
+		{"modalidad", &perfil.Modalidad, inferencia.Modalidades},
+	}
+
+	errores := []inferencia.ErrorCampo{}
+	for _, c := range campos {
+		*c.valor = inferencia.NormalizarTermino(*c.valor)
+		if e := inferencia.Validar(c.nombre, *c.valor, c.validos); e != nil {
+			errores = append(errores, *e)
+		}
+	}
+
+	// las materias se comparan con las que piden nota minima; una materia
+	// que ninguna carrera pide no filtra nada y se acepta igual
+	notas := map[string]float64{}
+	for materia, nota := range perfil.Notas {
+		materia = inferencia.NormalizarTermino(materia)
+		if nota < 0 || nota > 100 {
+			errores = append(errores, inferencia.ErrorCampo{Campo: "notas." + materia, Valor: fmt.Sprint(nota), Validos: []string{"0 a 100"}})
+			continue
+		}
+		notas[materia] = nota
+	}
+	perfil.Notas = notas
+	return errores
 }
 
 type DiagnosticoRequest struct {
 	Texto string `json:"texto"`
 }
 
+// respuestaErrorProlog traduce un error de consulta Prolog a HTTP:
+// 503 si se agoto el tiempo, 422 si se excedieron soluciones o pasos.
+func respuestaErrorProlog(c *fiber.Ctx, err error) error {
+	status := fiber.StatusInternalServerError
+	switch {
+	case errors.Is(err, inferencia.ErrTiempoAgotado):
+		status = fiber.StatusServiceUnavailable
+	case errors.Is(err, inferencia.ErrDemasiadasSoluciones), errors.Is(err, inferencia.ErrDemasiadosPasos):
+		status = fiber.StatusUnprocessableEntity
+	}
+	return c.Status(status).JSON(fiber.Map{"error": "Error al consultar la base de conocimiento", "detalle": err.Error()})
+}
+
 // Esta funcion llama a HuggingFace
 func llamarHuggingFace(texto string) (interface{}, error) {
 	fmt.Print("HuggingFaceCall")
@@ -140,29 +183,332 @@ func main() {
 		return c.Next()
 	})
 
-	programa := cargarProlog("./prolog/conocimiento.pl")
-	// Cargar las inferencias
-	m := golog.NewMachine()
-	m = m.Consult(programa)
+	// Cargar la base de conocimiento (medicamentos + carreras)
+	m, err := inferencia.CargarMaquina(inferencia.DefaultConocimientoPath, inferencia.DefaultCarrerasPath)
+	if err != nil {
+		panic(err)
+	}
+
+	// Catalogo y vocabulario derivados de carrera/5
+	catalogo, err := inferencia.CargarCatalogo(context.Background(), m)
+	if err != nil {
+		panic(err)
+	}
+
+	// Pesos del puntaje: los aprendidos con cmd/ajustarpesos si existen
+	pesos, err := inferencia.CargarPesos(inferencia.DefaultPesosPath)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println("Pesos de match:", pesos.Origen)
+	fb := &feedback.Almacen{Path: feedback.DefaultFeedbackPath}
+
+	// Modelo del recomendador hibrido (cmd/entrenarhibrido); es opcional
+	modelo, err := hibrido.CargarModelo(hibrido.DefaultModeloPath)
+	if errors.Is(err, hibrido.ErrSinModelo) {
+		fmt.Println("Modo hibrido deshabilitado:", err)
+	} else if err != nil {
+		panic(err)
+	}
+
+	// Expedientes de estudiantes (perfil, historial y notas)
+	versionKB, err := inferencia.VersionKB(inferencia.DefaultConocimientoPath, inferencia.DefaultCarrerasPath)
+	if err != nil {
+		panic(err)
+	}
+	almacen, err := expedientes.Abrir(expedientes.DefaultDBPath)
+	if err != nil {
+		panic(err)
+	}
+	defer almacen.Cerrar()
+	exped := &servicioExpedientes{almacen: almacen, catalogo: catalogo, versionKB: versionKB}
+
+	// Cuestionario adaptativo: arma el perfil a partir de preguntas
+	cuest, err := nuevoServicioCuestionario(m, catalogo, pesos, cuestionario.DefaultBancosDir, cuestionario.DefaultSesionesDir)
+	if err != nil {
+		panic(err)
+	}
+
+	// Exploracion para orientadores: escenarios y carreras parecidas
+	fichas, err := inferencia.CargarFichas(context.Background(), m)
+	if err != nil {
+		panic(err)
+	}
+	explorar := &servicioExplorar{m: m, catalogo: catalogo, pesos: pesos, fichas: fichas}
 
 	app.Get("/", func(c *fiber.Ctx) error {
 		return c.SendString("Servidor UniMatch funcionando 🧠")
 	})
 
+	app.Get("/carreras", func(c *fiber.Ctx) error {
+		return c.JSON(fiber.Map{"carreras": catalogo.Carreras})
+	})
+
+	app.Get("/facultades", func(c *fiber.Ctx) error {
+		return c.JSON(fiber.Map{"facultades": catalogo.Facultades})
+	})
+
+	app.Get("/vocabulario", func(c *fiber.Ctx) error {
+		return c.JSON(catalogo.Vocabulario)
+	})
+
+	// Valores de los filtros sede, modalidad y notas de /recomendar
+	app.Get("/sedes", func(c *fiber.Ctx) error {
+		return c.JSON(fiber.Map{"sedes": catalogo.Sedes, "modalidades": inferencia.Modalidades, "materias": catalogo.Materias})
+	})
+
+	app.Get("/carreras/:facultad/:carrera/similares", explorar.similares)
+	app.Post("/explorar/que-pasa-si", explorar.quePasaSi)
+
+	app.Get("/cuestionarios", cuest.listarBancos)
+	app.Post("/cuestionarios/sesiones", cuest.crearSesion)
+	app.Get("/cuestionarios/sesiones/:id", cuest.verSesion)
+	app.Post("/cuestionarios/sesiones/:id/respuestas", cuest.responder)
+
+	app.Post("/estudiantes", exped.guardarEstudiante)
+	app.Get("/estudiantes/:id/historial", exped.historial)
+	app.Post("/estudiantes/:id/notas", exped.agregarNota)
+	app.Get("/estudiantes/:id/comparar", exped.comparar)
+
 	app.Post("/recomendar", func(c *fiber.Ctx) error {
 		var perfil PerfilEstudiante
 		if err := c.BodyParser(&perfil); err != nil {
 			return c.Status(400).SendString("Error de entrada.")
 		}
 		fmt.Println("Perfil recibido:", perfil)
-		resultados := recomendarCarreras(m, perfil.Aptitud, perfil.Habilidad, perfil.Interes, perfil.Interes2, perfil.Habilidad2)
-		if len(resultados) == 0 {
-			return c.JSON(fiber.Map{"mensaje": "No se encontraron coincidencias."})
+		if errores := validarPerfil(&perfil, catalogo); len(errores) > 0 {
+			return c.Status(400).JSON(fiber.Map{
+				"error":   "Hay valores que no existen en la base de carreras. Consulte /vocabulario.",
+				"errores": errores,
+			})
+		}
+		if perfil.Modo != "" && perfil.Modo != "reglas" && perfil.Modo != "hibrido" {
+			return c.Status(400).JSON(fiber.Map{"error": "modo '" + perfil.Modo + "' no existe, use reglas o hibrido"})
+		}
+		if perfil.EstudianteID != "" {
+			if _, err := exped.almacen.Estudiante(perfil.EstudianteID); err != nil {
+				return errorExpediente(c, err)
+			}
+		}
+		resultados, err := inferencia.RecomendarCarrerasConPesos(c.UserContext(), m, perfil.PerfilCarrera(), pesos)
+		if err != nil {
+			return respuestaErrorProlog(c, err)
+		}
+		resultados, descartadas := catalogo.FiltrarPorFicha(resultados, perfil.FiltroFicha())
+		filtro := inferencia.FiltroRanking{
+			TopN:     perfil.TopN,
+			MinMatch: perfil.MinMatch,
+			Facultad: perfil.Facultad,
 		}
 
-		return c.JSON(fiber.Map{
-			"recomendaciones": resultados,
+		var ranking []inferencia.CarreraRecomendada
+		resp := fiber.Map{}
+		if perfil.Modo == "hibrido" {
+			if modelo == nil {
+				return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": hibrido.ErrSinModelo.Error()})
+			}
+			mezcla := modelo.Mezcla
+			if perfil.Mezcla != nil {
+				mezcla = *perfil.Mezcla
+			}
+			if err := hibrido.ValidarMezcla(mezcla); err != nil {
+				return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+			}
+			hibridas := hibrido.Combinar(resultados, modelo.Probabilidades(perfil.PerfilCarrera()), mezcla, filtro)
+			for _, h := range hibridas {
+				ranking = append(ranking, inferencia.CarreraRecomendada{Facultad: h.Facultad, Carrera: h.Carrera, Match: h.Match})
+			}
+			resp["modo"] = "hibrido"
+			resp["mezcla"] = mezcla
+			resp["recomendaciones"] = hibridas
+		} else {
+			ranking = inferencia.RankearCarreras(resultados, filtro)
+			resp["recomendaciones"] = ranking
+		}
+		if len(ranking) == 0 {
+			sinResultados := fiber.Map{"mensaje": "No se encontraron coincidencias."}
+			if len(descartadas) > 0 {
+				sinResultados["descartadas_por_nota"] = descartadas
+			}
+			return c.JSON(sinResultados)
+		}
+		resp["por_facultad"] = inferencia.AgruparPorFacultad(ranking)
+		if len(descartadas) > 0 {
+			resp["descartadas_por_nota"] = descartadas
+		}
+
+		// Historial del estudiante, si la consulta es para un expediente
+		if perfil.EstudianteID != "" {
+			id, err := exped.guardarRecomendacion(perfil.EstudianteID, perfil, perfil.Modo, pesos.Origen, ranking)
+			if err != nil {
+				return errorExpediente(c, err)
+			}
+			resp["historial_id"] = id
+		}
+		return c.JSON(resp)
+	})
+
+	// Recomendaciones para una planilla (CSV o XLSX) de un curso completo.
+	// Form multipart: archivo, top_n (por defecto 3), formato de salida.
+	app.Post("/recomendar/lote", func(c *fiber.Ctx) error {
+		fh, err := c.FormFile("archivo")
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": "Falta el archivo (campo 'archivo')."})
+		}
+		f, err := fh.Open()
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+		}
+		data, err := io.ReadAll(f)
+		f.Close()
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+		}
+
+		entrada := lote.DetectarFormato(fh.Filename, data)
+		salida := c.FormValue("formato", entrada)
+		if salida != lote.FormatoCSV && salida != lote.FormatoXLSX {
+			return c.Status(400).JSON(fiber.Map{"error": "formato '" + salida + "' no existe, use csv o xlsx"})
+		}
+		topN, err := strconv.Atoi(c.FormValue("top_n", "3"))
+		if err != nil || topN < 1 {
+			return c.Status(400).JSON(fiber.Map{"error": "top_n debe ser un entero mayor a 0"})
+		}
+
+		filas, err := lote.Leer(data, entrada)
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+		}
+		resultados := lote.Procesar(c.UserContext(), m, filas, lote.Opciones{
+			TopN:  topN,
+			Pesos: pesos,
+			Validar: func(p *inferencia.PerfilCarrera) []string {
+				perfil := PerfilEstudiante{Aptitud: p.Aptitud, Habilidad: p.Habilidad, Interes: p.Interes, Habilidad2: p.Habilidad2, Interes2: p.Interes2}
+				errores := []string{}
+				for _, e := range validarPerfil(&perfil, catalogo) {
+					errores = append(errores, e.Error())
+				}
+				*p = perfil.PerfilCarrera()
+				return errores
+			},
 		})
+
+		var buf bytes.Buffer
+		if err := lote.Escribir(&buf, resultados, topN, salida); err != nil {
+			return c.Status(500).JSON(fiber.Map{"error": "No se pudo generar la planilla", "detalle": err.Error()})
+		}
+		nombre := strings.TrimSuffix(fh.Filename, filepath.Ext(fh.Filename)) + "_recomendaciones." + salida
+		c.Attachment(nombre)
+		c.Set("X-Filas", strconv.Itoa(len(resultados)))
+		c.Set("X-Filas-Con-Error", strconv.Itoa(lote.ConErrores(resultados)))
+		return c.Send(buf.Bytes())
+	})
+
+	// Reporte imprimible: POST /reporte?formato=html|pdf con el perfil
+	app.Post("/reporte", func(c *fiber.Ctx) error {
+		var perfil PerfilEstudiante
+		if err := c.BodyParser(&perfil); err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": "Error de entrada."})
+		}
+		formato := c.Query("formato", "html")
+		if formato != "html" && formato != "pdf" {
+			return c.Status(400).JSON(fiber.Map{"error": "formato '" + formato + "' no existe, use html o pdf"})
+		}
+		if errores := validarPerfil(&perfil, catalogo); len(errores) > 0 {
+			return c.Status(400).JSON(fiber.Map{
+				"error":   "Hay valores que no existen en la base de carreras. Consulte /vocabulario.",
+				"errores": errores,
+			})
+		}
+		datos := reporte.Datos{Fecha: time.Now(), VersionKB: versionKB, Pesos: pesos.Origen, Perfil: perfil.PerfilCarrera()}
+		if perfil.EstudianteID != "" {
+			e, err := exped.almacen.Estudiante(perfil.EstudianteID)
+			if err != nil {
+				return errorExpediente(c, err)
+			}
+			datos.Nombre = e.Nombre
+		}
+
+		fichas, err := inferencia.CargarFichas(c.UserContext(), m)
+		if err != nil {
+			return respuestaErrorProlog(c, err)
+		}
+		resultados, err := inferencia.RecomendarCarrerasConPesos(c.UserContext(), m, datos.Perfil, pesos)
+		if err != nil {
+			return respuestaErrorProlog(c, err)
+		}
+		resultados, _ = catalogo.FiltrarPorFicha(resultados, perfil.FiltroFicha())
+		if perfil.TopN == 0 {
+			perfil.TopN = 5
+		}
+		ranking := inferencia.RankearCarreras(resultados, inferencia.FiltroRanking{
+			TopN:     perfil.TopN,
+			MinMatch: perfil.MinMatch,
+			Facultad: perfil.Facultad,
+		})
+		datos.Carreras = reporte.Explicar(datos.Perfil, fichas, ranking)
+
+		var buf bytes.Buffer
+		if formato == "pdf" {
+			err = reporte.PDF(&buf, datos)
+			c.Type("pdf")
+			c.Attachment("reporte_unimatch.pdf")
+		} else {
+			err = reporte.HTML(&buf, datos)
+			c.Type("html", "utf-8")
+		}
+		if err != nil {
+			return c.Status(500).JSON(fiber.Map{"error": "No se pudo generar el reporte", "detalle": err.Error()})
+		}
+		return c.Send(buf.Bytes())
+	})
+
+	app.Get("/pesos", func(c *fiber.Ctx) error {
+		return c.JSON(pesos)
+	})
+
+	// Feedback: calificacion de una recomendacion o carrera elegida.
+	// cmd/ajustarpesos lo usa para aprender los pesos del puntaje.
+	app.Post("/feedback", func(c *fiber.Ctx) error {
+		var req struct {
+			Tipo         string           `json:"tipo"`
+			Rol          string           `json:"rol"`
+			Perfil       PerfilEstudiante `json:"perfil"`
+			Carrera      string           `json:"carrera"`
+			Calificacion int              `json:"calificacion"`
+		}
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": "Error de entrada."})
+		}
+		errores := validarPerfil(&req.Perfil, catalogo)
+		claves := []string{}
+		for _, carr := range catalogo.Carreras {
+			claves = append(claves, carr.Facultad+"/"+carr.Carrera)
+		}
+		req.Carrera = inferencia.NormalizarTermino(req.Carrera)
+		if req.Carrera == "" {
+			errores = append(errores, inferencia.ErrorCampo{Campo: "carrera", Validos: claves})
+		} else if e := inferencia.Validar("carrera", req.Carrera, claves); e != nil {
+			errores = append(errores, *e)
+		}
+		if len(errores) > 0 {
+			return c.Status(400).JSON(fiber.Map{"error": "Hay valores que no existen en la base de carreras.", "errores": errores})
+		}
+
+		reg := feedback.Registro{
+			Tipo:         req.Tipo,
+			Rol:          req.Rol,
+			Perfil:       req.Perfil.PerfilCarrera(),
+			Carrera:      req.Carrera,
+			Calificacion: req.Calificacion,
+		}
+		if err := reg.Validar(); err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+		}
+		if err := fb.Agregar(reg); err != nil {
+			return c.Status(500).JSON(fiber.Map{"error": "No se pudo guardar el feedback", "detalle": err.Error()})
+		}
+		return c.Status(fiber.StatusCreated).JSON(fiber.Map{"mensaje": "Feedback registrado."})
 	})
 
 	// Diagnostico de Texto Medico
@@ -0,0 +1,76 @@
+// ajustarpesos es el job offline que aprende los pesos del puntaje de
+// match a partir del feedback guardado por POST /feedback. La API lee
+// el resultado al arrancar.
+//
+// Uso (desde backend/):
+//
+//	go run ./cmd/ajustarpesos -feedback ./datos/feedback.jsonl -o ./weights/pesos_match.json
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"unmatch/backend/feedback"
+	"unmatch/backend/inferencia"
+)
+
+func main() {
+	kbPath := flag.String("kb", inferencia.DefaultCarrerasPath, "base de carreras (fichas)")
+	fbPath := flag.String("feedback", feedback.DefaultFeedbackPath, "registros de feedback (JSONL)")
+	salida := flag.String("o", inferencia.DefaultPesosPath, "archivo de pesos a escribir")
+	hp := feedback.HiperparametrosPorDefecto
+	flag.Float64Var(&hp.Lr, "lr", hp.Lr, "tasa de aprendizaje")
+	flag.IntVar(&hp.NIter, "iter", hp.NIter, "iteraciones de descenso por gradiente")
+	flag.Float64Var(&hp.RegLambda, "lambda", hp.RegLambda, "regularizacion L2")
+	flag.Int64Var(&hp.Seed, "seed", 0, "seed de los pesos iniciales (0 = al azar)")
+	flag.IntVar(&hp.Minimo, "min", hp.Minimo, "ejemplos positivos minimos")
+	soloMostrar := flag.Bool("n", false, "solo mostrar los pesos, no escribir el archivo")
+	flag.Parse()
+
+	m, err := inferencia.CargarMaquina(*kbPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		os.Exit(2)
+	}
+	fichas, err := inferencia.CargarFichas(context.Background(), m)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		os.Exit(2)
+	}
+	registros, err := feedback.Leer(*fbPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		os.Exit(2)
+	}
+
+	pesos, rep, err := feedback.AjustarPesos(registros, fichas, hp)
+	fmt.Printf("Registros: %d, usados: %d, ignorados: %d\n", len(registros), rep.Muestras, rep.Ignorados)
+	if errors.Is(err, feedback.ErrPocosDatos) {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		os.Exit(1)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		os.Exit(2)
+	}
+
+	fmt.Printf("Accuracy softmax: %.4f (seed %d)\n", rep.Accuracy, pesos.Seed)
+	fmt.Printf("Carrera elegida en el primer puesto: %.1f%% (por defecto) -> %.1f%% (aprendidos)\n",
+		rep.AciertoPorDefecto*100, rep.AciertoAprendido*100)
+	for i, w := range pesos.Vector() {
+		fmt.Printf("  %-10s %.3f\n", inferencia.Criterios[i], w)
+	}
+
+	if *soloMostrar {
+		return
+	}
+	if err := inferencia.GuardarPesos(*salida, pesos); err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		os.Exit(2)
+	}
+	fmt.Println("Pesos escritos en", *salida)
+}
@@ -0,0 +1,76 @@
+// entrenarhibrido entrena el modelo softmax del recomendador hibrido con
+// los hechos carrera/5 (y el feedback positivo, si hay) y lo guarda para
+// que la API lo use en /recomendar con "modo": "hibrido".
+//
+// Uso (desde backend/):
+//
+//	go run ./cmd/entrenarhibrido -mezcla 0.3
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"unmatch/backend/feedback"
+	"unmatch/backend/hibrido"
+	"unmatch/backend/inferencia"
+)
+
+func main() {
+	kbPath := flag.String("kb", inferencia.DefaultCarrerasPath, "archivo de hechos carrera/5")
+	fbPath := flag.String("feedback", feedback.DefaultFeedbackPath, "registros de feedback (JSONL), opcional")
+	salida := flag.String("o", hibrido.DefaultModeloPath, "archivo del modelo a escribir")
+	mezcla := flag.Float64("mezcla", hibrido.MezclaPorDefecto, "peso del modelo en el puntaje final (0 a 1)")
+	hp := feedback.HiperparametrosPorDefecto
+	flag.Float64Var(&hp.Lr, "lr", hp.Lr, "tasa de aprendizaje")
+	flag.IntVar(&hp.NIter, "iter", hp.NIter, "iteraciones de descenso por gradiente")
+	flag.Float64Var(&hp.RegLambda, "lambda", hp.RegLambda, "regularizacion L2")
+	flag.Int64Var(&hp.Seed, "seed", 0, "seed de los pesos iniciales (0 = al azar)")
+	flag.Parse()
+
+	if err := hibrido.ValidarMezcla(*mezcla); err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		os.Exit(2)
+	}
+
+	ctx := context.Background()
+	m, err := inferencia.CargarMaquina(*kbPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		os.Exit(2)
+	}
+	hechos, err := inferencia.LeerHechosCarrera(ctx, m)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		os.Exit(2)
+	}
+	cat, err := inferencia.CargarCatalogo(ctx, m)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		os.Exit(2)
+	}
+	registros, err := feedback.Leer(*fbPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		os.Exit(2)
+	}
+
+	mod, err := hibrido.Entrenar(hechos, cat.Vocabulario, registros, hp)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		os.Exit(1)
+	}
+	mod.Mezcla = *mezcla
+
+	fmt.Printf("Ejemplos: %d (%d hechos + %d de feedback)\n", mod.Muestras, len(hechos), mod.Muestras-len(hechos))
+	fmt.Printf("Features: %d, carreras: %d\n", len(mod.Codificador.Nombres()), len(mod.Carreras))
+	fmt.Printf("Accuracy entrenamiento: %.4f (seed %d)\n", mod.Accuracy, mod.Seed)
+
+	if err := mod.Guardar(*salida); err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		os.Exit(2)
+	}
+	fmt.Println("Modelo escrito en", *salida)
+}
@@ -0,0 +1,274 @@
+// kbtest corre fixtures YAML contra la base de carreras real usando el
+// mismo camino que /recomendar (inferencia.RecomendarCarreras, el filtro
+// de la ficha y el ranking) y reporta que hechos carrera_atributo/4,
+// carrera_sede/3 y carrera_nota_minima/3 no ejercita ningun caso.
+//
+// Uso (desde backend/):
+//
+//	go run ./cmd/kbtest -fixtures ./prolog/fixtures
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"unmatch/backend/inferencia"
+)
+
+// Perfil son los mismos campos que PerfilEstudiante en la API
+type Perfil struct {
+	Aptitud    string             `yaml:"aptitud"`
+	Habilidad  string             `yaml:"habilidad"`
+	Interes    string             `yaml:"interes"`
+	Habilidad2 string             `yaml:"habilidad2"`
+	Interes2   string             `yaml:"interes2"`
+	Sede       string             `yaml:"sede"`
+	Modalidad  string             `yaml:"modalidad"`
+	Notas      map[string]float64 `yaml:"notas"`
+}
+
+// Caso es un fixture: perfil -> ranking esperado de carreras (las
+// primeras posiciones, de mayor a menor match) y, si se indican, las
+// carreras descartadas por nota minima.
+type Caso struct {
+	Nombre      string   `yaml:"nombre"`
+	Perfil      Perfil   `yaml:"perfil"`
+	Ranking     []string `yaml:"ranking"`
+	Descartadas []string `yaml:"descartadas"`
+}
+
+type archivoFixtures struct {
+	Casos []Caso `yaml:"casos"`
+}
+
+func main() {
+	kbPath := flag.String("kb", inferencia.DefaultCarrerasPath, "base de carreras (fichas)")
+	fixtures := flag.String("fixtures", "./prolog/fixtures", "archivo .yaml o carpeta con fixtures")
+	flag.Parse()
+
+	m, err := inferencia.CargarMaquina(*kbPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		os.Exit(2)
+	}
+
+	casos, err := cargarFixtures(*fixtures)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		os.Exit(2)
+	}
+	if len(casos) == 0 {
+		fmt.Fprintln(os.Stderr, "error: no se encontraron casos en", *fixtures)
+		os.Exit(2)
+	}
+
+	ctx := context.Background()
+	fichas, err := inferencia.CargarFichas(ctx, m)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		os.Exit(2)
+	}
+	cat, err := inferencia.CargarCatalogo(ctx, m)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		os.Exit(2)
+	}
+	porClave := map[string]inferencia.Ficha{}
+	for _, f := range fichas {
+		porClave[f.Clave()] = f
+	}
+	ejercitados := map[string]bool{}
+	fallos := 0
+
+	for _, caso := range casos {
+		p := caso.Perfil
+		resultados, err := inferencia.RecomendarCarreras(ctx, m, p.Aptitud, p.Habilidad, p.Interes, p.Habilidad2, p.Interes2)
+		if err != nil {
+			fallos++
+			fmt.Printf("FALLO %s\n      error: %v\n", caso.Nombre, err)
+			continue
+		}
+
+		// mismo filtro y ranking que /recomendar, recortado al largo esperado
+		filtro := inferencia.FiltroFicha{Sede: p.Sede, Modalidad: p.Modalidad, Notas: p.Notas}
+		resultados, descartadas := cat.FiltrarPorFicha(resultados, filtro)
+		ranking := inferencia.RankearCarreras(resultados, inferencia.FiltroRanking{TopN: len(caso.Ranking)})
+		obtenido := []string{}
+		for _, r := range ranking {
+			obtenido = append(obtenido, r.Carrera)
+			marcarHechos(ejercitados, porClave[r.Clave()], p, filtro, true)
+		}
+		obtenidasDescartadas := []string{}
+		for _, d := range descartadas {
+			obtenidasDescartadas = append(obtenidasDescartadas, d.Carrera)
+			marcarHechos(ejercitados, porClave[d.Facultad+"/"+d.Carrera], p, filtro, false)
+		}
+
+		problemas := comparar("", caso.Ranking, obtenido)
+		if caso.Descartadas != nil {
+			problemas = append(problemas, comparar("descartadas ", caso.Descartadas, obtenidasDescartadas)...)
+		}
+		if len(problemas) == 0 {
+			fmt.Printf("OK    %s\n", caso.Nombre)
+			continue
+		}
+		fallos++
+		fmt.Printf("FALLO %s\n", caso.Nombre)
+		for _, l := range problemas {
+			fmt.Printf("      %s\n", l)
+		}
+	}
+
+	// Cobertura: hechos de las fichas que ningun caso llego a usar
+	hechos := []string{}
+	for _, f := range fichas {
+		hechos = append(hechos, hechosFicha(f)...)
+	}
+	sinCubrir := []string{}
+	for _, h := range hechos {
+		if !ejercitados[h] {
+			sinCubrir = append(sinCubrir, h)
+		}
+	}
+
+	fmt.Println()
+	fmt.Printf("Casos: %d, fallos: %d\n", len(casos), fallos)
+	fmt.Printf("Cobertura: %d/%d hechos ejercitados\n", len(hechos)-len(sinCubrir), len(hechos))
+	for _, h := range sinCubrir {
+		fmt.Println("  sin cubrir:", h)
+	}
+
+	if fallos > 0 {
+		os.Exit(1)
+	}
+}
+
+// comparar devuelve las lineas esperado/obtenido si las listas difieren
+func comparar(prefijo string, esperado, obtenido []string) []string {
+	if strings.Join(obtenido, ",") == strings.Join(esperado, ",") {
+		return nil
+	}
+	return []string{
+		fmt.Sprintf("%sesperado: %s", prefijo, strings.Join(esperado, ", ")),
+		fmt.Sprintf("%sobtenido: %s", prefijo, strings.Join(obtenido, ", ")),
+	}
+}
+
+// Texto de cada hecho de una ficha, como figura en la cobertura
+func hechoAtributo(f inferencia.Ficha, tipo string, a inferencia.AtributoPonderado) string {
+	return fmt.Sprintf("carrera_atributo(%s, %s, %s, %g)", f.Carrera, tipo, a.Valor, a.Peso)
+}
+
+func hechoSede(f inferencia.Ficha, s inferencia.SedeCarrera) string {
+	return fmt.Sprintf("carrera_sede(%s, %s, %s)", f.Carrera, s.Sede, s.Modalidad)
+}
+
+func hechoNota(f inferencia.Ficha, n inferencia.NotaMinima) string {
+	return fmt.Sprintf("carrera_nota_minima(%s, %s, %g)", f.Carrera, n.Materia, n.Nota)
+}
+
+// atributosFicha devuelve los atributos de la ficha por tipo
+func atributosFicha(f inferencia.Ficha) map[string][]inferencia.AtributoPonderado {
+	return map[string][]inferencia.AtributoPonderado{
+		inferencia.AtributoAptitud:   f.Aptitudes,
+		inferencia.AtributoHabilidad: f.Habilidades,
+		inferencia.AtributoInteres:   f.Intereses,
+	}
+}
+
+// hechosFicha lista los hechos auxiliares de la ficha en el orden de la base
+func hechosFicha(f inferencia.Ficha) []string {
+	hechos := []string{}
+	atributos := atributosFicha(f)
+	for _, tipo := range []string{inferencia.AtributoAptitud, inferencia.AtributoHabilidad, inferencia.AtributoInteres} {
+		for _, a := range atributos[tipo] {
+			hechos = append(hechos, hechoAtributo(f, tipo, a))
+		}
+	}
+	for _, s := range f.Sedes {
+		hechos = append(hechos, hechoSede(f, s))
+	}
+	for _, n := range f.NotasMinimas {
+		hechos = append(hechos, hechoNota(f, n))
+	}
+	return hechos
+}
+
+// marcarHechos marca los hechos de la ficha que uso el caso: los
+// atributos que suman al match (solo si la carrera quedo en el ranking),
+// las sedes que pasan el filtro de sede/modalidad y las notas minimas de
+// las materias que el perfil informa.
+func marcarHechos(ejercitados map[string]bool, f inferencia.Ficha, p Perfil, filtro inferencia.FiltroFicha, enRanking bool) {
+	if enRanking {
+		perfil := inferencia.PerfilCarrera{Aptitud: p.Aptitud, Habilidad: p.Habilidad, Interes: p.Interes, Habilidad2: p.Habilidad2, Interes2: p.Interes2}
+		atributos := atributosFicha(f)
+		for tipo, valores := range perfil.ValoresComparados() {
+			for _, a := range atributos[tipo] {
+				for _, v := range valores {
+					if v != "" && v == a.Valor {
+						ejercitados[hechoAtributo(f, tipo, a)] = true
+					}
+				}
+			}
+		}
+		if filtro.Sede != "" || filtro.Modalidad != "" {
+			for _, s := range f.Sedes {
+				if (filtro.Sede == "" || s.Sede == filtro.Sede) && (filtro.Modalidad == "" || s.Modalidad == filtro.Modalidad) {
+					ejercitados[hechoSede(f, s)] = true
+				}
+			}
+		}
+	}
+	for _, n := range f.NotasMinimas {
+		if _, ok := filtro.Notas[n.Materia]; ok {
+			ejercitados[hechoNota(f, n)] = true
+		}
+	}
+}
+
+// cargarFixtures lee un archivo o todos los .yaml/.yml de una carpeta
+func cargarFixtures(path string) ([]Caso, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	archivos := []string{path}
+	if info.IsDir() {
+		archivos = nil
+		for _, patron := range []string{"*.yaml", "*.yml"} {
+			encontrados, err := filepath.Glob(filepath.Join(path, patron))
+			if err != nil {
+				return nil, err
+			}
+			archivos = append(archivos, encontrados...)
+		}
+		sort.Strings(archivos)
+	}
+
+	casos := []Caso{}
+	for _, archivo := range archivos {
+		data, err := os.ReadFile(archivo)
+		if err != nil {
+			return nil, err
+		}
+		var af archivoFixtures
+		if err := yaml.Unmarshal(data, &af); err != nil {
+			return nil, fmt.Errorf("%s: %w", archivo, err)
+		}
+		for i, c := range af.Casos {
+			if c.Nombre == "" {
+				c.Nombre = fmt.Sprintf("%s#%d", filepath.Base(archivo), i+1)
+			}
+			casos = append(casos, c)
+		}
+	}
+	return casos, nil
+}
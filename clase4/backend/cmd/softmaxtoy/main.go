@@ -1,19 +1,44 @@
+// softmaxtoy entrena el modelo Softmax con un dataset muy simple de 3
+// clases en 2D y genera archivos CSV para que puedas graficar los puntos
+// y sus probabilidades. Sirve para probar algorithms.SoftmaxRegression
+// sin la base de carreras.
+//
+// Uso (desde backend/):
+//
+//	go run ./cmd/softmaxtoy -iter 2000 -dir ./weights
 package main
 
 import (
 	"encoding/csv"
+	"flag"
 	"fmt"
 	"os"
+	"path/filepath"
 
 	"gonum.org/v1/gonum/mat"
 
 	"unmatch/backend/algorithms"
+	"unmatch/backend/feedback"
 )
 
-// SoftmaxToyTest entrena el modelo Softmax con un dataset
-// muy simple de 3 clases en 2D y genera archivos CSV para
-// que puedas graficar los puntos y sus probabilidades.
-func SoftmaxToyTest() error {
+func main() {
+	hp := feedback.HiperparametrosPorDefecto
+	flag.Float64Var(&hp.Lr, "lr", hp.Lr, "tasa de aprendizaje")
+	flag.IntVar(&hp.NIter, "iter", hp.NIter, "iteraciones de descenso por gradiente")
+	flag.Float64Var(&hp.RegLambda, "lambda", hp.RegLambda, "regularizacion L2")
+	flag.Int64Var(&hp.Seed, "seed", 0, "seed de los pesos iniciales (0 = al azar)")
+	dir := flag.String("dir", "./weights", "carpeta de los CSV")
+	flag.Parse()
+
+	if err := softmaxToy(hp, *dir); err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		os.Exit(2)
+	}
+}
+
+// softmaxToy entrena con el dataset de juguete y escribe los puntos de
+// entrenamiento y de prueba con sus probabilidades en dir.
+func softmaxToy(hp feedback.Hiperparametros, dir string) error {
 	// Dataset: 3 clases en 2D
 	// Clase 0: alrededor de (-1, -1)
 	// Clase 1: alrededor de (0, 1)
@@ -39,11 +64,11 @@ func SoftmaxToyTest() error {
 
 	X := mat.NewDense(9, 2, Xdata)
 
-	model := algorithms.NewSoftmaxRegression(0.1, 2000, 1e-3)
+	model := algorithms.NewSoftmaxRegression(hp.Lr, hp.NIter, hp.RegLambda)
 	model.Fit(X, y)
 
 	acc := model.Accuracy(X, y)
-	fmt.Printf("Accuracy entrenamiento (toy): %.4f\n", acc)
+	fmt.Printf("Accuracy entrenamiento (toy): %.4f (seed %d)\n", acc, model.Seed)
 
 	// Probabilidades en los mismos puntos de entrenamiento
 	trainProbs := model.PredictProba(X)
@@ -57,21 +82,24 @@ func SoftmaxToyTest() error {
 	Xtest := mat.NewDense(3, 2, XtestData)
 	testProbs := model.PredictProba(Xtest)
 
-	// Aseguramos que exista la carpeta weights
-	_ = os.MkdirAll("./weights", 0o755)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
 
-	if err := exportPointsCSV("./weights/softmax_train_points.csv", X, y, trainProbs); err != nil {
+	trainPath := filepath.Join(dir, "softmax_train_points.csv")
+	testPath := filepath.Join(dir, "softmax_test_points.csv")
+	if err := exportPointsCSV(trainPath, X, y, trainProbs); err != nil {
 		return err
 	}
 	// Para los puntos de prueba no tenemos etiqueta verdadera, usamos -1
 	yTestDummy := []int{-1, -1, -1}
-	if err := exportPointsCSV("./weights/softmax_test_points.csv", Xtest, yTestDummy, testProbs); err != nil {
+	if err := exportPointsCSV(testPath, Xtest, yTestDummy, testProbs); err != nil {
 		return err
 	}
 
 	fmt.Println("Se generaron:")
-	fmt.Println("  - weights/softmax_train_points.csv")
-	fmt.Println("  - weights/softmax_test_points.csv")
+	fmt.Println("  -", trainPath)
+	fmt.Println("  -", testPath)
 	fmt.Println("Puedes cargar esos CSV en Python, R, Excel, etc. para graficar.")
 
 	return nil
@@ -138,4 +166,3 @@ func exportPointsCSV(path string, X *mat.Dense, y []int, probs *mat.Dense) error
 
 	return nil
 }
-
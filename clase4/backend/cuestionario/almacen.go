@@ -0,0 +1,95 @@
+package cuestionario
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sync"
+)
+
+// DefaultSesionesDir es donde se guardan las sesiones en curso
+const DefaultSesionesDir = "./sesiones"
+
+// ErrSesionNoEncontrada: el id no corresponde a ninguna sesion guardada
+var ErrSesionNoEncontrada = errors.New("sesion no encontrada")
+
+var idValido = regexp.MustCompile(`^[a-f0-9]{16}$`)
+
+// Almacen guarda cada sesion en un archivo JSON para poder retomarla
+// aunque se reinicie el servidor.
+type Almacen struct {
+	Dir string
+	mu  sync.Mutex
+}
+
+// NuevoAlmacen crea la carpeta de sesiones si no existe
+func NuevoAlmacen(dir string) (*Almacen, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &Almacen{Dir: dir}, nil
+}
+
+// NuevoID genera un id aleatorio de 16 caracteres hexadecimales
+func NuevoID() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func (a *Almacen) path(id string) (string, error) {
+	if !idValido.MatchString(id) {
+		return "", ErrSesionNoEncontrada
+	}
+	return filepath.Join(a.Dir, id+".json"), nil
+}
+
+// Guardar escribe la sesion (archivo temporal + rename)
+func (a *Almacen) Guardar(s *Sesion) error {
+	path, err := a.path(s.ID)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// Cargar lee una sesion guardada
+func (a *Almacen) Cargar(id string) (*Sesion, error) {
+	path, err := a.path(id)
+	if err != nil {
+		return nil, err
+	}
+
+	a.mu.Lock()
+	data, err := os.ReadFile(path)
+	a.mu.Unlock()
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrSesionNoEncontrada
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var s Sesion
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
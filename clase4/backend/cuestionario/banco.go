@@ -0,0 +1,161 @@
+// Package cuestionario arma el perfil del estudiante con preguntas
+// adaptativas en lugar de pedirle aptitud, habilidades e intereses.
+package cuestionario
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"unmatch/backend/inferencia"
+)
+
+// DefaultBancosDir es la carpeta con los bancos de preguntas (*.json)
+const DefaultBancosDir = "./cuestionarios"
+
+// Atributos de carrera/5 que puede medir una pregunta
+const (
+	AtributoAptitud   = "aptitud"
+	AtributoHabilidad = "habilidad"
+	AtributoInteres   = "interes"
+)
+
+// Atributos en el orden en que se completan
+var Atributos = []string{AtributoAptitud, AtributoHabilidad, AtributoInteres}
+
+// ErrBancoInvalido indica un banco de preguntas mal formado
+var ErrBancoInvalido = errors.New("banco de preguntas invalido")
+
+// Opcion es una respuesta posible; Valor es un termino del vocabulario
+type Opcion struct {
+	Texto string `json:"texto"`
+	Valor string `json:"valor"`
+}
+
+// Pregunta mide un solo atributo del perfil
+type Pregunta struct {
+	ID       string   `json:"id"`
+	Atributo string   `json:"atributo"`
+	Texto    string   `json:"texto"`
+	Opciones []Opcion `json:"opciones"`
+}
+
+// Banco es un archivo JSON de preguntas
+type Banco struct {
+	Nombre       string     `json:"nombre"`
+	MaxPreguntas int        `json:"max_preguntas"` // 0 = todas
+	Preguntas    []Pregunta `json:"preguntas"`
+}
+
+// Pregunta busca una pregunta por id
+func (b *Banco) Pregunta(id string) (Pregunta, bool) {
+	for _, p := range b.Preguntas {
+		if p.ID == id {
+			return p, true
+		}
+	}
+	return Pregunta{}, false
+}
+
+// CargarBanco lee un banco de preguntas JSON; si no tiene nombre usa el
+// nombre del archivo.
+func CargarBanco(path string) (*Banco, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var b Banco
+	if err := json.Unmarshal(data, &b); err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrBancoInvalido, path, err)
+	}
+	if b.Nombre == "" {
+		b.Nombre = nombreArchivo(path)
+	}
+	return &b, nil
+}
+
+// CargarBancos lee todos los *.json de la carpeta y los valida contra el
+// vocabulario de la base de carreras.
+func CargarBancos(dir string, voc inferencia.Vocabulario) (map[string]*Banco, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+
+	bancos := map[string]*Banco{}
+	for _, path := range paths {
+		b, err := CargarBanco(path)
+		if err != nil {
+			return nil, err
+		}
+		if err := ValidarBanco(b, voc); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		if _, ok := bancos[b.Nombre]; ok {
+			return nil, fmt.Errorf("%w: el banco %q esta repetido (%s)", ErrBancoInvalido, b.Nombre, path)
+		}
+		bancos[b.Nombre] = b
+	}
+	return bancos, nil
+}
+
+// ValidarBanco revisa ids unicos, atributos conocidos y que cada valor
+// exista en el vocabulario de carrera/5, para que el perfil derivado
+// pase la misma validacion que /recomendar.
+func ValidarBanco(b *Banco, voc inferencia.Vocabulario) error {
+	if len(b.Preguntas) == 0 {
+		return fmt.Errorf("%w: %s no tiene preguntas", ErrBancoInvalido, b.Nombre)
+	}
+	validos := map[string][]string{
+		AtributoAptitud:   voc.Aptitudes,
+		AtributoHabilidad: voc.Habilidades,
+		AtributoInteres:   voc.Intereses,
+	}
+
+	ids := map[string]bool{}
+	cubiertos := map[string]bool{}
+	for _, p := range b.Preguntas {
+		if p.ID == "" || ids[p.ID] {
+			return fmt.Errorf("%w: id de pregunta vacio o repetido %q", ErrBancoInvalido, p.ID)
+		}
+		ids[p.ID] = true
+
+		vocab, ok := validos[p.Atributo]
+		if !ok {
+			return fmt.Errorf("%w: pregunta %s: atributo %q, use aptitud, habilidad o interes", ErrBancoInvalido, p.ID, p.Atributo)
+		}
+		cubiertos[p.Atributo] = true
+		if len(p.Opciones) < 2 {
+			return fmt.Errorf("%w: pregunta %s: se requieren al menos 2 opciones", ErrBancoInvalido, p.ID)
+		}
+		for i := range p.Opciones {
+			o := &p.Opciones[i]
+			o.Valor = inferencia.NormalizarTermino(o.Valor)
+			if o.Valor == "" {
+				return fmt.Errorf("%w: pregunta %s: opcion %d sin valor", ErrBancoInvalido, p.ID, i+1)
+			}
+			if e := inferencia.Validar(p.Atributo, o.Valor, vocab); e != nil {
+				sugerencia := ""
+				if e.Sugerencia != "" {
+					sugerencia = fmt.Sprintf(" (¿quiso decir %q?)", e.Sugerencia)
+				}
+				return fmt.Errorf("%w: pregunta %s: opcion %q no esta en el vocabulario%s", ErrBancoInvalido, p.ID, o.Valor, sugerencia)
+			}
+		}
+	}
+	for _, a := range Atributos {
+		if !cubiertos[a] {
+			return fmt.Errorf("%w: %s no tiene preguntas de %s", ErrBancoInvalido, b.Nombre, a)
+		}
+	}
+	return nil
+}
+
+func nombreArchivo(path string) string {
+	base := filepath.Base(path)
+	return base[:len(base)-len(filepath.Ext(base))]
+}
@@ -0,0 +1,274 @@
+package cuestionario
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"unmatch/backend/inferencia"
+)
+
+var (
+	// ErrRespuestaInvalida: la pregunta no es la pendiente o la opcion no existe
+	ErrRespuestaInvalida = errors.New("respuesta invalida")
+	// ErrSesionTerminada: la sesion ya no acepta respuestas
+	ErrSesionTerminada = errors.New("el cuestionario ya termino")
+)
+
+// Respuesta es la opcion que eligio el estudiante
+type Respuesta struct {
+	Pregunta string `json:"pregunta"`
+	Atributo string `json:"atributo"`
+	Valor    string `json:"valor"`
+}
+
+// Sesion es el estado que se guarda entre pregunta y pregunta
+type Sesion struct {
+	ID          string                    `json:"id"`
+	Banco       string                    `json:"banco"`
+	Respuestas  []Respuesta               `json:"respuestas"`
+	Pendiente   string                    `json:"pendiente,omitempty"` // id de la pregunta servida
+	Terminada   bool                      `json:"terminada"`
+	Perfil      *inferencia.PerfilCarrera `json:"perfil,omitempty"`
+	Creada      time.Time                 `json:"creada"`
+	Actualizada time.Time                 `json:"actualizada"`
+}
+
+// Motor elige preguntas de un banco usando el catalogo de carreras
+type Motor struct {
+	Banco    *Banco
+	Carreras []inferencia.CarreraCatalogo
+}
+
+// NuevaSesion crea la sesion y le asigna la primera pregunta
+func (m *Motor) NuevaSesion(id string) *Sesion {
+	ahora := time.Now()
+	s := &Sesion{ID: id, Banco: m.Banco.Nombre, Respuestas: []Respuesta{}, Creada: ahora, Actualizada: ahora}
+	m.avanzar(s)
+	return s
+}
+
+// Responder registra la opcion elegida para la pregunta pendiente y
+// avanza a la siguiente (o termina y deriva el perfil).
+func (m *Motor) Responder(s *Sesion, preguntaID, valor string) error {
+	if s.Terminada {
+		return ErrSesionTerminada
+	}
+	if preguntaID != s.Pendiente {
+		return fmt.Errorf("%w: la pregunta pendiente es %q", ErrRespuestaInvalida, s.Pendiente)
+	}
+	p, ok := m.Banco.Pregunta(preguntaID)
+	if !ok {
+		return fmt.Errorf("%w: la pregunta %q no esta en el banco %s", ErrRespuestaInvalida, preguntaID, m.Banco.Nombre)
+	}
+	valor = inferencia.NormalizarTermino(valor)
+	validos := []string{}
+	for _, o := range p.Opciones {
+		validos = append(validos, o.Valor)
+	}
+	if !contiene(validos, valor) {
+		return fmt.Errorf("%w: opcion %q, use una de %v", ErrRespuestaInvalida, valor, validos)
+	}
+
+	s.Respuestas = append(s.Respuestas, Respuesta{Pregunta: p.ID, Atributo: p.Atributo, Valor: valor})
+	s.Actualizada = time.Now()
+	m.avanzar(s)
+	return nil
+}
+
+// Pregunta devuelve la pregunta pendiente de la sesion, si hay
+func (m *Motor) Pregunta(s *Sesion) *Pregunta {
+	if s.Terminada {
+		return nil
+	}
+	if p, ok := m.Banco.Pregunta(s.Pendiente); ok {
+		return &p
+	}
+	return nil
+}
+
+func (m *Motor) avanzar(s *Sesion) {
+	if p := m.Siguiente(s.Respuestas); p != nil {
+		s.Pendiente = p.ID
+		return
+	}
+	perfil := m.DerivarPerfil(s.Respuestas)
+	s.Pendiente = ""
+	s.Terminada = true
+	s.Perfil = &perfil
+}
+
+// Candidatas son las carreras que coinciden con mas respuestas
+func (m *Motor) Candidatas(respuestas []Respuesta) []inferencia.CarreraCatalogo {
+	mejor := -1
+	out := []inferencia.CarreraCatalogo{}
+	for _, c := range m.Carreras {
+		n := 0
+		for _, r := range respuestas {
+			if contiene(valoresAtributo(c, r.Atributo), r.Valor) {
+				n++
+			}
+		}
+		switch {
+		case n > mejor:
+			mejor = n
+			out = []inferencia.CarreraCatalogo{c}
+		case n == mejor:
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// Siguiente elige la proxima pregunta o devuelve nil si ya se puede
+// derivar el perfil. Primero se cubren los tres atributos; despues se
+// sigue preguntando mientras alguna pregunta separe a las candidatas.
+func (m *Motor) Siguiente(respuestas []Respuesta) *Pregunta {
+	if m.Banco.MaxPreguntas > 0 && len(respuestas) >= m.Banco.MaxPreguntas {
+		return nil
+	}
+	respondidas := map[string]bool{}
+	cubiertos := map[string]bool{}
+	for _, r := range respuestas {
+		respondidas[r.Pregunta] = true
+		cubiertos[r.Atributo] = true
+	}
+
+	pendientes := []Pregunta{}
+	for _, p := range m.Banco.Preguntas {
+		if !respondidas[p.ID] {
+			pendientes = append(pendientes, p)
+		}
+	}
+	if len(pendientes) == 0 {
+		return nil
+	}
+
+	candidatas := m.Candidatas(respuestas)
+
+	// atributos sin responder tienen prioridad
+	faltantes := []Pregunta{}
+	for _, p := range pendientes {
+		if !cubiertos[p.Atributo] {
+			faltantes = append(faltantes, p)
+		}
+	}
+	if len(faltantes) > 0 {
+		p, _ := masDiscriminante(faltantes, candidatas)
+		return &p
+	}
+
+	if len(candidatas) <= 1 {
+		return nil
+	}
+	p, bits := masDiscriminante(pendientes, candidatas)
+	if bits == 0 {
+		return nil
+	}
+	return &p
+}
+
+// masDiscriminante devuelve la pregunta cuya respuesta mejor separa a
+// las candidatas (mayor entropia); los empates respetan el orden del banco.
+func masDiscriminante(preguntas []Pregunta, candidatas []inferencia.CarreraCatalogo) (Pregunta, float64) {
+	mejor, mejorBits := preguntas[0], -1.0
+	for _, p := range preguntas {
+		if bits := discriminacion(p, candidatas); bits > mejorBits {
+			mejor, mejorBits = p, bits
+		}
+	}
+	return mejor, mejorBits
+}
+
+// discriminacion es la entropia (en bits) de repartir las candidatas
+// segun la primera opcion de la pregunta que las describe.
+func discriminacion(p Pregunta, candidatas []inferencia.CarreraCatalogo) float64 {
+	grupos := map[string]int{}
+	for _, c := range candidatas {
+		grupo := ""
+		for _, o := range p.Opciones {
+			if contiene(valoresAtributo(c, p.Atributo), o.Valor) {
+				grupo = o.Valor
+				break
+			}
+		}
+		grupos[grupo]++
+	}
+	total := float64(len(candidatas))
+	bits := 0.0
+	for _, n := range grupos {
+		q := float64(n) / total
+		bits -= q * math.Log2(q)
+	}
+	return bits
+}
+
+// DerivarPerfil toma los valores mas elegidos de cada atributo (los
+// empates por orden de respuesta). Si faltan valores, p. ej. una sola
+// pregunta de habilidad, se completan con la carrera candidata lider.
+func (m *Motor) DerivarPerfil(respuestas []Respuesta) inferencia.PerfilCarrera {
+	candidatas := m.Candidatas(respuestas)
+	elegir := func(atributo string, n int) []string {
+		votos := map[string]int{}
+		orden := []string{}
+		for _, r := range respuestas {
+			if r.Atributo != atributo {
+				continue
+			}
+			if votos[r.Valor] == 0 {
+				orden = append(orden, r.Valor)
+			}
+			votos[r.Valor]++
+		}
+		sort.SliceStable(orden, func(i, j int) bool { return votos[orden[i]] > votos[orden[j]] })
+
+		for _, c := range candidatas {
+			for _, v := range valoresAtributo(c, atributo) {
+				if !contiene(orden, v) {
+					orden = append(orden, v)
+				}
+			}
+		}
+		for len(orden) > 0 && len(orden) < n {
+			orden = append(orden, orden[0])
+		}
+		for len(orden) < n {
+			orden = append(orden, "")
+		}
+		return orden[:n]
+	}
+
+	apt := elegir(AtributoAptitud, 1)
+	habs := elegir(AtributoHabilidad, 2)
+	ints := elegir(AtributoInteres, 2)
+	return inferencia.PerfilCarrera{
+		Aptitud:    apt[0],
+		Habilidad:  habs[0],
+		Habilidad2: habs[1],
+		Interes:    ints[0],
+		Interes2:   ints[1],
+	}
+}
+
+func valoresAtributo(c inferencia.CarreraCatalogo, atributo string) []string {
+	switch atributo {
+	case AtributoAptitud:
+		return c.Aptitudes
+	case AtributoHabilidad:
+		return c.Habilidades
+	case AtributoInteres:
+		return c.Intereses
+	}
+	return nil
+}
+
+func contiene(lista []string, s string) bool {
+	for _, x := range lista {
+		if x == s {
+			return true
+		}
+	}
+	return false
+}
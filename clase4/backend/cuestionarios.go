@@ -0,0 +1,171 @@
+package main
+
+import (
+	"errors"
+	"sort"
+	"sync"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/mndrix/golog"
+
+	"unmatch/backend/cuestionario"
+	"unmatch/backend/inferencia"
+)
+
+// BancoPorDefecto es el banco que se usa si la sesion no indica otro
+const BancoPorDefecto = "vocacional_basico"
+
+// servicioCuestionario atiende las sesiones del cuestionario adaptativo
+type servicioCuestionario struct {
+	m       golog.Machine
+	pesos   inferencia.Pesos
+	motores map[string]*cuestionario.Motor
+	almacen *cuestionario.Almacen
+	mu      sync.Mutex // serializa cargar-responder-guardar
+}
+
+func nuevoServicioCuestionario(m golog.Machine, cat inferencia.Catalogo, pesos inferencia.Pesos, bancosDir, sesionesDir string) (*servicioCuestionario, error) {
+	bancos, err := cuestionario.CargarBancos(bancosDir, cat.Vocabulario)
+	if err != nil {
+		return nil, err
+	}
+	almacen, err := cuestionario.NuevoAlmacen(sesionesDir)
+	if err != nil {
+		return nil, err
+	}
+	s := &servicioCuestionario{m: m, pesos: pesos, motores: map[string]*cuestionario.Motor{}, almacen: almacen}
+	for nombre, b := range bancos {
+		s.motores[nombre] = &cuestionario.Motor{Banco: b, Carreras: cat.Carreras}
+	}
+	return s, nil
+}
+
+// estado arma la respuesta comun: la pregunta pendiente o, si termino,
+// el perfil derivado y sus recomendaciones.
+func (s *servicioCuestionario) estado(c *fiber.Ctx, motor *cuestionario.Motor, ses *cuestionario.Sesion) error {
+	candidatas := []string{}
+	for _, carr := range motor.Candidatas(ses.Respuestas) {
+		candidatas = append(candidatas, carr.Facultad+"/"+carr.Carrera)
+	}
+	resp := fiber.Map{
+		"id":          ses.ID,
+		"banco":       ses.Banco,
+		"terminada":   ses.Terminada,
+		"respondidas": len(ses.Respuestas),
+		"candidatas":  candidatas,
+	}
+	if !ses.Terminada {
+		resp["pregunta"] = motor.Pregunta(ses)
+		return c.JSON(resp)
+	}
+
+	resultados, err := inferencia.RecomendarCarrerasConPesos(c.UserContext(), s.m, *ses.Perfil, s.pesos)
+	if err != nil {
+		return respuestaErrorProlog(c, err)
+	}
+	resp["perfil"] = ses.Perfil
+	resp["recomendaciones"] = inferencia.RankearCarreras(resultados, inferencia.FiltroRanking{TopN: 5})
+	return c.JSON(resp)
+}
+
+// cargar busca la sesion del parametro :id y el motor de su banco
+func (s *servicioCuestionario) cargar(c *fiber.Ctx) (*cuestionario.Motor, *cuestionario.Sesion, error) {
+	ses, err := s.almacen.Cargar(c.Params("id"))
+	if err != nil {
+		return nil, nil, err
+	}
+	motor, ok := s.motores[ses.Banco]
+	if !ok {
+		return nil, nil, cuestionario.ErrSesionNoEncontrada
+	}
+	return motor, ses, nil
+}
+
+func errorSesion(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, cuestionario.ErrSesionNoEncontrada):
+		return c.Status(404).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, cuestionario.ErrRespuestaInvalida):
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, cuestionario.ErrSesionTerminada):
+		return c.Status(409).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+}
+
+// GET /cuestionarios
+func (s *servicioCuestionario) listarBancos(c *fiber.Ctx) error {
+	bancos := []fiber.Map{}
+	for nombre, motor := range s.motores {
+		bancos = append(bancos, fiber.Map{
+			"nombre":        nombre,
+			"preguntas":     len(motor.Banco.Preguntas),
+			"max_preguntas": motor.Banco.MaxPreguntas,
+		})
+	}
+	sort.Slice(bancos, func(i, j int) bool { return bancos[i]["nombre"].(string) < bancos[j]["nombre"].(string) })
+	return c.JSON(fiber.Map{"bancos": bancos})
+}
+
+// POST /cuestionarios/sesiones  {"banco": "vocacional_basico"}
+func (s *servicioCuestionario) crearSesion(c *fiber.Ctx) error {
+	var req struct {
+		Banco string `json:"banco"`
+	}
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": "Error de entrada."})
+		}
+	}
+	if req.Banco == "" {
+		req.Banco = BancoPorDefecto
+	}
+	motor, ok := s.motores[req.Banco]
+	if !ok {
+		return c.Status(404).JSON(fiber.Map{"error": "No existe el banco de preguntas " + req.Banco})
+	}
+
+	id, err := cuestionario.NuevoID()
+	if err != nil {
+		return errorSesion(c, err)
+	}
+	ses := motor.NuevaSesion(id)
+	if err := s.almacen.Guardar(ses); err != nil {
+		return errorSesion(c, err)
+	}
+	c.Status(fiber.StatusCreated)
+	return s.estado(c, motor, ses)
+}
+
+// GET /cuestionarios/sesiones/:id  (para retomar una sesion)
+func (s *servicioCuestionario) verSesion(c *fiber.Ctx) error {
+	motor, ses, err := s.cargar(c)
+	if err != nil {
+		return errorSesion(c, err)
+	}
+	return s.estado(c, motor, ses)
+}
+
+// POST /cuestionarios/sesiones/:id/respuestas  {"pregunta": "...", "opcion": "..."}
+func (s *servicioCuestionario) responder(c *fiber.Ctx) error {
+	var req struct {
+		Pregunta string `json:"pregunta"`
+		Opcion   string `json:"opcion"`
+	}
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Error de entrada."})
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	motor, ses, err := s.cargar(c)
+	if err != nil {
+		return errorSesion(c, err)
+	}
+	if err := motor.Responder(ses, req.Pregunta, req.Opcion); err != nil {
+		return errorSesion(c, err)
+	}
+	if err := s.almacen.Guardar(ses); err != nil {
+		return errorSesion(c, err)
+	}
+	return s.estado(c, motor, ses)
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+
+	"unmatch/backend/expedientes"
+	"unmatch/backend/inferencia"
+)
+
+// servicioExpedientes atiende los expedientes de los estudiantes
+type servicioExpedientes struct {
+	almacen   *expedientes.Almacen
+	catalogo  inferencia.Catalogo
+	versionKB string
+}
+
+func errorExpediente(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, expedientes.ErrNoEncontrado):
+		return c.Status(404).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, expedientes.ErrDatoInvalido):
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.Status(500).JSON(fiber.Map{"error": "Error en la base de expedientes", "detalle": err.Error()})
+}
+
+// guardarRecomendacion agrega al historial del estudiante el ranking
+// devuelto por /recomendar; devuelve el id del registro.
+func (s *servicioExpedientes) guardarRecomendacion(id string, perfil PerfilEstudiante, modo, pesos string, ranking []inferencia.CarreraRecomendada) (uint64, error) {
+	if modo == "" {
+		modo = "reglas"
+	}
+	r := &expedientes.Recomendacion{
+		Perfil:     perfil.PerfilCarrera(),
+		VersionKB:  s.versionKB,
+		Modo:       modo,
+		Pesos:      pesos,
+		Resultados: ranking,
+	}
+	if err := s.almacen.AgregarRecomendacion(id, r); err != nil {
+		return 0, err
+	}
+	return r.ID, nil
+}
+
+// POST /estudiantes  {"id": "...", "nombre": "...", "colegio": "...", "perfil": {...}}
+func (s *servicioExpedientes) guardarEstudiante(c *fiber.Ctx) error {
+	var req struct {
+		ID      string           `json:"id"`
+		Nombre  string           `json:"nombre"`
+		Colegio string           `json:"colegio"`
+		Perfil  PerfilEstudiante `json:"perfil"`
+	}
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Error de entrada."})
+	}
+	if req.Nombre == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "El campo 'nombre' es requerido."})
+	}
+	if errores := validarPerfil(&req.Perfil, s.catalogo); len(errores) > 0 {
+		return c.Status(400).JSON(fiber.Map{
+			"error":   "Hay valores que no existen en la base de carreras. Consulte /vocabulario.",
+			"errores": errores,
+		})
+	}
+	if req.ID == "" {
+		id, err := expedientes.NuevoID()
+		if err != nil {
+			return errorExpediente(c, err)
+		}
+		req.ID = id
+	}
+
+	e := &expedientes.Estudiante{ID: req.ID, Nombre: req.Nombre, Colegio: req.Colegio, Perfil: req.Perfil.PerfilCarrera()}
+	if err := s.almacen.GuardarEstudiante(e); err != nil {
+		return errorExpediente(c, err)
+	}
+	return c.Status(fiber.StatusCreated).JSON(e)
+}
+
+// GET /estudiantes/:id/historial  (expediente, recomendaciones y notas)
+func (s *servicioExpedientes) historial(c *fiber.Ctx) error {
+	id := c.Params("id")
+	e, err := s.almacen.Estudiante(id)
+	if err != nil {
+		return errorExpediente(c, err)
+	}
+	recs, err := s.almacen.Historial(id)
+	if err != nil {
+		return errorExpediente(c, err)
+	}
+	notas, err := s.almacen.Notas(id)
+	if err != nil {
+		return errorExpediente(c, err)
+	}
+	return c.JSON(fiber.Map{
+		"estudiante":      e,
+		"version_kb":      s.versionKB,
+		"recomendaciones": recs,
+		"notas":           notas,
+	})
+}
+
+// POST /estudiantes/:id/notas  {"autor": "...", "texto": "..."}
+func (s *servicioExpedientes) agregarNota(c *fiber.Ctx) error {
+	var n expedientes.Nota
+	if err := c.BodyParser(&n); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Error de entrada."})
+	}
+	if err := s.almacen.AgregarNota(c.Params("id"), &n); err != nil {
+		return errorExpediente(c, err)
+	}
+	return c.Status(fiber.StatusCreated).JSON(n)
+}
+
+// GET /estudiantes/:id/comparar?desde=1&hasta=3  (por defecto la primera
+// contra la ultima recomendacion)
+func (s *servicioExpedientes) comparar(c *fiber.Ctx) error {
+	recs, err := s.almacen.Historial(c.Params("id"))
+	if err != nil {
+		return errorExpediente(c, err)
+	}
+	if len(recs) < 2 {
+		return c.Status(400).JSON(fiber.Map{"error": "Se necesitan al menos dos recomendaciones en el historial para comparar."})
+	}
+
+	buscar := func(param string, porDefecto expedientes.Recomendacion) (expedientes.Recomendacion, error) {
+		v := c.Query(param)
+		if v == "" {
+			return porDefecto, nil
+		}
+		n, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			return porDefecto, errors.New(param + " debe ser el id de una recomendacion")
+		}
+		for _, r := range recs {
+			if r.ID == n {
+				return r, nil
+			}
+		}
+		return porDefecto, errors.New("no existe la recomendacion " + v)
+	}
+	desde, err := buscar("desde", recs[0])
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	hasta, err := buscar("hasta", recs[len(recs)-1])
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(expedientes.Comparar(desde, hasta))
+}
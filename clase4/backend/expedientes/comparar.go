@@ -0,0 +1,117 @@
+package expedientes
+
+import (
+	"sort"
+	"time"
+
+	"unmatch/backend/inferencia"
+)
+
+// Estados de una carrera entre dos recomendaciones
+const (
+	EstadoNueva     = "nueva"
+	EstadoEliminada = "eliminada"
+	EstadoSube      = "sube"
+	EstadoBaja      = "baja"
+	EstadoIgual     = "igual"
+)
+
+// CambioCarrera es como cambio una carrera entre dos recomendaciones.
+// Las posiciones empiezan en 1; 0 significa que no aparecia.
+type CambioCarrera struct {
+	Facultad        string  `json:"facultad"`
+	Carrera         string  `json:"carrera"`
+	Estado          string  `json:"estado"`
+	PosicionAntes   int     `json:"posicion_antes"`
+	PosicionDespues int     `json:"posicion_despues"`
+	MatchAntes      float64 `json:"match_antes"`
+	MatchDespues    float64 `json:"match_despues"`
+	Diferencia      float64 `json:"diferencia"`
+}
+
+// Comparacion resume los cambios entre dos recomendaciones del historial
+type Comparacion struct {
+	Desde         uint64               `json:"desde"`
+	Hasta         uint64               `json:"hasta"`
+	FechaDesde    time.Time            `json:"fecha_desde"`
+	FechaHasta    time.Time            `json:"fecha_hasta"`
+	CambioKB      bool                 `json:"cambio_kb"`
+	CambiosPerfil map[string][2]string `json:"cambios_perfil"` // campo -> [antes, despues]
+	Carreras      []CambioCarrera      `json:"carreras"`
+}
+
+// Comparar contrasta dos recomendaciones del historial (a es la anterior)
+func Comparar(a, b Recomendacion) Comparacion {
+	cmp := Comparacion{
+		Desde: a.ID, Hasta: b.ID,
+		FechaDesde: a.Fecha, FechaHasta: b.Fecha,
+		CambioKB:      a.VersionKB != b.VersionKB,
+		CambiosPerfil: map[string][2]string{},
+		Carreras:      CompararRankings(a.Resultados, b.Resultados),
+	}
+
+	campos := []struct {
+		nombre       string
+		antes, ahora string
+	}{
+		{"aptitud", a.Perfil.Aptitud, b.Perfil.Aptitud},
+		{"habilidad", a.Perfil.Habilidad, b.Perfil.Habilidad},
+		{"interes", a.Perfil.Interes, b.Perfil.Interes},
+		{"habilidad2", a.Perfil.Habilidad2, b.Perfil.Habilidad2},
+		{"interes2", a.Perfil.Interes2, b.Perfil.Interes2},
+	}
+	for _, c := range campos {
+		if c.antes != c.ahora {
+			cmp.CambiosPerfil[c.nombre] = [2]string{c.antes, c.ahora}
+		}
+	}
+	return cmp
+}
+
+// CompararRankings contrasta dos rankings (a es el anterior). Las
+// carreras salen en el orden de b y al final las que desaparecieron.
+func CompararRankings(a, b []inferencia.CarreraRecomendada) []CambioCarrera {
+	cambios := map[string]*CambioCarrera{}
+	orden := []string{}
+	obtener := func(r inferencia.CarreraRecomendada) *CambioCarrera {
+		if c, ok := cambios[r.Clave()]; ok {
+			return c
+		}
+		c := &CambioCarrera{Facultad: r.Facultad, Carrera: r.Carrera}
+		cambios[r.Clave()] = c
+		orden = append(orden, r.Clave())
+		return c
+	}
+	for i, r := range b {
+		c := obtener(r)
+		c.PosicionDespues, c.MatchDespues = i+1, r.Match
+	}
+	for i, r := range a {
+		c := obtener(r)
+		c.PosicionAntes, c.MatchAntes = i+1, r.Match
+	}
+
+	out := []CambioCarrera{}
+	for _, k := range orden {
+		c := cambios[k]
+		c.Diferencia = c.MatchDespues - c.MatchAntes
+		switch {
+		case c.PosicionAntes == 0:
+			c.Estado = EstadoNueva
+		case c.PosicionDespues == 0:
+			c.Estado = EstadoEliminada
+		case c.PosicionDespues < c.PosicionAntes:
+			c.Estado = EstadoSube
+		case c.PosicionDespues > c.PosicionAntes:
+			c.Estado = EstadoBaja
+		default:
+			c.Estado = EstadoIgual
+		}
+		out = append(out, *c)
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		pi, pj := out[i].PosicionDespues, out[j].PosicionDespues
+		return pi != 0 && (pj == 0 || pi < pj)
+	})
+	return out
+}
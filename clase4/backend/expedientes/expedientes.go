@@ -0,0 +1,261 @@
+// Package expedientes guarda, en un archivo BoltDB, el perfil de cada
+// estudiante, todas las recomendaciones que recibio (con la version de
+// la base de conocimiento usada) y las notas de los orientadores.
+package expedientes
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+
+	"unmatch/backend/inferencia"
+)
+
+// DefaultDBPath es el archivo de la base de expedientes
+const DefaultDBPath = "./datos/expedientes.db"
+
+var (
+	bucketEstudiantes = []byte("estudiantes")
+	bucketHistorial   = []byte("historial") // un sub-bucket por estudiante
+	bucketNotas       = []byte("notas")     // un sub-bucket por estudiante
+)
+
+var (
+	// ErrNoEncontrado: el estudiante o la recomendacion no existen
+	ErrNoEncontrado = errors.New("no encontrado")
+	// ErrDatoInvalido: id, nota o perfil mal formados
+	ErrDatoInvalido = errors.New("dato invalido")
+)
+
+var idValido = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+// Estudiante es el expediente basico
+type Estudiante struct {
+	ID          string                   `json:"id"`
+	Nombre      string                   `json:"nombre"`
+	Colegio     string                   `json:"colegio,omitempty"`
+	Perfil      inferencia.PerfilCarrera `json:"perfil"`
+	Creado      time.Time                `json:"creado"`
+	Actualizado time.Time                `json:"actualizado"`
+}
+
+// Recomendacion es una respuesta de /recomendar guardada en el historial
+type Recomendacion struct {
+	ID         uint64                          `json:"id"`
+	Fecha      time.Time                       `json:"fecha"`
+	Perfil     inferencia.PerfilCarrera        `json:"perfil"`
+	VersionKB  string                          `json:"version_kb"`
+	Modo       string                          `json:"modo"`
+	Pesos      string                          `json:"pesos"` // origen de los pesos de match
+	Resultados []inferencia.CarreraRecomendada `json:"resultados"`
+}
+
+// Nota es un comentario de un orientador
+type Nota struct {
+	ID    uint64    `json:"id"`
+	Fecha time.Time `json:"fecha"`
+	Autor string    `json:"autor"`
+	Texto string    `json:"texto"`
+}
+
+// Almacen envuelve la base BoltDB
+type Almacen struct {
+	db *bolt.DB
+}
+
+// Abrir abre (o crea) la base y sus buckets
+func Abrir(path string) (*Almacen, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("no se pudo abrir %s: %w", path, err)
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, b := range [][]byte{bucketEstudiantes, bucketHistorial, bucketNotas} {
+			if _, err := tx.CreateBucketIfNotExists(b); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &Almacen{db: db}, nil
+}
+
+// Cerrar cierra la base
+func (a *Almacen) Cerrar() error {
+	return a.db.Close()
+}
+
+// NuevoID genera un id aleatorio para estudiantes sin codigo propio
+func NuevoID() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// GuardarEstudiante crea o actualiza el expediente (conserva la fecha
+// de creacion si ya existia).
+func (a *Almacen) GuardarEstudiante(e *Estudiante) error {
+	if !idValido.MatchString(e.ID) {
+		return fmt.Errorf("%w: id %q, use letras, numeros, _ o - (maximo 64)", ErrDatoInvalido, e.ID)
+	}
+	return a.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(bucketEstudiantes)
+		ahora := time.Now()
+		e.Creado, e.Actualizado = ahora, ahora
+		if data := b.Get([]byte(e.ID)); data != nil {
+			var previo Estudiante
+			if err := json.Unmarshal(data, &previo); err == nil {
+				e.Creado = previo.Creado
+			}
+		}
+		return putJSON(b, []byte(e.ID), e)
+	})
+}
+
+// Estudiante lee un expediente
+func (a *Almacen) Estudiante(id string) (*Estudiante, error) {
+	var e Estudiante
+	err := a.db.View(func(tx *bolt.Tx) error {
+		data := tx.Bucket(bucketEstudiantes).Get([]byte(id))
+		if data == nil {
+			return fmt.Errorf("%w: estudiante %q", ErrNoEncontrado, id)
+		}
+		return json.Unmarshal(data, &e)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+// AgregarRecomendacion guarda una recomendacion en el historial del
+// estudiante y actualiza su perfil con el usado en la consulta.
+func (a *Almacen) AgregarRecomendacion(id string, r *Recomendacion) error {
+	return a.db.Update(func(tx *bolt.Tx) error {
+		est := tx.Bucket(bucketEstudiantes)
+		data := est.Get([]byte(id))
+		if data == nil {
+			return fmt.Errorf("%w: estudiante %q", ErrNoEncontrado, id)
+		}
+		var e Estudiante
+		if err := json.Unmarshal(data, &e); err != nil {
+			return err
+		}
+		e.Perfil = r.Perfil
+		e.Actualizado = time.Now()
+		if err := putJSON(est, []byte(id), &e); err != nil {
+			return err
+		}
+
+		b, err := tx.Bucket(bucketHistorial).CreateBucketIfNotExists([]byte(id))
+		if err != nil {
+			return err
+		}
+		if r.ID, err = b.NextSequence(); err != nil {
+			return err
+		}
+		if r.Fecha.IsZero() {
+			r.Fecha = time.Now()
+		}
+		return putJSON(b, clave(r.ID), r)
+	})
+}
+
+// Historial devuelve las recomendaciones del estudiante, de la mas
+// antigua a la mas reciente.
+func (a *Almacen) Historial(id string) ([]Recomendacion, error) {
+	out := []Recomendacion{}
+	err := a.listar(bucketHistorial, id, func(data []byte) error {
+		var r Recomendacion
+		if err := json.Unmarshal(data, &r); err != nil {
+			return err
+		}
+		out = append(out, r)
+		return nil
+	})
+	return out, err
+}
+
+// AgregarNota guarda una nota en el expediente
+func (a *Almacen) AgregarNota(id string, n *Nota) error {
+	if n.Texto == "" {
+		return fmt.Errorf("%w: la nota no tiene texto", ErrDatoInvalido)
+	}
+	return a.db.Update(func(tx *bolt.Tx) error {
+		if tx.Bucket(bucketEstudiantes).Get([]byte(id)) == nil {
+			return fmt.Errorf("%w: estudiante %q", ErrNoEncontrado, id)
+		}
+		b, err := tx.Bucket(bucketNotas).CreateBucketIfNotExists([]byte(id))
+		if err != nil {
+			return err
+		}
+		if n.ID, err = b.NextSequence(); err != nil {
+			return err
+		}
+		n.Fecha = time.Now()
+		return putJSON(b, clave(n.ID), n)
+	})
+}
+
+// Notas devuelve las notas del estudiante en orden de creacion
+func (a *Almacen) Notas(id string) ([]Nota, error) {
+	out := []Nota{}
+	err := a.listar(bucketNotas, id, func(data []byte) error {
+		var n Nota
+		if err := json.Unmarshal(data, &n); err != nil {
+			return err
+		}
+		out = append(out, n)
+		return nil
+	})
+	return out, err
+}
+
+// listar recorre el sub-bucket del estudiante; falla si el estudiante
+// no existe, no si todavia no tiene registros.
+func (a *Almacen) listar(bucket []byte, id string, fn func([]byte) error) error {
+	return a.db.View(func(tx *bolt.Tx) error {
+		if tx.Bucket(bucketEstudiantes).Get([]byte(id)) == nil {
+			return fmt.Errorf("%w: estudiante %q", ErrNoEncontrado, id)
+		}
+		b := tx.Bucket(bucket).Bucket([]byte(id))
+		if b == nil {
+			return nil
+		}
+		return b.ForEach(func(_, v []byte) error { return fn(v) })
+	})
+}
+
+func putJSON(b *bolt.Bucket, k []byte, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return b.Put(k, data)
+}
+
+// clave codifica la secuencia en big-endian para que ForEach recorra en
+// orden de insercion
+func clave(n uint64) []byte {
+	k := make([]byte, 8)
+	binary.BigEndian.PutUint64(k, n)
+	return k
+}
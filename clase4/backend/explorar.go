@@ -0,0 +1,156 @@
+package main
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/mndrix/golog"
+
+	"unmatch/backend/expedientes"
+	"unmatch/backend/inferencia"
+)
+
+// servicioExplorar atiende las consultas de los orientadores: "que pasa
+// si" cambia el perfil y carreras parecidas a otra.
+type servicioExplorar struct {
+	m        golog.Machine
+	catalogo inferencia.Catalogo
+	pesos    inferencia.Pesos
+	fichas   []inferencia.Ficha
+}
+
+// escenario es un perfil con su ranking por reglas
+type escenario struct {
+	Perfil          PerfilEstudiante                `json:"perfil"`
+	Recomendaciones []inferencia.CarreraRecomendada `json:"recomendaciones"`
+}
+
+// ranking calcula el ranking por reglas con los filtros del perfil,
+// igual que /recomendar en modo reglas
+func (s *servicioExplorar) ranking(c *fiber.Ctx, perfil PerfilEstudiante) ([]inferencia.CarreraRecomendada, error) {
+	resultados, err := inferencia.RecomendarCarrerasConPesos(c.UserContext(), s.m, perfil.PerfilCarrera(), s.pesos)
+	if err != nil {
+		return nil, err
+	}
+	resultados, _ = s.catalogo.FiltrarPorFicha(resultados, perfil.FiltroFicha())
+	return inferencia.RankearCarreras(resultados, inferencia.FiltroRanking{
+		TopN:     perfil.TopN,
+		MinMatch: perfil.MinMatch,
+		Facultad: perfil.Facultad,
+	}), nil
+}
+
+// camposEditables son los campos del perfil que acepta "cambios"
+func camposEditables(p *PerfilEstudiante) map[string]*string {
+	return map[string]*string{
+		"aptitud":    &p.Aptitud,
+		"habilidad":  &p.Habilidad,
+		"interes":    &p.Interes,
+		"habilidad2": &p.Habilidad2,
+		"interes2":   &p.Interes2,
+		"facultad":   &p.Facultad,
+		"sede":       &p.Sede,
+		"modalidad":  &p.Modalidad,
+	}
+}
+
+// POST /explorar/que-pasa-si
+//
+//	{"base": {...perfil de /recomendar...}, "cambios": {"aptitud": "biologia"}}
+//
+// Devuelve el ranking del perfil base, el del perfil con los cambios y
+// como se movio cada carrera. Un cambio a "" borra el campo.
+func (s *servicioExplorar) quePasaSi(c *fiber.Ctx) error {
+	var req struct {
+		Base    PerfilEstudiante  `json:"base"`
+		Cambios map[string]string `json:"cambios"`
+	}
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Error de entrada."})
+	}
+	if len(req.Cambios) == 0 {
+		return c.Status(400).JSON(fiber.Map{"error": "Indique al menos un cambio en 'cambios'."})
+	}
+	if req.Base.Modo == "hibrido" {
+		return c.Status(400).JSON(fiber.Map{"error": "El escenario se calcula solo con las reglas, quite modo hibrido."})
+	}
+	if errores := validarPerfil(&req.Base, s.catalogo); len(errores) > 0 {
+		return c.Status(400).JSON(fiber.Map{
+			"error":   "Hay valores que no existen en la base de carreras. Consulte /vocabulario.",
+			"errores": errores,
+		})
+	}
+
+	nuevo := req.Base
+	campos := camposEditables(&nuevo)
+	validos := make([]string, 0, len(campos))
+	for k := range campos {
+		validos = append(validos, k)
+	}
+	sort.Strings(validos)
+	for campo, valor := range req.Cambios {
+		destino, ok := campos[campo]
+		if !ok {
+			e := inferencia.Validar("cambios", campo, validos)
+			return c.Status(400).JSON(fiber.Map{"error": e.Error(), "errores": []inferencia.ErrorCampo{*e}})
+		}
+		*destino = valor
+	}
+	if errores := validarPerfil(&nuevo, s.catalogo); len(errores) > 0 {
+		return c.Status(400).JSON(fiber.Map{
+			"error":   "Los cambios tienen valores que no existen en la base de carreras.",
+			"errores": errores,
+		})
+	}
+	if nuevo.Aptitud == "" || nuevo.Habilidad == "" || nuevo.Interes == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "aptitud, habilidad e interes no se pueden borrar"})
+	}
+
+	antes, err := s.ranking(c, req.Base)
+	if err != nil {
+		return respuestaErrorProlog(c, err)
+	}
+	despues, err := s.ranking(c, nuevo)
+	if err != nil {
+		return respuestaErrorProlog(c, err)
+	}
+
+	// cambios efectivos (normalizados) campo -> [antes, despues]
+	cambios := map[string][2]string{}
+	base := camposEditables(&req.Base)
+	for campo := range req.Cambios {
+		if *base[campo] != *campos[campo] {
+			cambios[campo] = [2]string{*base[campo], *campos[campo]}
+		}
+	}
+
+	return c.JSON(fiber.Map{
+		"base":      escenario{Perfil: req.Base, Recomendaciones: antes},
+		"escenario": escenario{Perfil: nuevo, Recomendaciones: despues},
+		"cambios":   cambios,
+		"carreras":  expedientes.CompararRankings(antes, despues),
+	})
+}
+
+// GET /carreras/:facultad/:carrera/similares?top_n=5
+func (s *servicioExplorar) similares(c *fiber.Ctx) error {
+	topN, err := strconv.Atoi(c.Query("top_n", "5"))
+	if err != nil || topN < 0 {
+		return c.Status(400).JSON(fiber.Map{"error": "top_n debe ser un entero mayor o igual a 0"})
+	}
+	clave := inferencia.NormalizarTermino(c.Params("facultad")) + "/" + inferencia.NormalizarTermino(c.Params("carrera"))
+	similares, err := inferencia.CarrerasSimilares(s.fichas, clave, topN)
+	if errors.Is(err, inferencia.ErrCarreraNoEncontrada) {
+		claves := []string{}
+		for _, f := range s.fichas {
+			claves = append(claves, f.Clave())
+		}
+		return c.Status(404).JSON(fiber.Map{"error": err.Error(), "sugerencia": inferencia.Sugerir(clave, claves)})
+	}
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"carrera": clave, "similares": similares})
+}
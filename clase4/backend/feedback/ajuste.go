@@ -0,0 +1,156 @@
+package feedback
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"gonum.org/v1/gonum/mat"
+
+	"unmatch/backend/algorithms"
+	"unmatch/backend/inferencia"
+)
+
+// ErrPocosDatos: no hay suficientes ejemplos positivos para ajustar
+var ErrPocosDatos = errors.New("no hay suficiente feedback para ajustar los pesos")
+
+// Hiperparametros del modelo softmax
+type Hiperparametros struct {
+	Lr        float64
+	NIter     int
+	RegLambda float64
+	Minimo    int   // ejemplos positivos requeridos
+	Seed      int64 // de los pesos iniciales; 0 = al azar (queda en el resultado)
+}
+
+// HiperparametrosPorDefecto son los mismos de cmd/softmaxtoy
+var HiperparametrosPorDefecto = Hiperparametros{Lr: 0.1, NIter: 2000, RegLambda: 1e-3, Minimo: 5}
+
+// Reporte resume el ajuste
+type Reporte struct {
+	Muestras          int     // ejemplos positivos usados
+	Ignorados         int     // calificaciones bajas o carreras que ya no existen
+	Accuracy          float64 // del modelo softmax sobre los ejemplos
+	AciertoPorDefecto float64 // carrera elegida en el primer puesto, pesos por defecto
+	AciertoAprendido  float64 // idem con los pesos aprendidos
+}
+
+// AjustarPesos entrena algorithms.SoftmaxRegression con una fila por
+// ejemplo positivo: los indicadores de match (aptitud, habilidad,
+// interes, cruce) de la ficha de cada carrera, concatenados; la etiqueta es la
+// carrera elegida. El peso de cada criterio es el promedio del
+// coeficiente que une el indicador de una carrera con su propia clase,
+// recortado a >= 0 y normalizado para sumar como los pesos por defecto.
+func AjustarPesos(registros []Registro, fichas []inferencia.Ficha, hp Hiperparametros) (inferencia.Pesos, Reporte, error) {
+	rep := Reporte{}
+
+	carreras := []string{}
+	indice := map[string]int{}
+	for _, f := range fichas {
+		indice[f.Clave()] = len(carreras)
+		carreras = append(carreras, f.Clave())
+	}
+
+	nc := len(inferencia.Criterios)
+	filas := [][]float64{}
+	y := []int{}
+	for _, r := range registros {
+		k, ok := indice[r.Carrera]
+		if !r.Positivo() || !ok {
+			rep.Ignorados++
+			continue
+		}
+		filas = append(filas, indicadoresPorCarrera(r.Perfil, fichas))
+		y = append(y, k)
+	}
+	rep.Muestras = len(filas)
+	if rep.Muestras < hp.Minimo {
+		return inferencia.PesosPorDefecto, rep, fmt.Errorf("%w: %d ejemplos positivos, se requieren %d", ErrPocosDatos, rep.Muestras, hp.Minimo)
+	}
+
+	data := make([]float64, 0, len(filas)*len(carreras)*nc)
+	for _, f := range filas {
+		data = append(data, f...)
+	}
+	X := mat.NewDense(len(filas), len(carreras)*nc, data)
+
+	model := algorithms.NewSoftmaxRegression(hp.Lr, hp.NIter, hp.RegLambda)
+	model.Seed = hp.Seed
+	model.Fit(X, y)
+	rep.Accuracy = model.Accuracy(X, y)
+
+	// coeficiente indicador(carrera k, criterio j) -> clase k, solo para
+	// las carreras que aparecen como etiqueta
+	_, nClases := model.W.Dims()
+	vistas := map[int]bool{}
+	for _, k := range y {
+		vistas[k] = true
+	}
+	w := make([]float64, nc)
+	for k := range vistas {
+		if k >= nClases {
+			continue
+		}
+		for j := 0; j < nc; j++ {
+			w[j] += model.W.At(k*nc+j, k) / float64(len(vistas))
+		}
+	}
+
+	suma := 0.0
+	for j := range w {
+		if w[j] < 0 {
+			w[j] = 0
+		}
+		suma += w[j]
+	}
+	if suma == 0 {
+		return inferencia.PesosPorDefecto, rep, fmt.Errorf("%w: ningun criterio favorece a las carreras elegidas", ErrPocosDatos)
+	}
+	escala := float64(nc) / suma
+
+	pesos := inferencia.Pesos{
+		Aptitud:   w[0] * escala,
+		Habilidad: w[1] * escala,
+		Interes:   w[2] * escala,
+		Cruce:     w[3] * escala,
+		Origen:    "feedback",
+		Muestras:  rep.Muestras,
+		Seed:      model.Seed,
+		Fecha:     time.Now(),
+	}
+	rep.AciertoPorDefecto = acierto(filas, y, inferencia.PesosPorDefecto)
+	rep.AciertoAprendido = acierto(filas, y, pesos)
+	return pesos, rep, nil
+}
+
+// indicadoresPorCarrera arma la fila de features: los indicadores de
+// cada ficha, en el orden de las fichas.
+func indicadoresPorCarrera(p inferencia.PerfilCarrera, fichas []inferencia.Ficha) []float64 {
+	fila := make([]float64, 0, len(fichas)*len(inferencia.Criterios))
+	for _, f := range fichas {
+		fila = append(fila, p.IndicadoresFicha(f)...)
+	}
+	return fila
+}
+
+// acierto es la fraccion de ejemplos cuya carrera elegida queda primera
+// con los pesos dados (los empates por orden de la base, como el ranking).
+func acierto(filas [][]float64, y []int, pesos inferencia.Pesos) float64 {
+	if len(filas) == 0 {
+		return 0
+	}
+	nc := len(inferencia.Criterios)
+	aciertos := 0
+	for i, f := range filas {
+		primera, mejor := 0, -1.0
+		for k := 0; k < len(f)/nc; k++ {
+			if s := pesos.Puntaje(f[k*nc : (k+1)*nc]); s > mejor {
+				primera, mejor = k, s
+			}
+		}
+		if primera == y[i] {
+			aciertos++
+		}
+	}
+	return float64(aciertos) / float64(len(filas))
+}
@@ -0,0 +1,134 @@
+// Package feedback guarda las calificaciones y elecciones de carrera de
+// los estudiantes y ajusta con ellas los pesos del puntaje de match.
+package feedback
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"unmatch/backend/inferencia"
+)
+
+// DefaultFeedbackPath es el archivo JSONL (un registro por linea)
+const DefaultFeedbackPath = "./datos/feedback.jsonl"
+
+// Tipos de registro
+const (
+	TipoCalificacion = "calificacion" // se califico una carrera recomendada (1 a 5)
+	TipoEleccion     = "eleccion"     // la carrera que el estudiante termino eligiendo
+)
+
+// Roles de quien registra el feedback
+var Roles = []string{"estudiante", "orientador"}
+
+// CalificacionPositiva: desde este valor una calificacion cuenta como
+// eleccion al entrenar
+const CalificacionPositiva = 4
+
+// ErrRegistroInvalido indica un registro de feedback mal formado
+var ErrRegistroInvalido = errors.New("registro de feedback invalido")
+
+// Registro es una calificacion o una eleccion para un perfil dado
+type Registro struct {
+	Fecha        time.Time                `json:"fecha"`
+	Tipo         string                   `json:"tipo"`
+	Rol          string                   `json:"rol"`
+	Perfil       inferencia.PerfilCarrera `json:"perfil"`
+	Carrera      string                   `json:"carrera"` // facultad/carrera
+	Calificacion int                      `json:"calificacion,omitempty"`
+}
+
+// Validar revisa tipo, rol y calificacion; perfil y carrera los valida
+// quien conoce el catalogo.
+func (r *Registro) Validar() error {
+	switch r.Tipo {
+	case TipoEleccion:
+		if r.Calificacion != 0 {
+			return fmt.Errorf("%w: una eleccion no lleva calificacion", ErrRegistroInvalido)
+		}
+	case TipoCalificacion:
+		if r.Calificacion < 1 || r.Calificacion > 5 {
+			return fmt.Errorf("%w: calificacion %d, use 1 a 5", ErrRegistroInvalido, r.Calificacion)
+		}
+	default:
+		return fmt.Errorf("%w: tipo %q, use %s o %s", ErrRegistroInvalido, r.Tipo, TipoCalificacion, TipoEleccion)
+	}
+	if r.Rol == "" {
+		r.Rol = Roles[0]
+	}
+	for _, rol := range Roles {
+		if r.Rol == rol {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: rol %q, use estudiante u orientador", ErrRegistroInvalido, r.Rol)
+}
+
+// Positivo indica si el registro sirve como ejemplo de carrera elegida
+func (r Registro) Positivo() bool {
+	return r.Tipo == TipoEleccion || r.Calificacion >= CalificacionPositiva
+}
+
+// Almacen agrega registros al final de un archivo JSONL
+type Almacen struct {
+	Path string
+	mu   sync.Mutex
+}
+
+// Agregar escribe un registro (con la fecha actual si no trae)
+func (a *Almacen) Agregar(r Registro) error {
+	if r.Fecha.IsZero() {
+		r.Fecha = time.Now()
+	}
+	data, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if err := os.MkdirAll(filepath.Dir(a.Path), 0o755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(a.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.Write(append(data, '\n'))
+	return err
+}
+
+// Leer devuelve todos los registros del archivo (vacio si no existe)
+func Leer(path string) ([]Registro, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return []Registro{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	registros := []Registro{}
+	sc := bufio.NewScanner(f)
+	linea := 0
+	for sc.Scan() {
+		linea++
+		if len(sc.Bytes()) == 0 {
+			continue
+		}
+		var r Registro
+		if err := json.Unmarshal(sc.Bytes(), &r); err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, linea, err)
+		}
+		registros = append(registros, r)
+	}
+	return registros, sc.Err()
+}
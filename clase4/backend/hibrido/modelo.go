@@ -0,0 +1,192 @@
+// Package hibrido combina el match de las reglas Prolog con la
+// probabilidad que da un modelo softmax entrenado sobre perfiles
+// codificados en one-hot.
+package hibrido
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"gonum.org/v1/gonum/mat"
+
+	"unmatch/backend/algorithms"
+	"unmatch/backend/feedback"
+	"unmatch/backend/inferencia"
+)
+
+// DefaultModeloPath es donde entrenarhibrido deja el modelo
+const DefaultModeloPath = "./weights/modelo_hibrido.json"
+
+// MezclaPorDefecto: peso del modelo en el puntaje final (0 = solo reglas)
+const MezclaPorDefecto = 0.5
+
+// ErrSinModelo: todavia no se entreno el modelo hibrido
+var ErrSinModelo = errors.New("no hay modelo hibrido entrenado, corra go run ./cmd/entrenarhibrido")
+
+// Codificador pasa un perfil a one-hot: un bloque por aptitud, y bloques
+// multi-hot para habilidades e intereses (habilidad y habilidad2 caen en
+// el mismo bloque).
+type Codificador struct {
+	Vocabulario inferencia.Vocabulario `json:"vocabulario"`
+}
+
+// Nombres de cada feature, p. ej. "aptitud=biologia"
+func (c Codificador) Nombres() []string {
+	out := []string{}
+	for _, v := range c.Vocabulario.Aptitudes {
+		out = append(out, "aptitud="+v)
+	}
+	for _, v := range c.Vocabulario.Habilidades {
+		out = append(out, "habilidad="+v)
+	}
+	for _, v := range c.Vocabulario.Intereses {
+		out = append(out, "interes="+v)
+	}
+	return out
+}
+
+// Codificar devuelve el vector one-hot; los valores que el modelo no
+// conoce (vocabulario nuevo) quedan en cero.
+func (c Codificador) Codificar(p inferencia.PerfilCarrera) []float64 {
+	x := make([]float64, len(c.Nombres()))
+	marcar := func(offset int, vocab []string, valores ...string) {
+		for _, v := range valores {
+			for i, w := range vocab {
+				if v != "" && v == w {
+					x[offset+i] = 1
+				}
+			}
+		}
+	}
+	v := c.Vocabulario
+	marcar(0, v.Aptitudes, p.Aptitud)
+	marcar(len(v.Aptitudes), v.Habilidades, p.Habilidad, p.Habilidad2)
+	marcar(len(v.Aptitudes)+len(v.Habilidades), v.Intereses, p.Interes, p.Interes2)
+	return x
+}
+
+// Modelo es el softmax entrenado junto con su codificacion y clases
+type Modelo struct {
+	Codificador Codificador `json:"codificador"`
+	Carreras    []string    `json:"carreras"` // clase k -> facultad/carrera
+	W           [][]float64 `json:"w"`        // (features x clases)
+	B           []float64   `json:"b"`
+	Mezcla      float64     `json:"mezcla"`
+	Muestras    int         `json:"muestras"`
+	Accuracy    float64     `json:"accuracy"`
+	Seed        int64       `json:"seed"` // de los pesos iniciales del softmax
+	Fecha       time.Time   `json:"fecha"`
+
+	softmax *algorithms.SoftmaxRegression
+}
+
+// Entrenar arma el dataset con un ejemplo por hecho carrera/5 (el
+// perfil que describe el hecho) mas los ejemplos positivos del feedback,
+// y ajusta algorithms.SoftmaxRegression.
+func Entrenar(hechos []inferencia.HechoCarrera, voc inferencia.Vocabulario, registros []feedback.Registro, hp feedback.Hiperparametros) (*Modelo, error) {
+	mod := &Modelo{Codificador: Codificador{Vocabulario: voc}, Carreras: []string{}, Mezcla: MezclaPorDefecto}
+	indice := map[string]int{}
+	for _, h := range hechos {
+		if _, ok := indice[h.Clave()]; !ok {
+			indice[h.Clave()] = len(mod.Carreras)
+			mod.Carreras = append(mod.Carreras, h.Clave())
+		}
+	}
+
+	data := []float64{}
+	y := []int{}
+	agregar := func(p inferencia.PerfilCarrera, carrera string) {
+		data = append(data, mod.Codificador.Codificar(p)...)
+		y = append(y, indice[carrera])
+	}
+	for _, h := range hechos {
+		agregar(inferencia.PerfilCarrera{Aptitud: h.Aptitud, Habilidad: h.Habilidad, Interes: h.Interes}, h.Clave())
+	}
+	for _, r := range registros {
+		if _, ok := indice[r.Carrera]; ok && r.Positivo() {
+			agregar(r.Perfil, r.Carrera)
+		}
+	}
+	if len(y) == 0 {
+		return nil, fmt.Errorf("no hay hechos carrera/5 para entrenar")
+	}
+
+	nFeatures := len(mod.Codificador.Nombres())
+	X := mat.NewDense(len(y), nFeatures, data)
+	sm := algorithms.NewSoftmaxRegression(hp.Lr, hp.NIter, hp.RegLambda)
+	sm.Seed = hp.Seed
+	sm.Fit(X, y)
+
+	mod.softmax = sm
+	mod.Muestras = len(y)
+	mod.Seed = sm.Seed
+	mod.Accuracy = sm.Accuracy(X, y)
+	mod.Fecha = time.Now()
+	r, k := sm.W.Dims()
+	mod.W = make([][]float64, r)
+	for i := 0; i < r; i++ {
+		mod.W[i] = append([]float64(nil), sm.W.RawRowView(i)...)
+	}
+	mod.B = make([]float64, k)
+	for j := 0; j < k; j++ {
+		mod.B[j] = sm.B.AtVec(j)
+	}
+	return mod, nil
+}
+
+// Probabilidades devuelve P(carrera | perfil) por clave facultad/carrera
+func (mod *Modelo) Probabilidades(p inferencia.PerfilCarrera) map[string]float64 {
+	x := mod.Codificador.Codificar(p)
+	probs := mod.softmax.PredictProba(mat.NewDense(1, len(x), x))
+	out := map[string]float64{}
+	_, k := probs.Dims()
+	for j := 0; j < k && j < len(mod.Carreras); j++ {
+		out[mod.Carreras[j]] = probs.At(0, j)
+	}
+	return out
+}
+
+// Guardar escribe el modelo en JSON
+func (mod *Modelo) Guardar(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(mod, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// CargarModelo lee el modelo; devuelve ErrSinModelo si no existe
+func CargarModelo(path string) (*Modelo, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, ErrSinModelo
+	}
+	if err != nil {
+		return nil, err
+	}
+	var mod Modelo
+	if err := json.Unmarshal(data, &mod); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	nFeatures := len(mod.Codificador.Nombres())
+	if len(mod.W) != nFeatures || len(mod.B) == 0 {
+		return nil, fmt.Errorf("%s: dimensiones invalidas (%d filas para %d features)", path, len(mod.W), nFeatures)
+	}
+	w := mat.NewDense(nFeatures, len(mod.B), nil)
+	for i, fila := range mod.W {
+		if len(fila) != len(mod.B) {
+			return nil, fmt.Errorf("%s: la fila %d de W tiene %d columnas, se esperaban %d", path, i, len(fila), len(mod.B))
+		}
+		w.SetRow(i, fila)
+	}
+	mod.softmax = &algorithms.SoftmaxRegression{W: w, B: mat.NewVecDense(len(mod.B), append([]float64(nil), mod.B...))}
+	return &mod, nil
+}
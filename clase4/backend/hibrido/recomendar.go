@@ -0,0 +1,67 @@
+package hibrido
+
+import (
+	"fmt"
+	"math"
+
+	"unmatch/backend/inferencia"
+)
+
+// CarreraHibrida es una recomendacion con ambos componentes
+type CarreraHibrida struct {
+	Facultad string  `json:"facultad"`
+	Carrera  string  `json:"carrera"`
+	Match    float64 `json:"match"`  // puntaje final mezclado
+	Reglas   float64 `json:"reglas"` // match de las reglas Prolog (%)
+	Modelo   float64 `json:"modelo"` // probabilidad del softmax (%)
+}
+
+// ValidarMezcla revisa que la mezcla este entre 0 (solo reglas) y 1
+// (solo modelo)
+func ValidarMezcla(mezcla float64) error {
+	if mezcla < 0 || mezcla > 1 || math.IsNaN(mezcla) {
+		return fmt.Errorf("mezcla %v fuera de rango, use un valor entre 0 y 1", mezcla)
+	}
+	return nil
+}
+
+// Combinar mezcla, para cada carrera, el mejor match de las reglas con
+// la probabilidad del modelo:
+//
+//	match = mezcla*modelo + (1-mezcla)*reglas
+//
+// y ordena y filtra igual que inferencia.RankearCarreras sobre el
+// puntaje final.
+func Combinar(reglas []inferencia.CarreraRecomendada, probs map[string]float64, mezcla float64, f inferencia.FiltroRanking) []CarreraHibrida {
+	componentes := map[string]*CarreraHibrida{}
+	orden := []string{}
+	for _, r := range reglas {
+		c, ok := componentes[r.Clave()]
+		if !ok {
+			c = &CarreraHibrida{Facultad: r.Facultad, Carrera: r.Carrera}
+			componentes[r.Clave()] = c
+			orden = append(orden, r.Clave())
+		}
+		if r.Match > c.Reglas {
+			c.Reglas = r.Match
+		}
+	}
+
+	mezclados := []inferencia.CarreraRecomendada{}
+	for _, clave := range orden {
+		c := componentes[clave]
+		c.Modelo = redondear(probs[clave] * 100)
+		c.Match = redondear(mezcla*c.Modelo + (1-mezcla)*c.Reglas)
+		mezclados = append(mezclados, inferencia.CarreraRecomendada{Facultad: c.Facultad, Carrera: c.Carrera, Match: c.Match})
+	}
+
+	out := []CarreraHibrida{}
+	for _, r := range inferencia.RankearCarreras(mezclados, f) {
+		out = append(out, *componentes[r.Clave()])
+	}
+	return out
+}
+
+func redondear(x float64) float64 {
+	return math.Round(x*100) / 100
+}
@@ -0,0 +1,94 @@
+package inferencia
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+
+	"github.com/mndrix/golog"
+)
+
+// Rutas por defecto de la base de conocimiento (relativas a backend/)
+const (
+	DefaultConocimientoPath = "./prolog/conocimiento.pl"
+	DefaultCarrerasPath     = "./prolog/carreras.pl"
+)
+
+type CarreraRecomendada struct {
+	Facultad string  `json:"facultad"`
+	Carrera  string  `json:"carrera"`
+	Match    float64 `json:"match"`
+}
+
+// CargarProlog lee un archivo .pl completo
+func CargarProlog(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// CargarMaquina levanta una maquina de inferencia con los archivos
+// indicados, en el orden en que se reciben.
+func CargarMaquina(paths ...string) (golog.Machine, error) {
+	m := golog.NewMachine()
+	for _, p := range paths {
+		programa, err := CargarProlog(p)
+		if err != nil {
+			return nil, fmt.Errorf("no se pudo leer %s: %w", p, err)
+		}
+		m = m.Consult(programa)
+	}
+	return m, nil
+}
+
+// RecomendarCarreras puntua cada ficha de carrera contra el perfil con
+// los pesos por defecto. La consulta respeta ctx y LimitesPorDefecto.
+func RecomendarCarreras(ctx context.Context, m golog.Machine, aptitud, habilidad1, interes1, habilidad2, interes2 string) ([]CarreraRecomendada, error) {
+	perfil := PerfilCarrera{Aptitud: aptitud, Habilidad: habilidad1, Interes: interes1, Habilidad2: habilidad2, Interes2: interes2}
+	return RecomendarCarrerasConPesos(ctx, m, perfil, PesosPorDefecto)
+}
+
+// RecomendarCarrerasConPesos es RecomendarCarreras con pesos propios
+// (p. ej. los aprendidos del feedback de los estudiantes). Devuelve un
+// resultado por ficha, en el orden de carrera_ficha/3; cada criterio
+// suma el peso del atributo que coincide (ver IndicadoresFicha).
+func RecomendarCarrerasConPesos(ctx context.Context, m golog.Machine, perfil PerfilCarrera, pesos Pesos) ([]CarreraRecomendada, error) {
+	fichas, err := CargarFichas(ctx, m)
+	if err != nil {
+		return nil, err
+	}
+
+	results := []CarreraRecomendada{}
+	for _, f := range fichas {
+		results = append(results, CarreraRecomendada{
+			Facultad: f.Facultad,
+			Carrera:  f.Carrera,
+			Match:    pesos.Puntaje(perfil.IndicadoresFicha(f)),
+		})
+	}
+	return results, nil
+}
+
+// Clave identifica una carrera dentro de la base (facultad/carrera)
+func (c CarreraRecomendada) Clave() string {
+	return c.Facultad + "/" + c.Carrera
+}
+
+// VersionKB identifica el contenido de los archivos de la base (primeros
+// 12 caracteres del SHA-256), para saber con que base se hizo una
+// recomendacion.
+func VersionKB(paths ...string) (string, error) {
+	h := sha256.New()
+	for _, p := range paths {
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return "", err
+		}
+		h.Write(data)
+	}
+	return hex.EncodeToString(h.Sum(nil))[:12], nil
+}
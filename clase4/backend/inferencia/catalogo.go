@@ -0,0 +1,263 @@
+package inferencia
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/mndrix/golog"
+)
+
+// CarreraCatalogo reune todos los hechos carrera/5 de una carrera y los
+// datos de su ficha
+type CarreraCatalogo struct {
+	Facultad     string        `json:"facultad"`
+	Carrera      string        `json:"carrera"`
+	Aptitudes    []string      `json:"aptitudes"`
+	Habilidades  []string      `json:"habilidades"`
+	Intereses    []string      `json:"intereses"`
+	Duracion     int           `json:"duracion"`
+	Sedes        []SedeCarrera `json:"sedes"`
+	NotasMinimas []NotaMinima  `json:"notas_minimas"`
+}
+
+// Vocabulario son los valores que aparecen en cada posicion de carrera/5
+type Vocabulario struct {
+	Aptitudes   []string `json:"aptitud"`
+	Habilidades []string `json:"habilidad"`
+	Intereses   []string `json:"interes"`
+}
+
+// Catalogo se deriva de los hechos carrera/5 y de las fichas de la base
+type Catalogo struct {
+	Carreras    []CarreraCatalogo `json:"carreras"`
+	Facultades  []string          `json:"facultades"`
+	Vocabulario Vocabulario       `json:"vocabulario"`
+	Sedes       []string          `json:"sedes"`
+	Modalidades []string          `json:"modalidades"`
+	Materias    []string          `json:"materias"` // con nota minima en alguna carrera
+
+	fichas map[string]Ficha
+}
+
+// CargarCatalogo recorre carrera/5 y las fichas y arma el catalogo
+// (ordenado alfabeticamente para que la respuesta sea estable).
+func CargarCatalogo(ctx context.Context, m golog.Machine) (Catalogo, error) {
+	solutions, err := ProveAll(ctx, m, "carrera(Fac, Carr, Apt, Hab, Int).", LimitesPorDefecto)
+	if err != nil {
+		return Catalogo{}, err
+	}
+	fichas, err := CargarFichas(ctx, m)
+	if err != nil {
+		return Catalogo{}, err
+	}
+
+	carreras := map[string]*CarreraCatalogo{}
+	facultades := map[string]bool{}
+	apts, habs, ints := map[string]bool{}, map[string]bool{}, map[string]bool{}
+
+	for _, sol := range solutions {
+		fac := sol.ByName_("Fac").String()
+		carr := sol.ByName_("Carr").String()
+		apt := sol.ByName_("Apt").String()
+		hab := sol.ByName_("Hab").String()
+		in := sol.ByName_("Int").String()
+
+		clave := fac + "/" + carr
+		c, ok := carreras[clave]
+		if !ok {
+			c = &CarreraCatalogo{Facultad: fac, Carrera: carr}
+			carreras[clave] = c
+		}
+		c.Aptitudes = agregarUnico(c.Aptitudes, apt)
+		c.Habilidades = agregarUnico(c.Habilidades, hab)
+		c.Intereses = agregarUnico(c.Intereses, in)
+
+		facultades[fac] = true
+		apts[apt] = true
+		habs[hab] = true
+		ints[in] = true
+	}
+
+	sedes, modalidades, materias := map[string]bool{}, map[string]bool{}, map[string]bool{}
+	porClave := map[string]Ficha{}
+	for _, f := range fichas {
+		porClave[f.Clave()] = f
+		for _, s := range f.Sedes {
+			sedes[s.Sede] = true
+			modalidades[s.Modalidad] = true
+		}
+		for _, n := range f.NotasMinimas {
+			materias[n.Materia] = true
+		}
+		if c, ok := carreras[f.Clave()]; ok {
+			c.Duracion, c.Sedes, c.NotasMinimas = f.Duracion, f.Sedes, f.NotasMinimas
+		}
+	}
+
+	cat := Catalogo{
+		Carreras:   []CarreraCatalogo{},
+		Facultades: ordenadas(facultades),
+		Vocabulario: Vocabulario{
+			Aptitudes:   ordenadas(apts),
+			Habilidades: ordenadas(habs),
+			Intereses:   ordenadas(ints),
+		},
+		Sedes:       ordenadas(sedes),
+		Modalidades: ordenadas(modalidades),
+		Materias:    ordenadas(materias),
+		fichas:      porClave,
+	}
+	for _, c := range carreras {
+		cat.Carreras = append(cat.Carreras, *c)
+	}
+	sort.Slice(cat.Carreras, func(i, j int) bool {
+		return cat.Carreras[i].Facultad+"/"+cat.Carreras[i].Carrera < cat.Carreras[j].Facultad+"/"+cat.Carreras[j].Carrera
+	})
+	return cat, nil
+}
+
+// FiltroFicha son los filtros de /recomendar sobre la ficha de la carrera
+type FiltroFicha struct {
+	Sede      string             // vacio = todas
+	Modalidad string             // vacio = todas
+	Notas     map[string]float64 // materia -> nota del estudiante (0 a 100)
+}
+
+// Descartada es una carrera con match que no cumple una nota minima
+type Descartada struct {
+	Facultad string `json:"facultad"`
+	Carrera  string `json:"carrera"`
+	Motivo   string `json:"motivo"`
+}
+
+// FiltrarPorFicha saca de los resultados las carreras que no se dictan
+// en la sede/modalidad pedidas o cuyas notas minimas el estudiante no
+// alcanza. Devuelve tambien las que tenian match y se descartaron por
+// nota, para poder explicarselo al estudiante.
+func (cat Catalogo) FiltrarPorFicha(resultados []CarreraRecomendada, f FiltroFicha) ([]CarreraRecomendada, []Descartada) {
+	quedan := []CarreraRecomendada{}
+	descartadas := []Descartada{}
+	vistas := map[string]bool{}
+	for _, r := range resultados {
+		ficha, ok := cat.fichas[r.Clave()]
+		if !ok {
+			quedan = append(quedan, r)
+			continue
+		}
+		if !ficha.Dictada(f.Sede, f.Modalidad) {
+			continue
+		}
+		if faltan := ficha.NotasInsuficientes(f.Notas); len(faltan) > 0 {
+			if r.Match > 0 && !vistas[r.Clave()] {
+				vistas[r.Clave()] = true
+				motivos := []string{}
+				for _, n := range faltan {
+					motivos = append(motivos, fmt.Sprintf("%s: pide %g y tiene %g", n.Materia, n.Nota, f.Notas[n.Materia]))
+				}
+				descartadas = append(descartadas, Descartada{Facultad: r.Facultad, Carrera: r.Carrera, Motivo: strings.Join(motivos, "; ")})
+			}
+			continue
+		}
+		quedan = append(quedan, r)
+	}
+	return quedan, descartadas
+}
+
+// ErrorCampo describe un valor que no existe en el vocabulario
+type ErrorCampo struct {
+	Campo      string   `json:"campo"`
+	Valor      string   `json:"valor"`
+	Sugerencia string   `json:"sugerencia,omitempty"`
+	Validos    []string `json:"validos"`
+}
+
+func (e ErrorCampo) Error() string {
+	if e.Sugerencia != "" {
+		return fmt.Sprintf("%s: '%s' no existe, ¿quiso decir '%s'?", e.Campo, e.Valor, e.Sugerencia)
+	}
+	return fmt.Sprintf("%s: '%s' no existe", e.Campo, e.Valor)
+}
+
+// Validar revisa un valor contra las opciones validas; los valores
+// vacios se aceptan (campos opcionales).
+func Validar(campo, valor string, validos []string) *ErrorCampo {
+	if valor == "" {
+		return nil
+	}
+	for _, v := range validos {
+		if v == valor {
+			return nil
+		}
+	}
+	return &ErrorCampo{Campo: campo, Valor: valor, Sugerencia: Sugerir(valor, validos), Validos: validos}
+}
+
+// Sugerir devuelve la opcion mas cercana por distancia de edicion, o
+// vacio si ninguna esta lo bastante cerca para ser un error de tipeo.
+func Sugerir(valor string, opciones []string) string {
+	mejor := ""
+	mejorDist := -1
+	for _, o := range opciones {
+		d := Levenshtein(valor, o)
+		if mejorDist == -1 || d < mejorDist {
+			mejor, mejorDist = o, d
+		}
+	}
+	// tolerancia: hasta un tercio del largo (minimo 2 ediciones)
+	limite := len([]rune(valor)) / 3
+	if limite < 2 {
+		limite = 2
+	}
+	if mejorDist == -1 || mejorDist > limite {
+		return ""
+	}
+	return mejor
+}
+
+// Levenshtein calcula la distancia de edicion entre a y b (por runas)
+func Levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			costo := 1
+			if ra[i-1] == rb[j-1] {
+				costo = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+costo)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
+
+// NormalizarTermino pasa "Diseno Grafico " a "diseno_grafico" para
+// comparar con los atomos de la base
+func NormalizarTermino(s string) string {
+	return strings.Join(strings.Fields(strings.ToLower(s)), "_")
+}
+
+func agregarUnico(lista []string, v string) []string {
+	for _, x := range lista {
+		if x == v {
+			return lista
+		}
+	}
+	return append(lista, v)
+}
+
+func ordenadas(set map[string]bool) []string {
+	out := make([]string, 0, len(set))
+	for k := range set {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	return out
+}
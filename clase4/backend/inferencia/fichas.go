@@ -0,0 +1,244 @@
+package inferencia
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mndrix/golog"
+	"github.com/mndrix/golog/term"
+)
+
+// Tipos de atributo de carrera_atributo/4
+const (
+	AtributoAptitud   = "aptitud"
+	AtributoHabilidad = "habilidad"
+	AtributoInteres   = "interes"
+)
+
+// Modalidades validas de carrera_sede/3
+var Modalidades = []string{"presencial", "semipresencial", "virtual"}
+
+// AtributoPonderado es un valor de la carrera con su peso (0 a 1]
+type AtributoPonderado struct {
+	Valor string  `json:"valor"`
+	Peso  float64 `json:"peso"`
+}
+
+// SedeCarrera es una sede donde se dicta la carrera y en que modalidad
+type SedeCarrera struct {
+	Sede      string `json:"sede"`
+	Modalidad string `json:"modalidad"`
+}
+
+// NotaMinima es la nota (sobre 100) que pide la carrera en una materia
+type NotaMinima struct {
+	Materia string  `json:"materia"`
+	Nota    float64 `json:"nota"`
+}
+
+// Ficha reune los hechos carrera_ficha, carrera_atributo, carrera_sede y
+// carrera_nota_minima de una carrera.
+type Ficha struct {
+	Facultad     string              `json:"facultad"`
+	Carrera      string              `json:"carrera"`
+	Duracion     int                 `json:"duracion"` // años
+	Aptitudes    []AtributoPonderado `json:"aptitudes"`
+	Habilidades  []AtributoPonderado `json:"habilidades"`
+	Intereses    []AtributoPonderado `json:"intereses"`
+	Sedes        []SedeCarrera       `json:"sedes"`
+	NotasMinimas []NotaMinima        `json:"notas_minimas"`
+}
+
+// Clave identifica la carrera (facultad/carrera)
+func (f Ficha) Clave() string {
+	return f.Facultad + "/" + f.Carrera
+}
+
+// Peso devuelve el peso del valor en los atributos del tipo indicado, o
+// 0 si la carrera no lo tiene.
+func (f Ficha) Peso(tipo, valor string) float64 {
+	if valor == "" {
+		return 0
+	}
+	var lista []AtributoPonderado
+	switch tipo {
+	case AtributoAptitud:
+		lista = f.Aptitudes
+	case AtributoHabilidad:
+		lista = f.Habilidades
+	case AtributoInteres:
+		lista = f.Intereses
+	}
+	peso := 0.0
+	for _, a := range lista {
+		if a.Valor == valor && a.Peso > peso {
+			peso = a.Peso
+		}
+	}
+	return peso
+}
+
+// Dictada dice si la carrera se ofrece en la sede y modalidad pedidas
+// (vacio = cualquiera).
+func (f Ficha) Dictada(sede, modalidad string) bool {
+	if sede == "" && modalidad == "" {
+		return true
+	}
+	for _, s := range f.Sedes {
+		if (sede == "" || s.Sede == sede) && (modalidad == "" || s.Modalidad == modalidad) {
+			return true
+		}
+	}
+	return false
+}
+
+// NotasInsuficientes devuelve los requisitos que el estudiante no
+// alcanza; las materias sin nota informada no cuentan como faltantes.
+func (f Ficha) NotasInsuficientes(notas map[string]float64) []NotaMinima {
+	out := []NotaMinima{}
+	for _, n := range f.NotasMinimas {
+		if nota, ok := notas[n.Materia]; ok && nota < n.Nota {
+			out = append(out, n)
+		}
+	}
+	return out
+}
+
+// IndicadoresFicha es Indicadores sobre los atributos ponderados: cada
+// criterio vale el peso del atributo que coincide (el mayor si hay
+// varios), en el orden de Criterios.
+func (p PerfilCarrera) IndicadoresFicha(f Ficha) []float64 {
+	return []float64{
+		f.Peso(AtributoAptitud, p.Aptitud),
+		max(f.Peso(AtributoHabilidad, p.Habilidad), f.Peso(AtributoHabilidad, p.Habilidad2)),
+		max(f.Peso(AtributoInteres, p.Interes), f.Peso(AtributoInteres, p.Interes2)),
+		// cruce: el perfil aparece en otro tipo de atributo de la carrera
+		max(f.Peso(AtributoInteres, p.Habilidad), f.Peso(AtributoAptitud, p.Habilidad2),
+			f.Peso(AtributoHabilidad, p.Interes), f.Peso(AtributoHabilidad, p.Interes2)),
+	}
+}
+
+// ValoresComparados devuelve, por tipo de atributo, los valores del
+// perfil que IndicadoresFicha busca en ese tipo (cruce incluido).
+func (p PerfilCarrera) ValoresComparados() map[string][]string {
+	return map[string][]string{
+		AtributoAptitud:   {p.Aptitud, p.Habilidad2},
+		AtributoHabilidad: {p.Habilidad, p.Habilidad2, p.Interes, p.Interes2},
+		AtributoInteres:   {p.Interes, p.Interes2, p.Habilidad},
+	}
+}
+
+// CargarFichas lee las fichas en el orden de carrera_ficha/3. Un hecho
+// auxiliar de una carrera sin ficha, un tipo o modalidad desconocidos o
+// un peso fuera de (0, 1] son errores de la base.
+func CargarFichas(ctx context.Context, m golog.Machine) ([]Ficha, error) {
+	solutions, err := ProveAll(ctx, m, "carrera_ficha(Fac, Carr, Dur).", LimitesPorDefecto)
+	if err != nil {
+		return nil, err
+	}
+	fichas := []Ficha{}
+	indice := map[string]int{}
+	for _, sol := range solutions {
+		carr := sol.ByName_("Carr").String()
+		dur, ok := numero(sol.ByName_("Dur"))
+		if !ok {
+			return nil, fmt.Errorf("carrera_ficha de %s: la duracion no es un numero", carr)
+		}
+		if _, dup := indice[carr]; dup {
+			return nil, fmt.Errorf("carrera_ficha de %s: la carrera esta repetida", carr)
+		}
+		indice[carr] = len(fichas)
+		fichas = append(fichas, Ficha{
+			Facultad:     sol.ByName_("Fac").String(),
+			Carrera:      carr,
+			Duracion:     int(dur),
+			Aptitudes:    []AtributoPonderado{},
+			Habilidades:  []AtributoPonderado{},
+			Intereses:    []AtributoPonderado{},
+			Sedes:        []SedeCarrera{},
+			NotasMinimas: []NotaMinima{},
+		})
+	}
+
+	// ficha devuelve la ficha de la carrera de un hecho auxiliar
+	ficha := func(hecho string, carr term.Term) (*Ficha, error) {
+		i, ok := indice[carr.String()]
+		if !ok {
+			return nil, fmt.Errorf("%s de %s: la carrera no tiene carrera_ficha/3", hecho, carr)
+		}
+		return &fichas[i], nil
+	}
+
+	solutions, err = ProveAll(ctx, m, "carrera_atributo(Carr, Tipo, Valor, Peso).", LimitesPorDefecto)
+	if err != nil {
+		return nil, err
+	}
+	for _, sol := range solutions {
+		f, err := ficha("carrera_atributo", sol.ByName_("Carr"))
+		if err != nil {
+			return nil, err
+		}
+		peso, ok := numero(sol.ByName_("Peso"))
+		if !ok || peso <= 0 || peso > 1 {
+			return nil, fmt.Errorf("carrera_atributo de %s: peso %s fuera de (0, 1]", f.Carrera, sol.ByName_("Peso"))
+		}
+		a := AtributoPonderado{Valor: sol.ByName_("Valor").String(), Peso: peso}
+		switch tipo := sol.ByName_("Tipo").String(); tipo {
+		case AtributoAptitud:
+			f.Aptitudes = append(f.Aptitudes, a)
+		case AtributoHabilidad:
+			f.Habilidades = append(f.Habilidades, a)
+		case AtributoInteres:
+			f.Intereses = append(f.Intereses, a)
+		default:
+			return nil, fmt.Errorf("carrera_atributo de %s: tipo %q, use aptitud, habilidad o interes", f.Carrera, tipo)
+		}
+	}
+
+	solutions, err = ProveAll(ctx, m, "carrera_sede(Carr, Sede, Mod).", LimitesPorDefecto)
+	if err != nil {
+		return nil, err
+	}
+	for _, sol := range solutions {
+		f, err := ficha("carrera_sede", sol.ByName_("Carr"))
+		if err != nil {
+			return nil, err
+		}
+		mod := sol.ByName_("Mod").String()
+		if e := Validar("modalidad", mod, Modalidades); e != nil {
+			return nil, fmt.Errorf("carrera_sede de %s: %w", f.Carrera, e)
+		}
+		f.Sedes = append(f.Sedes, SedeCarrera{Sede: sol.ByName_("Sede").String(), Modalidad: mod})
+	}
+
+	solutions, err = ProveAll(ctx, m, "carrera_nota_minima(Carr, Materia, Nota).", LimitesPorDefecto)
+	if err != nil {
+		return nil, err
+	}
+	for _, sol := range solutions {
+		f, err := ficha("carrera_nota_minima", sol.ByName_("Carr"))
+		if err != nil {
+			return nil, err
+		}
+		nota, ok := numero(sol.ByName_("Nota"))
+		if !ok || nota < 0 || nota > 100 {
+			return nil, fmt.Errorf("carrera_nota_minima de %s: nota %s fuera de 0 a 100", f.Carrera, sol.ByName_("Nota"))
+		}
+		f.NotasMinimas = append(f.NotasMinimas, NotaMinima{Materia: sol.ByName_("Materia").String(), Nota: nota})
+	}
+
+	for _, f := range fichas {
+		if len(f.Aptitudes) == 0 || len(f.Habilidades) == 0 || len(f.Intereses) == 0 {
+			return nil, fmt.Errorf("la carrera %s necesita al menos una aptitud, una habilidad y un interes", f.Carrera)
+		}
+	}
+	return fichas, nil
+}
+
+// numero convierte un termino entero o flotante
+func numero(t term.Term) (float64, bool) {
+	if !term.IsNumber(t) {
+		return 0, false
+	}
+	return t.(term.Number).Float64(), true
+}
@@ -0,0 +1,142 @@
+package inferencia
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/mndrix/golog"
+)
+
+// DefaultPesosPath es donde ajustarpesos deja los pesos aprendidos
+const DefaultPesosPath = "./weights/pesos_match.json"
+
+// Criterios del puntaje, en el orden de Pesos.Vector
+var Criterios = []string{"aptitud", "habilidad", "interes", "cruce"}
+
+// divisorMatch mantiene la escala original del puntaje (4 criterios
+// sobre 5), asi los pesos por defecto dan los mismos porcentajes.
+const divisorMatch = 5.0
+
+// Pesos de cada criterio al puntuar un hecho carrera/5. Los aprendidos
+// se normalizan para sumar lo mismo que los por defecto.
+type Pesos struct {
+	Aptitud   float64   `json:"aptitud"`
+	Habilidad float64   `json:"habilidad"`
+	Interes   float64   `json:"interes"`
+	Cruce     float64   `json:"cruce"`
+	Origen    string    `json:"origen,omitempty"`
+	Muestras  int       `json:"muestras,omitempty"`
+	Seed      int64     `json:"seed,omitempty"` // del softmax que los ajusto
+	Fecha     time.Time `json:"fecha,omitempty"`
+}
+
+// PesosPorDefecto reproduce el puntaje fijo original
+var PesosPorDefecto = Pesos{Aptitud: 1, Habilidad: 1, Interes: 1, Cruce: 1, Origen: "por_defecto"}
+
+// Vector devuelve los pesos en el orden de Criterios
+func (p Pesos) Vector() []float64 {
+	return []float64{p.Aptitud, p.Habilidad, p.Interes, p.Cruce}
+}
+
+// Puntaje convierte los indicadores de un hecho en porcentaje de match
+func (p Pesos) Puntaje(ind []float64) float64 {
+	total := 0.0
+	for i, w := range p.Vector() {
+		total += w * ind[i]
+	}
+	return total / divisorMatch * 100.0
+}
+
+// CargarPesos lee pesos aprendidos; si el archivo no existe devuelve
+// los pesos por defecto.
+func CargarPesos(path string) (Pesos, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return PesosPorDefecto, nil
+	}
+	if err != nil {
+		return PesosPorDefecto, err
+	}
+	var p Pesos
+	if err := json.Unmarshal(data, &p); err != nil {
+		return PesosPorDefecto, fmt.Errorf("%s: %w", path, err)
+	}
+	return p, nil
+}
+
+// GuardarPesos escribe los pesos en JSON
+func GuardarPesos(path string, p Pesos) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// PerfilCarrera son los campos del perfil que usa el puntaje
+type PerfilCarrera struct {
+	Aptitud    string `json:"aptitud"`
+	Habilidad  string `json:"habilidad"`
+	Interes    string `json:"interes"`
+	Habilidad2 string `json:"habilidad2"`
+	Interes2   string `json:"interes2"`
+}
+
+// HechoCarrera es un hecho carrera(Fac, Carr, Apt, Hab, Int)
+type HechoCarrera struct {
+	Facultad  string
+	Carrera   string
+	Aptitud   string
+	Habilidad string
+	Interes   string
+}
+
+// Clave identifica la carrera del hecho (facultad/carrera)
+func (h HechoCarrera) Clave() string {
+	return h.Facultad + "/" + h.Carrera
+}
+
+// LeerHechosCarrera devuelve los hechos carrera/5 en el orden de la base
+func LeerHechosCarrera(ctx context.Context, m golog.Machine) ([]HechoCarrera, error) {
+	solutions, err := ProveAll(ctx, m, "carrera(Fac, Carr, Apt, Hab, Int).", LimitesPorDefecto)
+	if err != nil {
+		return nil, err
+	}
+	hechos := make([]HechoCarrera, 0, len(solutions))
+	for _, sol := range solutions {
+		hechos = append(hechos, HechoCarrera{
+			Facultad:  sol.ByName_("Fac").String(),
+			Carrera:   sol.ByName_("Carr").String(),
+			Aptitud:   sol.ByName_("Apt").String(),
+			Habilidad: sol.ByName_("Hab").String(),
+			Interes:   sol.ByName_("Int").String(),
+		})
+	}
+	return hechos, nil
+}
+
+// Indicadores dice (1 o 0) que criterios cumple el hecho para el perfil,
+// en el orden de Criterios.
+func (p PerfilCarrera) Indicadores(h HechoCarrera) []float64 {
+	ind := make([]float64, len(Criterios))
+	if h.Aptitud == p.Aptitud {
+		ind[0] = 1
+	}
+	if h.Habilidad == p.Habilidad || h.Habilidad == p.Habilidad2 {
+		ind[1] = 1
+	}
+	if h.Interes == p.Interes || h.Interes == p.Interes2 {
+		ind[2] = 1
+	}
+	if p.Habilidad == h.Interes || p.Habilidad2 == h.Aptitud || p.Interes == h.Habilidad || p.Interes2 == h.Habilidad {
+		ind[3] = 1 // peso extra si hay cruce interesante
+	}
+	return ind
+}
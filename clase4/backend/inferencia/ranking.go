@@ -0,0 +1,73 @@
+package inferencia
+
+import (
+	"sort"
+	"strings"
+)
+
+// FiltroRanking son las opciones de /recomendar para ordenar y recortar
+// los resultados de RecomendarCarreras.
+type FiltroRanking struct {
+	TopN     int     // 0 = todas
+	MinMatch float64 // porcentaje minimo; los 0% siempre se descartan
+	Facultad string  // vacio = todas
+}
+
+// GrupoFacultad agrupa las carreras recomendadas de una facultad
+type GrupoFacultad struct {
+	Facultad string               `json:"facultad"`
+	Carreras []CarreraRecomendada `json:"carreras"`
+}
+
+// RankearCarreras deja una entrada por carrera (la de mejor match, ya
+// que una carrera puede aparecer en varios hechos), aplica los filtros
+// y ordena de mayor a menor match. Los empates respetan el orden de la
+// base de conocimiento.
+func RankearCarreras(resultados []CarreraRecomendada, f FiltroRanking) []CarreraRecomendada {
+	facultad := strings.ToLower(strings.TrimSpace(f.Facultad))
+
+	ranking := []CarreraRecomendada{}
+	posicion := map[string]int{}
+	for _, r := range resultados {
+		if r.Match == 0 || r.Match < f.MinMatch {
+			continue
+		}
+		if facultad != "" && r.Facultad != facultad {
+			continue
+		}
+		if i, ok := posicion[r.Clave()]; ok {
+			if r.Match > ranking[i].Match {
+				ranking[i].Match = r.Match
+			}
+			continue
+		}
+		posicion[r.Clave()] = len(ranking)
+		ranking = append(ranking, r)
+	}
+
+	sort.SliceStable(ranking, func(i, j int) bool {
+		return ranking[i].Match > ranking[j].Match
+	})
+
+	if f.TopN > 0 && len(ranking) > f.TopN {
+		ranking = ranking[:f.TopN]
+	}
+	return ranking
+}
+
+// AgruparPorFacultad conserva el orden del ranking: primero la facultad
+// de la mejor carrera.
+func AgruparPorFacultad(ranking []CarreraRecomendada) []GrupoFacultad {
+	grupos := []GrupoFacultad{}
+	indice := map[string]int{}
+	for _, r := range ranking {
+		i, ok := indice[r.Facultad]
+		if !ok {
+			i = len(grupos)
+			indice[r.Facultad] = i
+			grupos = append(grupos, GrupoFacultad{Facultad: r.Facultad})
+		}
+		grupos[i].Carreras = append(grupos[i].Carreras, r)
+	}
+	return grupos
+}
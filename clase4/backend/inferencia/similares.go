@@ -0,0 +1,109 @@
+package inferencia
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// ErrCarreraNoEncontrada: la clave facultad/carrera no esta en la base
+var ErrCarreraNoEncontrada = errors.New("carrera no encontrada")
+
+// CarreraSimilar es una carrera parecida a otra por sus atributos
+type CarreraSimilar struct {
+	Facultad    string   `json:"facultad"`
+	Carrera     string   `json:"carrera"`
+	Similitud   float64  `json:"similitud"`   // 0 a 100
+	Compartidos []string `json:"compartidos"` // p. ej. "aptitud=matematica"
+}
+
+// Clave identifica la carrera (facultad/carrera)
+func (c CarreraSimilar) Clave() string {
+	return c.Facultad + "/" + c.Carrera
+}
+
+// Similitud compara los atributos ponderados de dos fichas con Jaccard
+// pesado: suma de minimos sobre suma de maximos de cada tipo=valor.
+// Devuelve un porcentaje y los atributos que comparten.
+func Similitud(a, b Ficha) (float64, []string) {
+	pa, pb := atributosPorClave(a), atributosPorClave(b)
+	claves := []string{}
+	for k := range pa {
+		claves = append(claves, k)
+	}
+	for k := range pb {
+		if _, ok := pa[k]; !ok {
+			claves = append(claves, k)
+		}
+	}
+	sort.Strings(claves)
+
+	minimos, maximos := 0.0, 0.0
+	compartidos := []string{}
+	for _, k := range claves {
+		minimos += math.Min(pa[k], pb[k])
+		maximos += math.Max(pa[k], pb[k])
+		if pa[k] > 0 && pb[k] > 0 {
+			compartidos = append(compartidos, k)
+		}
+	}
+	if maximos == 0 {
+		return 0, compartidos
+	}
+	return math.Round(minimos/maximos*1000) / 10, compartidos
+}
+
+// CarrerasSimilares ordena las demas carreras por similitud con la de
+// clave facultad/carrera; descarta las que no comparten atributos y
+// recorta a topN (0 = todas). Los empates respetan el orden de la base.
+func CarrerasSimilares(fichas []Ficha, clave string, topN int) ([]CarreraSimilar, error) {
+	var base *Ficha
+	for i := range fichas {
+		if fichas[i].Clave() == clave {
+			base = &fichas[i]
+		}
+	}
+	if base == nil {
+		return nil, fmt.Errorf("%w: %s", ErrCarreraNoEncontrada, clave)
+	}
+
+	out := []CarreraSimilar{}
+	for _, f := range fichas {
+		if f.Clave() == clave {
+			continue
+		}
+		sim, compartidos := Similitud(*base, f)
+		if sim == 0 {
+			continue
+		}
+		out = append(out, CarreraSimilar{Facultad: f.Facultad, Carrera: f.Carrera, Similitud: sim, Compartidos: compartidos})
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		return out[i].Similitud > out[j].Similitud
+	})
+	if topN > 0 && len(out) > topN {
+		out = out[:topN]
+	}
+	return out, nil
+}
+
+// atributosPorClave indexa los atributos de la ficha por "tipo=valor"
+func atributosPorClave(f Ficha) map[string]float64 {
+	out := map[string]float64{}
+	grupos := []struct {
+		tipo  string
+		lista []AtributoPonderado
+	}{
+		{AtributoAptitud, f.Aptitudes},
+		{AtributoHabilidad, f.Habilidades},
+		{AtributoInteres, f.Intereses},
+	}
+	for _, g := range grupos {
+		for _, a := range g.lista {
+			k := g.tipo + "=" + a.Valor
+			out[k] = math.Max(out[k], a.Peso)
+		}
+	}
+	return out
+}
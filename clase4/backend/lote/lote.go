@@ -0,0 +1,314 @@
+// Package lote procesa planillas (CSV o XLSX) con los perfiles de un
+// curso completo: recomienda carreras a cada fila en paralelo y arma una
+// planilla de salida con el top-N y los errores de cada fila.
+package lote
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/mndrix/golog"
+	"github.com/xuri/excelize/v2"
+
+	"unmatch/backend/inferencia"
+)
+
+// Formatos de planilla
+const (
+	FormatoCSV  = "csv"
+	FormatoXLSX = "xlsx"
+)
+
+// MaxFilas limita el tamaño de una planilla
+const MaxFilas = 5000
+
+// ErrPlanillaInvalida: el archivo no se puede leer o le faltan columnas
+var ErrPlanillaInvalida = errors.New("planilla invalida")
+
+// columnas requeridas y opcionales (encabezados, sin importar mayusculas)
+var (
+	columnasRequeridas = []string{"aptitud", "habilidad", "interes"}
+	columnasOpcionales = []string{"id", "nombre", "habilidad2", "interes2"}
+)
+
+// Fila es un estudiante de la planilla
+type Fila struct {
+	Numero int // fila en la planilla (el encabezado es la 1)
+	ID     string
+	Nombre string
+	Perfil inferencia.PerfilCarrera
+}
+
+// Resultado es la salida de una fila: sus recomendaciones o sus errores
+type Resultado struct {
+	Fila            Fila
+	Recomendaciones []inferencia.CarreraRecomendada
+	Errores         []string
+}
+
+// DetectarFormato decide por la extension y, si no alcanza, por el
+// contenido (los XLSX son archivos zip).
+func DetectarFormato(nombre string, data []byte) string {
+	nombre = strings.ToLower(nombre)
+	switch {
+	case strings.HasSuffix(nombre, ".xlsx"):
+		return FormatoXLSX
+	case strings.HasSuffix(nombre, ".csv"):
+		return FormatoCSV
+	case bytes.HasPrefix(data, []byte("PK\x03\x04")):
+		return FormatoXLSX
+	}
+	return FormatoCSV
+}
+
+// Leer parsea la planilla en el formato indicado
+func Leer(data []byte, formato string) ([]Fila, error) {
+	var tabla [][]string
+	var err error
+	switch formato {
+	case FormatoXLSX:
+		tabla, err = tablaXLSX(data)
+	case FormatoCSV:
+		tabla, err = tablaCSV(data)
+	default:
+		return nil, fmt.Errorf("%w: formato %q, use csv o xlsx", ErrPlanillaInvalida, formato)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return filas(tabla)
+}
+
+// tablaCSV acepta coma o punto y coma (Excel en español exporta con ;)
+// y quita el BOM que agregan algunas planillas.
+func tablaCSV(data []byte) ([][]string, error) {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	primera := data
+	if i := bytes.IndexByte(data, '\n'); i >= 0 {
+		primera = data[:i]
+	}
+	r := csv.NewReader(bytes.NewReader(data))
+	if bytes.Count(primera, []byte(";")) > bytes.Count(primera, []byte(",")) {
+		r.Comma = ';'
+	}
+	r.FieldsPerRecord = -1
+	r.TrimLeadingSpace = true
+	tabla, err := r.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrPlanillaInvalida, err)
+	}
+	return tabla, nil
+}
+
+// tablaXLSX lee la primera hoja
+func tablaXLSX(data []byte) ([][]string, error) {
+	f, err := excelize.OpenReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrPlanillaInvalida, err)
+	}
+	defer f.Close()
+	hojas := f.GetSheetList()
+	if len(hojas) == 0 {
+		return nil, fmt.Errorf("%w: el libro no tiene hojas", ErrPlanillaInvalida)
+	}
+	tabla, err := f.GetRows(hojas[0])
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrPlanillaInvalida, err)
+	}
+	return tabla, nil
+}
+
+// filas ubica las columnas por encabezado y arma una Fila por renglon
+// no vacio. Faltar una columna requerida invalida toda la planilla.
+func filas(tabla [][]string) ([]Fila, error) {
+	if len(tabla) == 0 {
+		return nil, fmt.Errorf("%w: la planilla esta vacia", ErrPlanillaInvalida)
+	}
+	idx := map[string]int{}
+	for i, h := range tabla[0] {
+		idx[inferencia.NormalizarTermino(h)] = i
+	}
+	for _, c := range columnasRequeridas {
+		if _, ok := idx[c]; !ok {
+			return nil, fmt.Errorf("%w: falta la columna %q (se requieren %s; opcionales %s)", ErrPlanillaInvalida,
+				c, strings.Join(columnasRequeridas, ", "), strings.Join(columnasOpcionales, ", "))
+		}
+	}
+	if len(tabla)-1 > MaxFilas {
+		return nil, fmt.Errorf("%w: %d filas, el maximo es %d", ErrPlanillaInvalida, len(tabla)-1, MaxFilas)
+	}
+
+	celda := func(renglon []string, col string) string {
+		i, ok := idx[col]
+		if !ok || i >= len(renglon) {
+			return ""
+		}
+		return strings.TrimSpace(renglon[i])
+	}
+
+	out := []Fila{}
+	for n, renglon := range tabla[1:] {
+		if strings.TrimSpace(strings.Join(renglon, "")) == "" {
+			continue
+		}
+		out = append(out, Fila{
+			Numero: n + 2,
+			ID:     celda(renglon, "id"),
+			Nombre: celda(renglon, "nombre"),
+			Perfil: inferencia.PerfilCarrera{
+				Aptitud:    celda(renglon, "aptitud"),
+				Habilidad:  celda(renglon, "habilidad"),
+				Interes:    celda(renglon, "interes"),
+				Habilidad2: celda(renglon, "habilidad2"),
+				Interes2:   celda(renglon, "interes2"),
+			},
+		})
+	}
+	return out, nil
+}
+
+// Opciones del procesamiento
+type Opciones struct {
+	TopN    int
+	Workers int // 0 = runtime.NumCPU()
+	Pesos   inferencia.Pesos
+	// Validar normaliza el perfil y devuelve sus errores (los mismos de
+	// /recomendar); una fila con errores no se consulta.
+	Validar func(*inferencia.PerfilCarrera) []string
+}
+
+// Procesar recomienda carreras a cada fila con un pool de workers. Los
+// resultados salen en el orden de la planilla; un error de consulta
+// queda en la fila y no detiene el resto.
+func Procesar(ctx context.Context, m golog.Machine, filas []Fila, op Opciones) []Resultado {
+	workers := op.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	resultados := make([]Resultado, len(filas))
+	trabajos := make(chan int)
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range trabajos {
+				resultados[i] = procesarFila(ctx, m, filas[i], op)
+			}
+		}()
+	}
+	for i := range filas {
+		trabajos <- i
+	}
+	close(trabajos)
+	wg.Wait()
+	return resultados
+}
+
+func procesarFila(ctx context.Context, m golog.Machine, f Fila, op Opciones) Resultado {
+	res := Resultado{Fila: f, Recomendaciones: []inferencia.CarreraRecomendada{}, Errores: []string{}}
+	if err := ctx.Err(); err != nil {
+		res.Errores = append(res.Errores, err.Error())
+		return res
+	}
+	if op.Validar != nil {
+		res.Errores = append(res.Errores, op.Validar(&res.Fila.Perfil)...)
+	}
+	if res.Fila.Perfil.Aptitud == "" || res.Fila.Perfil.Habilidad == "" || res.Fila.Perfil.Interes == "" {
+		res.Errores = append(res.Errores, "aptitud, habilidad e interes son requeridos")
+	}
+	if len(res.Errores) > 0 {
+		return res
+	}
+
+	recs, err := inferencia.RecomendarCarrerasConPesos(ctx, m, res.Fila.Perfil, op.Pesos)
+	if err != nil {
+		res.Errores = append(res.Errores, err.Error())
+		return res
+	}
+	res.Recomendaciones = inferencia.RankearCarreras(recs, inferencia.FiltroRanking{TopN: op.TopN})
+	return res
+}
+
+// ConErrores cuenta las filas que no se pudieron procesar
+func ConErrores(resultados []Resultado) int {
+	n := 0
+	for _, r := range resultados {
+		if len(r.Errores) > 0 {
+			n++
+		}
+	}
+	return n
+}
+
+// tablaSalida arma encabezado y renglones de la planilla de salida
+func tablaSalida(resultados []Resultado, topN int) [][]string {
+	enc := []string{"fila", "id", "nombre", "aptitud", "habilidad", "interes", "habilidad2", "interes2"}
+	for i := 1; i <= topN; i++ {
+		enc = append(enc, fmt.Sprintf("carrera_%d", i), fmt.Sprintf("match_%d", i))
+	}
+	enc = append(enc, "errores")
+
+	tabla := [][]string{enc}
+	for _, r := range resultados {
+		p := r.Fila.Perfil
+		renglon := []string{fmt.Sprint(r.Fila.Numero), r.Fila.ID, r.Fila.Nombre,
+			p.Aptitud, p.Habilidad, p.Interes, p.Habilidad2, p.Interes2}
+		for i := 0; i < topN; i++ {
+			if i < len(r.Recomendaciones) {
+				c := r.Recomendaciones[i]
+				renglon = append(renglon, c.Clave(), fmt.Sprintf("%.1f", c.Match))
+			} else {
+				renglon = append(renglon, "", "")
+			}
+		}
+		renglon = append(renglon, strings.Join(r.Errores, "; "))
+		tabla = append(tabla, renglon)
+	}
+	return tabla
+}
+
+// Escribir genera la planilla de salida en el formato indicado
+func Escribir(w io.Writer, resultados []Resultado, topN int, formato string) error {
+	tabla := tablaSalida(resultados, topN)
+	if formato == FormatoCSV {
+		cw := csv.NewWriter(w)
+		if err := cw.WriteAll(tabla); err != nil {
+			return err
+		}
+		return cw.Error()
+	}
+
+	f := excelize.NewFile()
+	defer f.Close()
+	hoja := f.GetSheetName(0)
+	for i, renglon := range tabla {
+		celda, err := excelize.CoordinatesToCellName(1, i+1)
+		if err != nil {
+			return err
+		}
+		valores := make([]interface{}, len(renglon))
+		for j, v := range renglon {
+			valores[j] = v
+			// fila y match como numeros para poder ordenar en la planilla
+			if col := tabla[0][j]; i > 0 && (col == "fila" || strings.HasPrefix(col, "match_")) {
+				if n, err := strconv.ParseFloat(v, 64); err == nil {
+					valores[j] = n
+				}
+			}
+		}
+		if err := f.SetSheetRow(hoja, celda, &valores); err != nil {
+			return err
+		}
+	}
+	return f.Write(w)
+}
@@ -0,0 +1,171 @@
+% ============================================================
+% BASE DE CONOCIMIENTO DE CARRERAS (EJEMPLO DIDÁCTICO)
+% ============================================================
+
+% Cada carrera se describe con una ficha y varios hechos auxiliares:
+%
+% carrera_ficha(Facultad, Carrera, DuracionAnios).
+% carrera_atributo(Carrera, Tipo, Valor, Peso).
+%     Tipo: aptitud | habilidad | interes. Peso entre 0 y 1: 1 es el
+%     perfil central de la carrera, los menores son afines.
+% carrera_sede(Carrera, Sede, Modalidad).
+%     Modalidad: presencial | semipresencial | virtual.
+% carrera_nota_minima(Carrera, Materia, Nota).
+%     Nota minima (sobre 100) en una materia del colegio.
+
+% Ingeniería
+carrera_ficha(ingenieria, sistemas,   5).
+carrera_ficha(ingenieria, mecanica,   5).
+carrera_ficha(ingenieria, civil,      5).
+carrera_ficha(ingenieria, industrial, 5).
+
+carrera_atributo(sistemas, aptitud,   matematica,   1.0).
+carrera_atributo(sistemas, aptitud,   logica,       1.0).
+carrera_atributo(sistemas, habilidad, programacion, 1.0).
+carrera_atributo(sistemas, habilidad, analisis,     1.0).
+carrera_atributo(sistemas, interes,   tecnologia,   1.0).
+
+carrera_atributo(mecanica, aptitud,   matematica, 1.0).
+carrera_atributo(mecanica, habilidad, diseno,     1.0).
+carrera_atributo(mecanica, habilidad, analisis,   0.4).
+carrera_atributo(mecanica, interes,   maquinas,   1.0).
+carrera_atributo(mecanica, interes,   tecnologia, 0.4).
+
+carrera_atributo(civil, aptitud,   matematica,   1.0).
+carrera_atributo(civil, habilidad, diseno,       1.0).
+carrera_atributo(civil, interes,   construccion, 1.0).
+
+carrera_atributo(industrial, aptitud,   matematica, 1.0).
+carrera_atributo(industrial, aptitud,   logica,     0.5).
+carrera_atributo(industrial, habilidad, liderazgo,  1.0).
+carrera_atributo(industrial, habilidad, analisis,   0.5).
+carrera_atributo(industrial, interes,   empresas,   1.0).
+carrera_atributo(industrial, interes,   maquinas,   0.5).
+
+carrera_sede(sistemas,   central, presencial).
+carrera_sede(sistemas,   central, virtual).
+carrera_sede(sistemas,   norte,   presencial).
+carrera_sede(mecanica,   central, presencial).
+carrera_sede(civil,      central, presencial).
+carrera_sede(civil,      norte,   presencial).
+carrera_sede(industrial, central, presencial).
+carrera_sede(industrial, norte,   semipresencial).
+
+carrera_nota_minima(sistemas,   matematica, 60).
+carrera_nota_minima(mecanica,   matematica, 60).
+carrera_nota_minima(mecanica,   fisica,     55).
+carrera_nota_minima(civil,      matematica, 60).
+carrera_nota_minima(civil,      fisica,     55).
+carrera_nota_minima(industrial, matematica, 55).
+
+% Ciencias de la salud
+carrera_ficha(medicina, medicina,   6).
+carrera_ficha(medicina, enfermeria, 4).
+carrera_ficha(ciencias_quimicas, quimica_biologica, 5).
+
+carrera_atributo(medicina, aptitud,   biologia, 1.0).
+carrera_atributo(medicina, habilidad, empatia,  1.0).
+carrera_atributo(medicina, habilidad, analisis, 0.5).
+carrera_atributo(medicina, interes,   salud,    1.0).
+carrera_atributo(medicina, interes,   cuidado,  0.5).
+
+carrera_atributo(enfermeria, aptitud,   biologia, 1.0).
+carrera_atributo(enfermeria, habilidad, empatia,  1.0).
+carrera_atributo(enfermeria, interes,   cuidado,  1.0).
+carrera_atributo(enfermeria, interes,   salud,    0.5).
+
+carrera_atributo(quimica_biologica, aptitud,   biologia,    1.0).
+carrera_atributo(quimica_biologica, habilidad, analisis,    1.0).
+carrera_atributo(quimica_biologica, interes,   laboratorio, 1.0).
+carrera_atributo(quimica_biologica, interes,   salud,       0.5).
+
+carrera_sede(medicina,          central, presencial).
+carrera_sede(enfermeria,        central, presencial).
+carrera_sede(enfermeria,        norte,   presencial).
+carrera_sede(quimica_biologica, central, presencial).
+
+carrera_nota_minima(medicina,          biologia, 70).
+carrera_nota_minima(medicina,          quimica,  65).
+carrera_nota_minima(enfermeria,        biologia, 55).
+carrera_nota_minima(quimica_biologica, quimica,  60).
+
+% Humanidades y ciencias sociales
+carrera_ficha(humanidades, psicologia, 5).
+carrera_ficha(ciencias_juridicas, derecho, 5).
+
+carrera_atributo(psicologia, aptitud,   lenguaje, 1.0).
+carrera_atributo(psicologia, aptitud,   biologia, 0.5).
+carrera_atributo(psicologia, habilidad, empatia,  1.0).
+carrera_atributo(psicologia, interes,   personas, 1.0).
+carrera_atributo(psicologia, interes,   salud,    0.5).
+
+carrera_atributo(derecho, aptitud,   lenguaje,      1.0).
+carrera_atributo(derecho, habilidad, argumentacion, 1.0).
+carrera_atributo(derecho, interes,   justicia,      1.0).
+carrera_atributo(derecho, interes,   personas,      0.5).
+
+carrera_sede(psicologia, central, presencial).
+carrera_sede(psicologia, central, virtual).
+carrera_sede(derecho,    central, presencial).
+carrera_sede(derecho,    norte,   presencial).
+carrera_sede(derecho,    central, semipresencial).
+
+carrera_nota_minima(psicologia, lenguaje, 55).
+carrera_nota_minima(derecho,    lenguaje, 60).
+
+% Arquitectura y diseño
+carrera_ficha(arquitectura, arquitectura,   5).
+carrera_ficha(arquitectura, diseno_grafico, 4).
+
+carrera_atributo(arquitectura, aptitud,   arte,         1.0).
+carrera_atributo(arquitectura, aptitud,   matematica,   0.5).
+carrera_atributo(arquitectura, habilidad, diseno,       1.0).
+carrera_atributo(arquitectura, habilidad, creatividad,  0.5).
+carrera_atributo(arquitectura, interes,   construccion, 1.0).
+
+carrera_atributo(diseno_grafico, aptitud,   arte,         1.0).
+carrera_atributo(diseno_grafico, habilidad, creatividad,  1.0).
+carrera_atributo(diseno_grafico, habilidad, diseno,       0.5).
+carrera_atributo(diseno_grafico, interes,   comunicacion, 1.0).
+carrera_atributo(diseno_grafico, interes,   tecnologia,   0.5).
+
+carrera_sede(arquitectura,   central, presencial).
+carrera_sede(diseno_grafico, central, presencial).
+carrera_sede(diseno_grafico, central, virtual).
+
+carrera_nota_minima(arquitectura, matematica, 55).
+
+% Ciencias económicas
+carrera_ficha(ciencias_economicas, economia,       5).
+carrera_ficha(ciencias_economicas, administracion, 4).
+
+carrera_atributo(economia, aptitud,   matematica, 1.0).
+carrera_atributo(economia, habilidad, analisis,   1.0).
+carrera_atributo(economia, interes,   empresas,   1.0).
+carrera_atributo(economia, interes,   personas,   0.5).
+
+carrera_atributo(administracion, aptitud,   lenguaje,     1.0).
+carrera_atributo(administracion, aptitud,   matematica,   0.5).
+carrera_atributo(administracion, habilidad, liderazgo,    1.0).
+carrera_atributo(administracion, interes,   empresas,     1.0).
+carrera_atributo(administracion, interes,   comunicacion, 0.5).
+
+carrera_sede(economia,       central, presencial).
+carrera_sede(economia,       central, virtual).
+carrera_sede(administracion, central, presencial).
+carrera_sede(administracion, norte,   presencial).
+carrera_sede(administracion, central, virtual).
+
+carrera_nota_minima(economia, matematica, 60).
+
+% ------------------------------------------------------------
+% carrera(Facultad, Carrera, Aptitud, Habilidad, Interes).
+% Vista plana de la ficha: una solucion por cada combinacion de
+% atributos (sin importar el peso). La usan el catalogo, el vocabulario
+% y el modelo hibrido.
+% ------------------------------------------------------------
+carrera(Fac, Carr, Apt, Hab, Int) :-
+    carrera_ficha(Fac, Carr, _),
+    carrera_atributo(Carr, aptitud, Apt, _),
+    carrera_atributo(Carr, habilidad, Hab, _),
+    carrera_atributo(Carr, interes, Int, _).
@@ -0,0 +1,145 @@
+package reporte
+
+import (
+	_ "embed"
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+
+	"github.com/go-pdf/fpdf"
+)
+
+//go:embed plantilla.html
+var plantillaHTML string
+
+var plantilla = template.Must(template.New("reporte").Funcs(template.FuncMap{
+	"termino": Termino,
+}).Parse(plantillaHTML))
+
+// Termino pasa un atomo de la base a texto legible
+// ("quimica_biologica" -> "Quimica biologica")
+func Termino(s string) string {
+	s = strings.ReplaceAll(s, "_", " ")
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
+
+// HTML escribe el reporte como pagina HTML
+func HTML(w io.Writer, d Datos) error {
+	return plantilla.Execute(w, d)
+}
+
+// colores de la marca (los mismos del HTML)
+var (
+	colorMarca = [3]int{79, 70, 229}
+	colorBarra = [3]int{34, 197, 94}
+	colorGris  = [3]int{107, 114, 128}
+)
+
+// PDF escribe el reporte con fpdf (Go puro, fuentes base en cp1252)
+func PDF(w io.Writer, d Datos) error {
+	pdf := fpdf.New("P", "mm", "A4", "")
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+	pdf.SetTitle("UniMatch - Reporte de orientacion vocacional", true)
+	pdf.SetAuthor("UniMatch", true)
+	pdf.SetMargins(15, 15, 15)
+	pdf.SetAutoPageBreak(true, 20)
+	pdf.AliasNbPages("")
+	ancho, _ := pdf.GetPageSize()
+	util := ancho - 30
+
+	pdf.SetFooterFunc(func() {
+		pdf.SetY(-15)
+		pdf.SetFont("Helvetica", "I", 8)
+		pdf.SetTextColor(colorGris[0], colorGris[1], colorGris[2])
+		pdf.CellFormat(0, 10, tr(fmt.Sprintf("UniMatch · base %s · pesos %s · página %d/{nb}", d.VersionKB, d.Pesos, pdf.PageNo())), "", 0, "C", false, 0, "")
+	})
+	pdf.AddPage()
+
+	// encabezado con la marca
+	pdf.SetFillColor(colorMarca[0], colorMarca[1], colorMarca[2])
+	pdf.Rect(0, 0, ancho, 30, "F")
+	pdf.SetTextColor(255, 255, 255)
+	pdf.SetFont("Helvetica", "B", 18)
+	pdf.SetXY(15, 8)
+	pdf.CellFormat(util, 9, tr("UniMatch · Reporte de orientación vocacional"), "", 1, "L", false, 0, "")
+	pdf.SetFont("Helvetica", "", 11)
+	sub := d.Fecha.Format("02/01/2006")
+	if d.Nombre != "" {
+		sub = d.Nombre + " · " + sub
+	}
+	pdf.CellFormat(util, 6, tr(sub), "", 1, "L", false, 0, "")
+	pdf.SetY(38)
+
+	titulo := func(s string) {
+		pdf.SetTextColor(colorMarca[0], colorMarca[1], colorMarca[2])
+		pdf.SetFont("Helvetica", "B", 14)
+		pdf.CellFormat(util, 8, tr(s), "B", 1, "L", false, 0, "")
+		pdf.Ln(2)
+		pdf.SetTextColor(31, 41, 55)
+	}
+	lista := func(a, b string) string {
+		if b == "" {
+			return Termino(a)
+		}
+		return Termino(a) + ", " + Termino(b)
+	}
+
+	titulo("Tu perfil")
+	for _, f := range [][2]string{
+		{"Aptitud", Termino(d.Perfil.Aptitud)},
+		{"Habilidades", lista(d.Perfil.Habilidad, d.Perfil.Habilidad2)},
+		{"Intereses", lista(d.Perfil.Interes, d.Perfil.Interes2)},
+	} {
+		pdf.SetFont("Helvetica", "B", 11)
+		pdf.CellFormat(35, 6, tr(f[0]), "", 0, "L", false, 0, "")
+		pdf.SetFont("Helvetica", "", 11)
+		pdf.CellFormat(util-35, 6, tr(f[1]), "", 1, "L", false, 0, "")
+	}
+	pdf.Ln(4)
+
+	titulo("Carreras sugeridas")
+	if len(d.Carreras) == 0 {
+		pdf.SetFont("Helvetica", "I", 11)
+		pdf.CellFormat(util, 6, tr("No se encontraron carreras para este perfil."), "", 1, "L", false, 0, "")
+	}
+	for _, ex := range d.Carreras {
+		pdf.SetFont("Helvetica", "B", 12)
+		pdf.CellFormat(util, 7, tr(fmt.Sprintf("%d. %s (%s)", ex.Posicion, Termino(ex.Carrera.Carrera), Termino(ex.Carrera.Facultad))), "", 1, "L", false, 0, "")
+
+		// barra de match
+		x, y := pdf.GetXY()
+		pdf.SetFillColor(229, 231, 235)
+		pdf.Rect(x, y+1, util*0.6, 3, "F")
+		pdf.SetFillColor(colorBarra[0], colorBarra[1], colorBarra[2])
+		pdf.Rect(x, y+1, util*0.6*ex.Carrera.Match/100, 3, "F")
+		pdf.SetXY(x+util*0.6+3, y)
+		pdf.SetFont("Helvetica", "", 10)
+		pdf.CellFormat(util*0.4-3, 5, tr(fmt.Sprintf("Coincidencia: %.1f%%", ex.Carrera.Match)), "", 1, "L", false, 0, "")
+
+		pdf.SetFont("Helvetica", "", 10)
+		pdf.MultiCell(util, 5, tr(fmt.Sprintf("La carrera busca aptitud en %s, habilidad de %s e interés por %s.",
+			Termino(ex.Aptitud), Termino(ex.Habilidad), Termino(ex.Interes))), "", "L", false)
+		if len(ex.Coincidencias) == 0 {
+			pdf.SetFont("Helvetica", "I", 10)
+			pdf.MultiCell(util, 5, tr("Ningún criterio coincidió directamente."), "", "L", false)
+		}
+		for _, c := range ex.Coincidencias {
+			pdf.SetFont("Helvetica", "B", 10)
+			pdf.CellFormat(25, 5, tr("  • "+c.Criterio), "", 0, "L", false, 0, "")
+			pdf.SetFont("Helvetica", "", 10)
+			pdf.MultiCell(util-25, 5, tr(c.Detalle), "", "L", false)
+		}
+		pdf.Ln(3)
+	}
+
+	pdf.Ln(2)
+	pdf.SetFont("Helvetica", "I", 9)
+	pdf.SetTextColor(colorGris[0], colorGris[1], colorGris[2])
+	pdf.MultiCell(util, 5, tr("Este reporte es orientativo y no reemplaza la conversación con un orientador."), "", "L", false)
+
+	return pdf.Output(w)
+}
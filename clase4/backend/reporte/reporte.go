@@ -0,0 +1,109 @@
+// Package reporte genera el informe imprimible (HTML y PDF) que explica
+// por que se sugirio cada carrera.
+package reporte
+
+import (
+	"time"
+
+	"unmatch/backend/inferencia"
+)
+
+// Coincidencia es un criterio del perfil que coincidio con la carrera
+type Coincidencia struct {
+	Criterio string // aptitud, habilidad, interes o cruce
+	Detalle  string
+}
+
+// Explicacion es una carrera del ranking con su desglose
+type Explicacion struct {
+	Posicion      int
+	Carrera       inferencia.CarreraRecomendada
+	Coincidencias []Coincidencia
+	// Perfil de la carrera: el atributo que coincidio o, si ninguno, el
+	// de mayor peso de la ficha
+	Aptitud, Habilidad, Interes string
+}
+
+// Datos es todo lo que muestra el reporte
+type Datos struct {
+	Nombre    string
+	Fecha     time.Time
+	VersionKB string
+	Pesos     string
+	Perfil    inferencia.PerfilCarrera
+	Carreras  []Explicacion
+}
+
+// Explicar arma el desglose de cada carrera del ranking a partir de su
+// ficha; los atributos afines (peso menor a 1) se marcan como tales.
+func Explicar(perfil inferencia.PerfilCarrera, fichas []inferencia.Ficha, ranking []inferencia.CarreraRecomendada) []Explicacion {
+	porClave := map[string]inferencia.Ficha{}
+	for _, f := range fichas {
+		porClave[f.Clave()] = f
+	}
+
+	out := []Explicacion{}
+	for i, c := range ranking {
+		f := porClave[c.Clave()]
+		apt, okApt := elegir(f.Aptitudes, perfil.Aptitud)
+		hab, okHab := elegir(f.Habilidades, perfil.Habilidad, perfil.Habilidad2)
+		in, okIn := elegir(f.Intereses, perfil.Interes, perfil.Interes2)
+		ex := Explicacion{Posicion: i + 1, Carrera: c, Aptitud: apt.Valor, Habilidad: hab.Valor, Interes: in.Valor, Coincidencias: []Coincidencia{}}
+		if okApt {
+			ex.Coincidencias = append(ex.Coincidencias, Coincidencia{"aptitud", "tu aptitud en " + apt.Valor + afin(apt)})
+		}
+		if okHab {
+			ex.Coincidencias = append(ex.Coincidencias, Coincidencia{"habilidad", "tu habilidad de " + hab.Valor + afin(hab)})
+		}
+		if okIn {
+			ex.Coincidencias = append(ex.Coincidencias, Coincidencia{"interes", "tu interes por " + in.Valor + afin(in)})
+		}
+		if perfil.IndicadoresFicha(f)[3] > 0 {
+			ex.Coincidencias = append(ex.Coincidencias, Coincidencia{"cruce", cruce(perfil, f)})
+		}
+		out = append(out, ex)
+	}
+	return out
+}
+
+// elegir devuelve el atributo de mayor peso entre los que coinciden con
+// los valores del perfil; si ninguno coincide, el de mayor peso.
+func elegir(atributos []inferencia.AtributoPonderado, valores ...string) (inferencia.AtributoPonderado, bool) {
+	mejor, coincide := inferencia.AtributoPonderado{}, false
+	for _, a := range atributos {
+		esta := false
+		for _, v := range valores {
+			if v != "" && v == a.Valor {
+				esta = true
+			}
+		}
+		switch {
+		case esta && (!coincide || a.Peso > mejor.Peso):
+			mejor, coincide = a, true
+		case !esta && !coincide && a.Peso > mejor.Peso:
+			mejor = a
+		}
+	}
+	return mejor, coincide
+}
+
+// afin aclara cuando el atributo no es central en la carrera
+func afin(a inferencia.AtributoPonderado) string {
+	if a.Peso < 1 {
+		return " (afin a la carrera)"
+	}
+	return ""
+}
+
+// cruce describe que par de valores produjo el punto extra
+func cruce(p inferencia.PerfilCarrera, f inferencia.Ficha) string {
+	switch {
+	case f.Peso(inferencia.AtributoInteres, p.Habilidad) > 0:
+		return "tu habilidad " + p.Habilidad + " es un interes de la carrera"
+	case f.Peso(inferencia.AtributoAptitud, p.Habilidad2) > 0:
+		return "tu habilidad " + p.Habilidad2 + " es una aptitud que pide la carrera"
+	case f.Peso(inferencia.AtributoHabilidad, p.Interes) > 0:
+		return "tu interes por " + p.Interes + " es una habilidad que desarrolla la carrera"
+	}
+	return "tu interes por " + p.Interes2 + " es una habilidad que desarrolla la carrera"
+}
@@ -16,12 +16,33 @@ const DefaultSoftmaxModelPath = "./weights/softmax_model.json"
 
 // SoftmaxRegression implements multinomial logistic regression (softmax).
 type SoftmaxRegression struct {
-	W         *mat.Dense    // (nFeatures x nClasses)
-	B         *mat.VecDense // (nClasses)
-	Lr        float64       // Learning Rate
-	NIter     int           // Number of iterations
-	RegLambda float64       // Regularization strength
-	LossHistory []float64   // Training loss per iteration
+	W           *mat.Dense    // (nFeatures x nClasses)
+	B           *mat.VecDense // (nClasses)
+	Lr          float64       // Learning Rate
+	NIter       int           // Number of iterations
+	RegLambda   float64       // Regularization strength
+	Seed        int64         // Seed for the initial weights (0 = pick one in Fit)
+	LossHistory []float64     // Training loss per iteration
+	// Optional metadata stored in the artifact (column names of X and y,
+	// names of the classes 0..K-1)
+	FeatureNames []string
+	Label        string
+	Classes      []string
+	// How missing inputs were filled at training time (see Imputation)
+	Imputation []Imputation
+	// Optional hook called by Fit on every iteration, after the training
+	// loss is recorded and before the weights are updated (not saved)
+	OnIteration func(iter int)
+}
+
+// Imputation is the value fitted on the training data to fill the
+// missing (NaN) values of a feature. If Indicator is set, the model
+// also has a 0/1 feature with that name, appended after the inputs.
+type Imputation struct {
+	Feature   string  `json:"feature"`
+	Strategy  string  `json:"strategy"`
+	Value     float64 `json:"value"`
+	Indicator string  `json:"indicator,omitempty"`
 }
 
 // NewSoftmaxRegression creates a new model with hyperparameters.
@@ -126,19 +147,8 @@ func (m *SoftmaxRegression) Fit(X *mat.Dense, y []int) {
 	// X = vector de entrada
 	// B = Vector de Bias
 
-	// initialize W and B
-	rand.Seed(time.Now().UnixNano())
+	m.initParams(nFeatures, nClasses)
 
-	if m.W == nil {
-		dataW := make([]float64, nFeatures*nClasses)
-		for i := range dataW {
-			dataW[i] = 0.01 * rand.NormFloat64()
-		}
-		m.W = mat.NewDense(nFeatures, nClasses, dataW)
-	}
-	if m.B == nil {
-		m.B = mat.NewVecDense(nClasses, nil)
-	}
 	// El objeto recibe K labels (puede recibir cualquier cantida )
 	// El objeto tambien recibe n features (cualquier cantidad)
 	// y = {bajo, medio, alto} -> {0,1,2}
@@ -162,74 +172,143 @@ func (m *SoftmaxRegression) Fit(X *mat.Dense, y []int) {
 		_, probs := m.forward(X) // probs: (n x K)
 
 		// Compute cross-entropy loss with optional L2 regularization
-		loss := 0.0
-		for i := 0; i < nSamples; i++ {
-			pRow := probs.RawRowView(i)
-			yRow := Y.RawRowView(i)
-			for k := 0; k < nClasses; k++ {
-				if yRow[k] == 1.0 {
-					p := pRow[k]
-					if p < 1e-15 {
-						p = 1e-15
-					}
-					loss -= math.Log(p)
-				}
-			}
+		m.LossHistory = append(m.LossHistory, m.loss(probs, y))
+		if m.OnIteration != nil {
+			m.OnIteration(iter)
 		}
-		loss /= float64(nSamples)
-
-		if m.RegLambda > 0 {
-			rowsW, colsW := m.W.Dims()
-			regSum := 0.0
-			for i := 0; i < rowsW; i++ {
-				row := m.W.RawRowView(i)
-				for k := 0; k < colsW; k++ {
-					regSum += row[k] * row[k]
-				}
-			}
-			loss += 0.5 * m.RegLambda * regSum
+
+		m.step(X, Y, probs)
+	}
+}
+
+// initParams sets up W (small random values from the model's own
+// source, so the same seed gives the same weights) and B, unless they
+// are already set. The seed used is kept in m.Seed.
+func (m *SoftmaxRegression) initParams(nFeatures, nClasses int) {
+	if m.Seed == 0 {
+		m.Seed = time.Now().UnixNano()
+	}
+	rng := rand.New(rand.NewSource(m.Seed))
+
+	if m.W == nil {
+		dataW := make([]float64, nFeatures*nClasses)
+		for i := range dataW {
+			dataW[i] = 0.01 * rng.NormFloat64()
 		}
-		m.LossHistory = append(m.LossHistory, loss)
+		m.W = mat.NewDense(nFeatures, nClasses, dataW)
+	}
+	if m.B == nil {
+		m.B = mat.NewVecDense(nClasses, nil)
+	}
+}
+
+// step does one gradient descent update of W and B on X, given the
+// one-hot labels Y and the current probabilities.
+func (m *SoftmaxRegression) step(X, Y, probs *mat.Dense) {
+	nSamples, nFeatures := X.Dims()
+	_, nClasses := Y.Dims()
 
-		// dScores = (probs - Y)/n
-		dScores := mat.NewDense(nSamples, nClasses, nil)
-		dScores.Sub(probs, Y)
-		dScores.Scale(1.0/float64(nSamples), dScores)
+	// dScores = (probs - Y)/n
+	dScores := mat.NewDense(nSamples, nClasses, nil)
+	dScores.Sub(probs, Y)
+	dScores.Scale(1.0/float64(nSamples), dScores)
 
-		// dW = X^T * dScores + lambda * W
-		var XT mat.Dense
-		XT.CloneFrom(X.T()) // (d x n)
+	// dW = X^T * dScores + lambda * W
+	var XT mat.Dense
+	XT.CloneFrom(X.T()) // (d x n)
 
-		dW := mat.NewDense(nFeatures, nClasses, nil)
-		dW.Mul(&XT, dScores) // (d x n)*(n x K) = (d x K)
+	dW := mat.NewDense(nFeatures, nClasses, nil)
+	dW.Mul(&XT, dScores) // (d x n)*(n x K) = (d x K)
 
-		if m.RegLambda > 0 {
-			var regW mat.Dense
-			regW.CloneFrom(m.W)
-			regW.Scale(m.RegLambda, &regW)
-			dW.Add(dW, &regW)
+	if m.RegLambda > 0 {
+		var regW mat.Dense
+		regW.CloneFrom(m.W)
+		regW.Scale(m.RegLambda, &regW)
+		dW.Add(dW, &regW)
+	}
+
+	// db = row-wise sum of dScores
+	dbData := make([]float64, nClasses)
+	for i := 0; i < nSamples; i++ {
+		row := dScores.RawRowView(i)
+		for k := 0; k < nClasses; k++ {
+			dbData[k] += row[k]
 		}
+	}
+	db := mat.NewVecDense(nClasses, dbData)
 
-		// db = row-wise sum of dScores
-		dbData := make([]float64, nClasses)
-		for i := 0; i < nSamples; i++ {
-			row := dScores.RawRowView(i)
-			for k := 0; k < nClasses; k++ {
-				dbData[k] += row[k]
-			}
+	// update W and B
+	// W = W - lr * dW
+	var scaledDW mat.Dense
+	scaledDW.Scale(m.Lr, dW)
+	m.W.Sub(m.W, &scaledDW)
+
+	var scaledDB mat.VecDense
+	scaledDB.ScaleVec(m.Lr, db)
+	m.B.SubVec(m.B, &scaledDB)
+}
+
+// PartialFit does one gradient descent step on a mini-batch and returns
+// its loss (before the update). nClasses is fixed by the caller, since a
+// batch may not have every class; every y must be below it. W and B are
+// initialized on the first call (see Fit). LossHistory is left to the
+// caller.
+func (m *SoftmaxRegression) PartialFit(X *mat.Dense, y []int, nClasses int) float64 {
+	nSamples, nFeatures := X.Dims()
+	if nSamples == 0 {
+		log.Fatal("PartialFit: X is empty")
+	}
+	if len(y) != nSamples {
+		log.Fatal("PartialFit: X and y have different number of samples")
+	}
+	for _, yi := range y {
+		if yi < 0 || yi >= nClasses {
+			log.Fatalf("PartialFit: label %d out of %d classes", yi, nClasses)
 		}
-		db := mat.NewVecDense(nClasses, dbData)
+	}
+	m.initParams(nFeatures, nClasses)
+
+	_, probs := m.forward(X)
+	loss := m.loss(probs, y)
+	m.step(X, oneHotDense(y, nSamples, nClasses), probs)
+	return loss
+}
 
-		// update W and B
-		// W = W - lr * dW
-		var scaledDW mat.Dense
-		scaledDW.Scale(m.Lr, dW)
-		m.W.Sub(m.W, &scaledDW)
+// loss is the mean cross-entropy of probs against y plus the L2 penalty.
+func (m *SoftmaxRegression) loss(probs *mat.Dense, y []int) float64 {
+	_, nClasses := probs.Dims()
+	loss := 0.0
+	for i, yi := range y {
+		p := 1e-15
+		if yi < nClasses {
+			p = math.Max(probs.At(i, yi), 1e-15)
+		}
+		loss -= math.Log(p)
+	}
+	loss /= float64(len(y))
+
+	if m.RegLambda > 0 {
+		rowsW, colsW := m.W.Dims()
+		regSum := 0.0
+		for i := 0; i < rowsW; i++ {
+			row := m.W.RawRowView(i)
+			for k := 0; k < colsW; k++ {
+				regSum += row[k] * row[k]
+			}
+		}
+		loss += 0.5 * m.RegLambda * regSum
+	}
+	return loss
+}
 
-		var scaledDB mat.VecDense
-		scaledDB.ScaleVec(m.Lr, db)
-		m.B.SubVec(m.B, &scaledDB)
+// Loss returns the training objective (cross-entropy plus L2 penalty)
+// of the current weights on X and y, e.g. on a validation set.
+func (m *SoftmaxRegression) Loss(X *mat.Dense, y []int) float64 {
+	if m.W == nil || m.B == nil {
+		log.Fatal("Loss: model not trained")
 	}
+	_, probs := m.forward(X)
+	return m.loss(probs, y)
 }
 
 // PredictProba returns an (n x K) matrix with probabilities.
@@ -287,6 +366,12 @@ type softmaxModelFile struct {
 	Lr        float64   `json:"lr"`
 	NIter     int       `json:"n_iter"`
 	RegLambda float64   `json:"reg_lambda"`
+	Seed      int64     `json:"seed,omitempty"`
+	Features  []string  `json:"features,omitempty"`
+	Label     string    `json:"label,omitempty"`
+	Classes   []string  `json:"classes,omitempty"`
+	// Imputation fitted at training time
+	Imputation []Imputation `json:"imputation,omitempty"`
 }
 
 // SaveToFile saves weights and biases to a JSON file.
@@ -308,13 +393,18 @@ func (m *SoftmaxRegression) SaveToFile(path string) error {
 	}
 
 	fileStruct := softmaxModelFile{
-		NFeatures: nFeatures,
-		NClasses:  nClasses,
-		W:         dataW,
-		B:         dataB,
-		Lr:        m.Lr,
-		NIter:     m.NIter,
-		RegLambda: m.RegLambda,
+		NFeatures:  nFeatures,
+		NClasses:   nClasses,
+		W:          dataW,
+		B:          dataB,
+		Lr:         m.Lr,
+		NIter:      m.NIter,
+		RegLambda:  m.RegLambda,
+		Seed:       m.Seed,
+		Features:   m.FeatureNames,
+		Label:      m.Label,
+		Classes:    m.Classes,
+		Imputation: m.Imputation,
 	}
 
 	bytes, err := json.MarshalIndent(fileStruct, "", "  ")
@@ -343,16 +433,27 @@ func LoadSoftmaxRegression(path string) (*SoftmaxRegression, error) {
 	if len(fileStruct.B) != fileStruct.NClasses {
 		return nil, fmt.Errorf("LoadSoftmaxRegression: B dimensions mismatch")
 	}
+	if len(fileStruct.Features) > 0 && len(fileStruct.Features) != fileStruct.NFeatures {
+		return nil, fmt.Errorf("LoadSoftmaxRegression: %d feature names for %d features", len(fileStruct.Features), fileStruct.NFeatures)
+	}
+	if len(fileStruct.Classes) > 0 && len(fileStruct.Classes) != fileStruct.NClasses {
+		return nil, fmt.Errorf("LoadSoftmaxRegression: %d class names for %d classes", len(fileStruct.Classes), fileStruct.NClasses)
+	}
 
 	W := mat.NewDense(fileStruct.NFeatures, fileStruct.NClasses, fileStruct.W)
 	B := mat.NewVecDense(fileStruct.NClasses, fileStruct.B)
 
 	model := &SoftmaxRegression{
-		W:         W,
-		B:         B,
-		Lr:        fileStruct.Lr,
-		NIter:     fileStruct.NIter,
-		RegLambda: fileStruct.RegLambda,
+		W:            W,
+		B:            B,
+		Lr:           fileStruct.Lr,
+		NIter:        fileStruct.NIter,
+		RegLambda:    fileStruct.RegLambda,
+		Seed:         fileStruct.Seed,
+		FeatureNames: fileStruct.Features,
+		Label:        fileStruct.Label,
+		Classes:      fileStruct.Classes,
+		Imputation:   fileStruct.Imputation,
 	}
 	return model, nil
 }
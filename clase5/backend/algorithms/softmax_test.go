@@ -0,0 +1,94 @@
+package algorithms
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// toyData are 3 classes in 2D, like Example
+func toyData() (*mat.Dense, []int) {
+	X := mat.NewDense(6, 2, []float64{
+		-1.0, -1.2,
+		-0.8, -0.9,
+		0.0, 1.0,
+		0.2, 0.8,
+		2.0, 2.1,
+		1.8, 1.9,
+	})
+	return X, []int{0, 0, 1, 1, 2, 2}
+}
+
+func fitWithSeed(seed int64) *SoftmaxRegression {
+	X, y := toyData()
+	m := NewSoftmaxRegression(0.1, 50, 1e-3)
+	m.Seed = seed
+	m.Fit(X, y)
+	return m
+}
+
+func TestFitSameSeed(t *testing.T) {
+	a, b := fitWithSeed(7), fitWithSeed(7)
+	if !mat.Equal(a.W, b.W) {
+		t.Errorf("same seed, different W:\n%v\n%v", mat.Formatted(a.W), mat.Formatted(b.W))
+	}
+	if !mat.Equal(a.B, b.B) {
+		t.Errorf("same seed, different B: %v %v", a.B.RawVector().Data, b.B.RawVector().Data)
+	}
+}
+
+func TestFitDifferentSeed(t *testing.T) {
+	if a, b := fitWithSeed(7), fitWithSeed(8); mat.Equal(a.W, b.W) {
+		t.Error("seeds 7 and 8 gave the same W")
+	}
+}
+
+func TestFitPicksSeed(t *testing.T) {
+	if m := fitWithSeed(0); m.Seed == 0 {
+		t.Error("Fit did not keep the seed it picked")
+	}
+}
+
+func TestSaveLoadRoundTrip(t *testing.T) {
+	m := fitWithSeed(42)
+	m.FeatureNames = []string{"x1", "x2"}
+	m.Label = "clase"
+	m.Classes = []string{"baja", "mediana", "alta"}
+	m.Imputation = []Imputation{
+		{Feature: "x1", Strategy: "media", Value: 0.25},
+		{Feature: "x2", Strategy: "constante", Value: 0, Indicator: "x2_faltante"},
+	}
+
+	path := filepath.Join(t.TempDir(), "model.json")
+	if err := m.SaveToFile(path); err != nil {
+		t.Fatal(err)
+	}
+	got, err := LoadSoftmaxRegression(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got.Seed != m.Seed {
+		t.Errorf("Seed = %d, want %d", got.Seed, m.Seed)
+	}
+	if !reflect.DeepEqual(got.FeatureNames, m.FeatureNames) {
+		t.Errorf("FeatureNames = %v, want %v", got.FeatureNames, m.FeatureNames)
+	}
+	if got.Label != m.Label {
+		t.Errorf("Label = %q, want %q", got.Label, m.Label)
+	}
+	if !reflect.DeepEqual(got.Classes, m.Classes) {
+		t.Errorf("Classes = %v, want %v", got.Classes, m.Classes)
+	}
+	if !reflect.DeepEqual(got.Imputation, m.Imputation) {
+		t.Errorf("Imputation = %+v, want %+v", got.Imputation, m.Imputation)
+	}
+	if !mat.Equal(got.W, m.W) || !mat.Equal(got.B, m.B) {
+		t.Error("W or B changed after SaveToFile/LoadSoftmaxRegression")
+	}
+	if got.Lr != m.Lr || got.NIter != m.NIter || got.RegLambda != m.RegLambda {
+		t.Errorf("hyperparameters = %v/%d/%v, want %v/%d/%v", got.Lr, got.NIter, got.RegLambda, m.Lr, m.NIter, m.RegLambda)
+	}
+}
@@ -2,109 +2,24 @@ package main
 
 import (
 	"bytes"
+	"crypto/sha256"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"os"
+	"sync"
 
 	"github.com/gofiber/fiber/v2"
-	"github.com/mndrix/golog"
 	"gonum.org/v1/gonum/mat"
 
 	"unmatch/backend/algorithms"
+	"unmatch/backend/dataset"
+	"unmatch/backend/entrenamiento"
+	"unmatch/backend/inferencia"
 )
 
-// estructura que abstrae el MedicamentoRecomendado
-type MedicamentoRecomendado struct {
-	Urgencia    string
-	Enfermedad  string
-	Cronica     string
-	Pecho       string
-	Respiracion string
-	Medicamento string
-	Match       float64
-}
-
-func recomendarMedicamentos(
-	m golog.Machine,
-	urgencia, enfermedad, cronica, pecho, respiracion string,
-) []MedicamentoRecomendado {
-
-	// Usamos la regla de inferencia, no directamente la base de hechos.
-	query := "recomendar_medicamento(Urg, Enf, Cron, Pecho, Resp, Med)."
-	solutions := m.ProveAll(query)
-
-	results := []MedicamentoRecomendado{}
-
-	for _, sol := range solutions {
-		urg := sol.ByName_("Urg").String()
-		enf := sol.ByName_("Enf").String()
-		cron := sol.ByName_("Cron").String()
-		pech := sol.ByName_("Pecho").String()
-		resp := sol.ByName_("Resp").String()
-		med := sol.ByName_("Med").String()
-
-		matchCount := 0
-
-		// 1: urgencia
-		if urg == urgencia {
-			matchCount++
-		}
-		// 2: enfermedad
-		if enf == enfermedad {
-			matchCount++
-		}
-		// 3: cronicidad
-		if cron == cronica {
-			matchCount++
-		}
-		// 4: pecho
-		if pech == pecho {
-			matchCount++
-		}
-		// 5: respiración
-		if resp == respiracion {
-			matchCount++
-		}
-
-		// 6: “peso extra” por combinación peligrosa (ejemplos)
-		// alta urgencia + problema respiratorio
-		if urgencia == "alta" && respiracion == "resp_si" {
-			matchCount++
-		}
-		// enfermedad pulmonar crónica + respiración comprometida
-		if (enfermedad == "asma" || enfermedad == "bronquitis" || enfermedad == "enfisema") &&
-			cronica == "cronica_si" && respiracion == "resp_si" {
-			matchCount++
-		}
-
-		// Tenemos 5 condiciones base + 2 extras posibles = divisor 7.0
-		matchPercent := float64(matchCount) / 7.0 * 100.0
-
-		results = append(results, MedicamentoRecomendado{
-			Urgencia:    urg,
-			Enfermedad:  enf,
-			Cronica:     cron,
-			Pecho:       pech,
-			Respiracion: resp,
-			Medicamento: med,
-			Match:       matchPercent,
-		})
-	}
-
-	return results
-}
-
-// Agregar numeros para recomendacion
-func cargarProlog(path string) string {
-	data, err := os.ReadFile(path)
-	if err != nil {
-		panic(err)
-	}
-	return string(data)
-}
-
 type PerfilEstudiante struct {
 	Aptitud    string `json:"aptitud"`
 	Habilidad  string `json:"habilidad"`
@@ -121,6 +36,11 @@ type CarreraRecomendada struct {
 
 type DiagnosticoRequest struct {
 	Texto string `json:"texto"`
+	// Opcionales: se cruzan con las clases de medicamentos de la base
+	Alergias             []string `json:"alergias"`
+	MedicamentosActuales []string `json:"medicamentos_actuales"`
+	RangoEdad            string   `json:"rango_edad"` // nino, adolescente, adulto, adulto_mayor
+	Embarazo             bool     `json:"embarazo"`
 }
 
 // ===== Tipos para el modelo Softmax =====
@@ -131,13 +51,38 @@ type SoftmaxTrainRequest struct {
 	Lr        float64     `json:"lr"`         // opcional, default 0.1
 	NIter     int         `json:"n_iter"`     // opcional, default 2000
 	RegLambda float64     `json:"reg_lambda"` // opcional, default 1e-3
+	Seed      int64       `json:"seed"`       // opcional, 0 = al azar (se devuelve)
+	// Nombres de las columnas de X (opcional); sin nombres /diagnostico
+	// solo sirve si X tiene las 12 columnas de bronco_dataset.csv
+	Features []string `json:"features"`
 }
 
 type SoftmaxPredictRequest struct {
 	X [][]float64 `json:"x"` // matriz nSamples x nFeatures
+	// Alternativa a X: un objeto {feature: valor} por muestra, con los
+	// nombres que guarda el modelo
+	Registros []map[string]float64 `json:"registros"`
 }
 
-var softmaxModel *algorithms.SoftmaxRegression
+// El modelo Softmax se reemplaza completo al entrenar o promover un
+// experimento; los handlers toman el actual al empezar (como la maquina
+// de inferencia, ver reglas.go).
+var (
+	softmaxMu    sync.RWMutex
+	softmaxModel *algorithms.SoftmaxRegression
+)
+
+func modeloActual() *algorithms.SoftmaxRegression {
+	softmaxMu.RLock()
+	defer softmaxMu.RUnlock()
+	return softmaxModel
+}
+
+func setModelo(m *algorithms.SoftmaxRegression) {
+	softmaxMu.Lock()
+	softmaxModel = m
+	softmaxMu.Unlock()
+}
 
 const softmaxModelPath = algorithms.DefaultSoftmaxModelPath
 
@@ -170,6 +115,22 @@ func denseTo2D(m *mat.Dense) [][]float64 {
 	return out
 }
 
+// respuestaErrorProlog traduce un error de consulta Prolog a HTTP:
+// 400 si un dato del paciente es invalido, 503 si se agoto el tiempo,
+// 422 si se excedieron soluciones o pasos.
+func respuestaErrorProlog(c *fiber.Ctx, err error) error {
+	status := fiber.StatusInternalServerError
+	switch {
+	case errors.Is(err, inferencia.ErrDatoInvalido):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, inferencia.ErrTiempoAgotado):
+		status = fiber.StatusServiceUnavailable
+	case errors.Is(err, inferencia.ErrDemasiadasSoluciones), errors.Is(err, inferencia.ErrDemasiadosPasos):
+		status = fiber.StatusUnprocessableEntity
+	}
+	return c.Status(status).JSON(fiber.Map{"error": "Error al consultar la base de conocimiento", "detalle": err.Error()})
+}
+
 // Esta funcion llama a HuggingFace
 func llamarHuggingFace(texto string) (interface{}, error) {
 	fmt.Print("HuggingFaceCall")
@@ -303,14 +264,16 @@ func main() {
 	})
 	// vecotres todo el proceso el proceso
 	// variables globales
-	programa := cargarProlog("./prolog/conocimiento.pl")
-	// Cargar las inferencias
-	m := golog.NewMachine()
-	m = m.Consult(programa)
+	// Cargar los hechos, las inferencias y las reglas del DSL
+	maquina, err := inferencia.CargarBase()
+	if err != nil {
+		panic(err)
+	}
+	setMaquina(maquina)
 
 	// Intentar cargar el modelo Softmax desde disco (si existe)
 	if model, err := algorithms.LoadSoftmaxRegression(softmaxModelPath); err == nil {
-		softmaxModel = model
+		setModelo(model)
 		fmt.Println("Modelo Softmax cargado desde", softmaxModelPath)
 	} else {
 		fmt.Println("Modelo Softmax no cargado (aún). Entrénelo vía /softmax/train")
@@ -331,6 +294,9 @@ func main() {
 		if len(req.X) != len(req.Y) {
 			return c.Status(400).JSON(fiber.Map{"error": "X e y deben tener el mismo número de filas."})
 		}
+		if len(req.Features) > 0 && len(req.Features) != len(req.X[0]) {
+			return c.Status(400).JSON(fiber.Map{"error": fmt.Sprintf("features tiene %d nombres y X tiene %d columnas", len(req.Features), len(req.X[0]))})
+		}
 
 		lr := req.Lr
 		if lr == 0 {
@@ -351,57 +317,98 @@ func main() {
 		}
 
 		model := algorithms.NewSoftmaxRegression(lr, nIter, reg)
+		model.Seed = req.Seed
 		model.Fit(Xmat, req.Y)
+		if len(req.Features) > 0 {
+			model.FeatureNames = req.Features
+		}
 		acc := model.Accuracy(Xmat, req.Y)
 
-		softmaxModel = model
-		if err := model.SaveToFile(softmaxModelPath); err != nil {
-			fmt.Println("Error al guardar el modelo Softmax:", err)
+		setModelo(model)
+		datos, _ := json.Marshal(fiber.Map{"x": req.X, "y": req.Y})
+		d := &dataset.Dataset{Features: req.Features, X: Xmat, Y: req.Y, SHA256: fmt.Sprintf("%x", sha256.Sum256(datos))}
+		res := entrenamiento.ResultadoSinParticion(model, d)
+		id, err := registrarSoftmax(res, res.Manifiesto(softmaxModelPath, "POST /softmax/train", nil))
+		if err != nil {
+			return errorRegistro(c, id, model.Seed, err)
 		}
 
 		return c.JSON(fiber.Map{
-			"mensaje":  "Modelo Softmax entrenado",
-			"accuracy": acc,
+			"mensaje":     "Modelo Softmax entrenado",
+			"accuracy":    acc,
+			"seed":        model.Seed,
+			"experimento": id,
 		})
 	})
 
+	// Entrenar con un CSV y su esquema (ver softmax.go)
+	app.Post("/softmax/train/csv", entrenarSoftmaxCSV)
+
+	// Experimentos: cada entrenamiento queda registrado (ver experimentos.go)
+	app.Get("/experimentos", listarExperimentos)
+	app.Get("/experimentos/comparar", compararExperimentos)
+	app.Get("/experimentos/:id", obtenerExperimento)
+	app.Get("/experimentos/:id/archivos/:archivo", archivoExperimento)
+	app.Post("/experimentos/:id/promover", promoverExperimento)
+
 	// Usar el modelo Softmax entrenado para predecir
 	app.Post("/softmax/predict", func(c *fiber.Ctx) error {
 		var req SoftmaxPredictRequest
 		if err := c.BodyParser(&req); err != nil {
 			return c.Status(400).JSON(fiber.Map{"error": "Error de entrada."})
 		}
-		if len(req.X) == 0 {
-			return c.Status(400).JSON(fiber.Map{"error": "X es requerido."})
+		if len(req.X) == 0 && len(req.Registros) == 0 {
+			return c.Status(400).JSON(fiber.Map{"error": "X o registros es requerido."})
 		}
 
-		if softmaxModel == nil {
+		modelo := modeloActual()
+		if modelo == nil {
 			// intentar cargar desde disco por si se entrenó antes
 			if model, err := algorithms.LoadSoftmaxRegression(softmaxModelPath); err == nil {
-				softmaxModel = model
+				modelo = model
+				setModelo(model)
 			} else {
 				return c.Status(400).JSON(fiber.Map{"error": "Modelo no entrenado. Primero llame a /softmax/train."})
 			}
 		}
 
-		Xmat, err := slice2DToDense(req.X)
+		var Xmat *mat.Dense
+		var err error
+		if len(req.Registros) > 0 {
+			Xmat, err = matrizPorNombre(modelo, req.Registros)
+		} else {
+			Xmat, err = slice2DToDense(req.X)
+		}
 		if err != nil {
 			return c.Status(400).JSON(fiber.Map{"error": err.Error()})
 		}
+		nFeatures, _ := modelo.W.Dims()
+		if _, nCols := Xmat.Dims(); nCols != nFeatures {
+			return c.Status(400).JSON(fiber.Map{"error": fmt.Sprintf("el modelo espera %d features y X tiene %d", nFeatures, nCols)})
+		}
 
-		yPred := softmaxModel.Predict(Xmat)
-		probsMat := softmaxModel.PredictProba(Xmat)
+		yPred := modelo.Predict(Xmat)
+		probsMat := modelo.PredictProba(Xmat)
 		probs := denseTo2D(probsMat)
 
-		return c.JSON(fiber.Map{
+		resp := fiber.Map{
 			"y_pred": yPred,
 			"probs":  probs,
-		})
+		}
+		if clases := nombresClases(modelo, yPred); clases != nil {
+			resp["clases"] = clases
+		}
+		return c.JSON(resp)
 	})
 
+	// Reglas de contraindicacion en el DSL (YAML/JSON)
+	app.Get("/kb/reglas", obtenerReglasDSL)
+	app.Post("/kb/reglas", subirReglasDSL)
+
 	// Diagnostico de Texto Medico
 	// {"texto": "tengo tos y dificultad para respirar"}
 	app.Post("/diagnostico", func(c *fiber.Ctx) error {
+		m := maquinaActual()
 		var req DiagnosticoRequest
 		if err := c.BodyParser(&req); err != nil {
 			return c.Status(400).JSON(fiber.Map{"error": "Error de entrada."})
@@ -439,25 +446,35 @@ func main() {
 		// Convertimos el VectorEntrada a algo que pueda usar el modelo Softmax
 		// Aca ya tienen todos los datos recopilados en una estructura
 
-		// 1. Crear matriz Gonum con los datos del vector de entrada
-		Xdata := []float64{
-			float64(entrada.a_asma),
-			float64(entrada.a_bronquitis),
-			float64(entrada.a_enfisema),
-			float64(entrada.a_apnea),
-			float64(entrada.a_fibromialgia),
-			float64(entrada.a_migranas),
-			float64(entrada.a_reflujo),
-			float64(entrada.n_sintomas),
-			float64(entrada.n_cronicas), // Puede que de error el parseo
+		// 1. Crear matriz Gonum con los datos del vector de entrada, por
+		// nombre de feature para respetar el orden con el que se entreno
+		modelo := modeloActual()
+		if modelo == nil {
+			return c.Status(400).JSON(fiber.Map{"error": "Modelo no entrenado. Primero llame a /softmax/train."})
+		}
+		registro := map[string]float64{
+			"a_asma":              float64(entrada.a_asma),
+			"a_bronquitis":        float64(entrada.a_bronquitis),
+			"a_enfisema":          float64(entrada.a_enfisema),
+			"a_apnea":             float64(entrada.a_apnea),
+			"a_fibromialgia":      float64(entrada.a_fibromialgia),
+			"a_migranas":          float64(entrada.a_migranas),
+			"a_reflujo":           float64(entrada.a_reflujo),
+			"n_sintomas":          float64(entrada.n_sintomas),
+			"n_cronicas":          float64(entrada.n_cronicas),
+			"redflag_pecho":       boolAFloat(entrada.redflag_pecho),
+			"redflag_respiracion": boolAFloat(entrada.redflag_respiracion),
+			"tiene_cronicas":      boolAFloat(entrada.tiene_cronicas),
+		}
+		Xmat, err := matrizPorNombre(modelo, []map[string]float64{registro})
+		if err != nil {
+			// el modelo se entreno con otras columnas: no es un error del
+			// servidor sino del modelo cargado
+			return c.Status(409).JSON(fiber.Map{"error": "El modelo actual no usa las features de bronco. Reentrene con /softmax/train/csv o con 'features' en /softmax/train.", "detalle": err.Error()})
 		}
-		// aunque mapeamos a
-		Xmat := mat.NewDense(1, len(Xdata), Xdata)
 
 		// ingresamos al modelo Softmax
-		// Cargar el modelo
-		// 1. Crear la matriz Gonum con los datos del vector de entrada
-		inferencias_softmax := softmaxModel.Predict(Xmat)
+		inferencias_softmax := modelo.Predict(Xmat)
 		// luego adaptamos los resultados al esquema de entrada de Prolog
 		var urgencia, enfermedad, cronica, pecho, respiracion string
 		// usando la funcion recomendarMedicacion
@@ -471,7 +488,7 @@ func main() {
 			enfermedad = "ninguna"
 			cronica = "cronica_no"
 		case 1:
-			urgencia = "media"
+			urgencia = "mediana"
 			enfermedad = "asma"
 			cronica = "cronica_si"
 		case 2:
@@ -494,7 +511,8 @@ func main() {
 			respiracion = "resp_no"
 		}
 		// 2. Llamar a recomendarMedicacion
-		resultados := recomendarMedicamentos(
+		resultados, err := inferencia.RecomendarMedicamentos(
+			c.UserContext(),
 			m,
 			urgencia,
 			enfermedad,
@@ -502,6 +520,25 @@ func main() {
 			pecho,
 			respiracion,
 		)
+		if err != nil {
+			return respuestaErrorProlog(c, err)
+		}
+
+		// 3. Cruzar con alergias, medicacion actual, edad y embarazo
+		contraindicaciones, err := inferencia.ContraindicacionesPaciente(
+			c.UserContext(),
+			m,
+			resultados,
+			inferencia.DatosPaciente{
+				Alergias:             req.Alergias,
+				MedicamentosActuales: req.MedicamentosActuales,
+				RangoEdad:            req.RangoEdad,
+				Embarazo:             req.Embarazo,
+			},
+		)
+		if err != nil {
+			return respuestaErrorProlog(c, err)
+		}
 
 		// A partir del modelo de ML = urgencia del caso
 		// La descripcion cliente de sus padecimientos
@@ -514,7 +551,8 @@ func main() {
 		// si respiratorio se meta a un excel e ingrese los datos
 		// inicie un meet instantaneo
 		return c.JSON(fiber.Map{
-			"resultado": respuesta,
+			"resultado":          respuesta,
+			"contraindicaciones": contraindicaciones,
 		})
 	})
 
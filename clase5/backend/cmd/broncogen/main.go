@@ -0,0 +1,98 @@
+// broncogen genera datasets sinteticos de bronco con la especificacion
+// de algorithms/bronco_sintetico.yaml (distribucion de cada feature y
+// reglas de urgencia con ruido), para probar el entrenamiento y la API
+// con mas filas que bronco_dataset.csv.
+//
+// Uso (desde backend/):
+//
+//	go run ./cmd/broncogen --filas 10000 --seed 7 -o /tmp/bronco_10k.csv --esquema /tmp/bronco_10k.yaml
+//	go run ./cmd/softmaxctl train --data /tmp/bronco_10k.csv --esquema /tmp/bronco_10k.yaml
+//
+// La seed sale en stderr: con la misma especificacion, filas y seed el
+// CSV es el mismo. Sale con codigo 2 si la especificacion no es valida.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"unmatch/backend/sintetico"
+)
+
+func fallar(err error) {
+	fmt.Fprintln(os.Stderr, "error:", err)
+	os.Exit(2)
+}
+
+func main() {
+	specPath := flag.String("spec", sintetico.DefaultSpecPath, "especificacion YAML/JSON del dataset")
+	filas := flag.Int("filas", 0, "filas a generar (por defecto las de la especificacion)")
+	seed := flag.Int64("seed", 0, "seed (por defecto la de la especificacion; si no trae, al azar)")
+	ruido := flag.Float64("ruido", -1, "probabilidad de cambiar la etiqueta (por defecto la de la especificacion)")
+	salida := flag.String("o", "", "CSV de salida (por defecto stdout)")
+	esquemaPath := flag.String("esquema", "", "donde escribir el esquema del CSV para softmaxctl (opcional)")
+	flag.Parse()
+
+	spec, err := sintetico.CargarSpec(*specPath)
+	if err != nil {
+		fallar(err)
+	}
+	if *filas > 0 {
+		spec.Filas = *filas
+	}
+	if *ruido >= 0 {
+		spec.Ruido = *ruido
+	}
+	if *seed != 0 {
+		spec.Seed = *seed
+	}
+	if spec.Seed == 0 {
+		spec.Seed = time.Now().UnixNano()
+	}
+
+	d, err := sintetico.Generar(spec)
+	if err != nil {
+		fallar(err)
+	}
+
+	var w io.Writer = os.Stdout
+	if *salida != "" {
+		if err := os.MkdirAll(filepath.Dir(*salida), 0o755); err != nil {
+			fallar(err)
+		}
+		f, err := os.Create(*salida)
+		if err != nil {
+			fallar(err)
+		}
+		defer f.Close()
+		w = f
+	}
+	if err := sintetico.EscribirCSV(w, d); err != nil {
+		fallar(err)
+	}
+
+	if *esquemaPath != "" {
+		data, err := yaml.Marshal(d.Esquema)
+		if err != nil {
+			fallar(err)
+		}
+		if err := os.WriteFile(*esquemaPath, data, 0o644); err != nil {
+			fallar(err)
+		}
+	}
+
+	conteo := make([]int, len(d.Clases))
+	for _, yi := range d.Y {
+		conteo[yi]++
+	}
+	fmt.Fprintf(os.Stderr, "%d filas, seed %d\n", d.Muestras(), spec.Seed)
+	for k, n := range conteo {
+		fmt.Fprintf(os.Stderr, "  %s: %d (%.1f%%)\n", d.Clases[k], n, float64(n)/float64(d.Muestras())*100)
+	}
+}
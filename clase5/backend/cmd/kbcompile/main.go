@@ -0,0 +1,80 @@
+// kbcompile traduce el DSL de reglas (YAML/JSON) a hechos Prolog
+// compatibles con conocimiento.pl, y de vuelta.
+//
+// Uso (desde backend/):
+//
+//	go run ./cmd/kbcompile compilar -o prolog/reglas_dsl.pl reglas.yaml
+//	go run ./cmd/kbcompile descompilar -o reglas.yaml prolog/conocimiento.pl
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"unmatch/backend/inferencia"
+)
+
+func uso() {
+	fmt.Fprintln(os.Stderr, "uso: kbcompile compilar|descompilar [-o salida] archivo")
+	os.Exit(2)
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		uso()
+	}
+
+	fs := flag.NewFlagSet(os.Args[1], flag.ExitOnError)
+	salida := fs.String("o", "", "archivo de salida (por defecto stdout)")
+	fs.Parse(os.Args[2:])
+	if fs.NArg() != 1 {
+		uso()
+	}
+	entrada := fs.Arg(0)
+
+	data, err := os.ReadFile(entrada)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		os.Exit(2)
+	}
+
+	var out []byte
+	switch os.Args[1] {
+	case "compilar":
+		dsl, err := inferencia.LeerDSL(data)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "error:", err)
+			os.Exit(1)
+		}
+		programa, err := inferencia.CompilarDSL(dsl, filepath.Base(entrada))
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "error:", err)
+			os.Exit(1)
+		}
+		out = []byte(programa)
+	case "descompilar":
+		clausulas, err := inferencia.LeerClausulas(string(data))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %s: %v\n", entrada, err)
+			os.Exit(1)
+		}
+		out, err = inferencia.EscribirDSL(inferencia.DescompilarKB(clausulas))
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "error:", err)
+			os.Exit(1)
+		}
+	default:
+		uso()
+	}
+
+	if *salida == "" {
+		os.Stdout.Write(out)
+		return
+	}
+	if err := os.WriteFile(*salida, out, 0o644); err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		os.Exit(2)
+	}
+}
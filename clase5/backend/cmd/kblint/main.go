@@ -0,0 +1,94 @@
+// kblint revisa la base de conocimiento: hechos duplicados, atomos fuera
+// del vocabulario declarado, aridades inconsistentes, reglas que llaman a
+// predicados no definidos y hechos que violan las reglas "nunca" de
+// prolog/kblint.yaml.
+//
+// Uso (desde backend/, por ejemplo en un hook de pre-commit):
+//
+//	go run ./cmd/kblint -formato json prolog/conocimiento.pl prolog/inferencias.pl
+//
+// Sale con codigo 1 si hay errores (o avisos con -estricto) y 2 si no pudo
+// leer los archivos.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"unmatch/backend/inferencia"
+)
+
+func main() {
+	configPath := flag.String("config", inferencia.DefaultLintConfigPath, "vocabulario y reglas nunca (YAML)")
+	formato := flag.String("formato", "texto", "formato de salida: texto o json")
+	estricto := flag.Bool("estricto", false, "tratar los avisos como errores")
+	flag.Parse()
+
+	paths := flag.Args()
+	if len(paths) == 0 {
+		paths = []string{inferencia.DefaultConocimientoPath, inferencia.DefaultInferenciasPath}
+	}
+
+	cfg, err := inferencia.CargarConfigLint(*configPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		os.Exit(2)
+	}
+
+	archivos := []inferencia.ArchivoKB{}
+	for _, p := range paths {
+		programa, err := inferencia.CargarProlog(p)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "error:", err)
+			os.Exit(2)
+		}
+		clausulas, err := inferencia.LeerClausulas(programa)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %s: %v\n", p, err)
+			os.Exit(2)
+		}
+		archivos = append(archivos, inferencia.ArchivoKB{Path: p, Clausulas: clausulas})
+	}
+
+	hallazgos, err := inferencia.Lint(archivos, cfg)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		os.Exit(2)
+	}
+
+	errores, avisos := 0, 0
+	for _, h := range hallazgos {
+		if h.Severidad == inferencia.SeveridadError {
+			errores++
+		} else {
+			avisos++
+		}
+	}
+
+	switch *formato {
+	case "json":
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(reporte{Errores: errores, Avisos: avisos, Hallazgos: hallazgos}); err != nil {
+			fmt.Fprintln(os.Stderr, "error:", err)
+			os.Exit(2)
+		}
+	default:
+		for _, h := range hallazgos {
+			fmt.Println(h)
+		}
+		fmt.Printf("%d errores, %d avisos\n", errores, avisos)
+	}
+
+	if errores > 0 || (*estricto && avisos > 0) {
+		os.Exit(1)
+	}
+}
+
+type reporte struct {
+	Errores   int                   `json:"errores"`
+	Avisos    int                   `json:"avisos"`
+	Hallazgos []inferencia.Hallazgo `json:"hallazgos"`
+}
@@ -0,0 +1,263 @@
+// kbtest corre fixtures YAML contra la base de conocimiento real usando
+// el mismo camino que la API (inferencia.RecomendarMedicamentos e
+// inferencia.ContraindicacionesPaciente) y reporta que hechos de
+// medicamento_contraindicado/6, alergia_clase/2, interaccion/3 y
+// contraindicado_en_estado/3 no ejercita ningun caso.
+//
+// Uso (desde backend/):
+//
+//	go run ./cmd/kbtest -fixtures ./prolog/fixtures
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/mndrix/golog"
+	"gopkg.in/yaml.v3"
+
+	"unmatch/backend/inferencia"
+)
+
+// Paciente son los atributos que la API manda a Prolog
+type Paciente struct {
+	Urgencia    string `yaml:"urgencia"`
+	Enfermedad  string `yaml:"enfermedad"`
+	Cronica     string `yaml:"cronica"`
+	Pecho       string `yaml:"pecho"`
+	Respiracion string `yaml:"respiracion"`
+
+	// datos opcionales, como en /diagnostico
+	Alergias             []string `yaml:"alergias"`
+	MedicamentosActuales []string `yaml:"medicamentos_actuales"`
+	RangoEdad            string   `yaml:"rango_edad"`
+	Embarazo             bool     `yaml:"embarazo"`
+}
+
+// Caso es un fixture: paciente -> medicamentos contraindicados esperados.
+// Si MinMatch es 0 se toman los medicamentos con el mejor puntaje.
+// Contraindicados son los que descartan los datos opcionales (alergias,
+// medicacion actual, rango de edad y embarazo); un caso sin enfermedad
+// solo controla esos.
+type Caso struct {
+	Nombre          string   `yaml:"nombre"`
+	Paciente        Paciente `yaml:"paciente"`
+	Esperados       []string `yaml:"esperados"`
+	MinMatch        float64  `yaml:"min_match"`
+	Contraindicados []string `yaml:"contraindicados"`
+}
+
+// datos devuelve los datos opcionales del paciente
+func (p Paciente) datos() inferencia.DatosPaciente {
+	return inferencia.DatosPaciente{
+		Alergias:             p.Alergias,
+		MedicamentosActuales: p.MedicamentosActuales,
+		RangoEdad:            p.RangoEdad,
+		Embarazo:             p.Embarazo,
+	}
+}
+
+type archivoFixtures struct {
+	Casos []Caso `yaml:"casos"`
+}
+
+func main() {
+	kbPath := flag.String("kb", inferencia.DefaultConocimientoPath, "archivo de hechos .pl")
+	reglasPath := flag.String("reglas", inferencia.DefaultInferenciasPath, "archivo de reglas .pl")
+	fixtures := flag.String("fixtures", "./prolog/fixtures", "archivo .yaml o carpeta con fixtures")
+	flag.Parse()
+
+	m, err := inferencia.CargarMaquina(*kbPath, *reglasPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		os.Exit(2)
+	}
+
+	casos, err := cargarFixtures(*fixtures)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		os.Exit(2)
+	}
+	if len(casos) == 0 {
+		fmt.Fprintln(os.Stderr, "error: no se encontraron casos en", *fixtures)
+		os.Exit(2)
+	}
+
+	ejercitados := map[string]bool{}
+	fallos := 0
+
+	for _, caso := range casos {
+		problemas, err := correrCaso(m, caso, ejercitados)
+		if err != nil {
+			fallos++
+			fmt.Printf("FALLO %s\n      error: %v\n", caso.Nombre, err)
+			continue
+		}
+		if len(problemas) == 0 {
+			fmt.Printf("OK    %s\n", caso.Nombre)
+			continue
+		}
+		fallos++
+		fmt.Printf("FALLO %s\n", caso.Nombre)
+		for _, p := range problemas {
+			fmt.Printf("      %s\n", p)
+		}
+	}
+
+	// Cobertura: hechos que ningun caso llego a seleccionar
+	hechos, err := inferencia.HechosMedicamentos(context.Background(), m)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		os.Exit(2)
+	}
+	hechosPaciente, err := inferencia.HechosPaciente(context.Background(), m)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		os.Exit(2)
+	}
+	hechos = append(hechos, hechosPaciente...)
+	sinCubrir := []string{}
+	for _, h := range hechos {
+		if !ejercitados[h] {
+			sinCubrir = append(sinCubrir, h)
+		}
+	}
+
+	fmt.Println()
+	fmt.Printf("Casos: %d, fallos: %d\n", len(casos), fallos)
+	fmt.Printf("Cobertura: %d/%d hechos ejercitados\n", len(hechos)-len(sinCubrir), len(hechos))
+	for _, h := range sinCubrir {
+		fmt.Println("  sin cubrir:", h)
+	}
+
+	if fallos > 0 {
+		os.Exit(1)
+	}
+}
+
+// correrCaso compara lo que devuelve la base para caso con lo esperado,
+// marca en ejercitados los hechos que usa y devuelve las diferencias
+func correrCaso(m golog.Machine, caso Caso, ejercitados map[string]bool) ([]string, error) {
+	ctx := context.Background()
+	p := caso.Paciente
+	problemas := []string{}
+
+	if p.Enfermedad != "" {
+		resultados, err := inferencia.RecomendarMedicamentos(ctx, m, p.Urgencia, p.Enfermedad, p.Cronica, p.Pecho, p.Respiracion)
+		if err != nil {
+			return nil, err
+		}
+
+		umbral := caso.MinMatch
+		if umbral == 0 {
+			for _, r := range resultados {
+				if r.Match > umbral {
+					umbral = r.Match
+				}
+			}
+		}
+
+		obtenidos := map[string]bool{}
+		for _, r := range resultados {
+			if r.Match >= umbral && r.Match > 0 {
+				obtenidos[r.Medicamento] = true
+				ejercitados[r.Clave()] = true
+			}
+		}
+		problemas = append(problemas, comparar(fmt.Sprintf(" (umbral %.2f%%)", umbral), caso.Esperados, obtenidos)...)
+	}
+
+	datos := p.datos()
+	contras, err := inferencia.ContraindicacionesPaciente(ctx, m, nil, datos)
+	if err != nil {
+		return nil, err
+	}
+	obtenidos := map[string]bool{}
+	for _, c := range contras {
+		obtenidos[c.Medicamento] = true
+	}
+	problemas = append(problemas, comparar(" por datos del paciente", caso.Contraindicados, obtenidos)...)
+
+	usados, err := inferencia.HechosUsados(ctx, m, datos)
+	if err != nil {
+		return nil, err
+	}
+	for _, h := range usados {
+		ejercitados[h] = true
+	}
+	return problemas, nil
+}
+
+// comparar devuelve los medicamentos esperados que faltan y los obtenidos
+// que sobran, con sufijo en cada linea
+func comparar(sufijo string, esperados []string, obtenidos map[string]bool) []string {
+	quiero := map[string]bool{}
+	for _, med := range esperados {
+		quiero[med] = true
+	}
+	out := []string{}
+	if faltan := diferencia(quiero, obtenidos); len(faltan) > 0 {
+		out = append(out, fmt.Sprintf("faltan%s: %s", sufijo, strings.Join(faltan, ", ")))
+	}
+	if sobran := diferencia(obtenidos, quiero); len(sobran) > 0 {
+		out = append(out, fmt.Sprintf("sobran%s: %s", sufijo, strings.Join(sobran, ", ")))
+	}
+	return out
+}
+
+// cargarFixtures lee un archivo o todos los .yaml/.yml de una carpeta
+func cargarFixtures(path string) ([]Caso, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	archivos := []string{path}
+	if info.IsDir() {
+		archivos = nil
+		for _, patron := range []string{"*.yaml", "*.yml"} {
+			encontrados, err := filepath.Glob(filepath.Join(path, patron))
+			if err != nil {
+				return nil, err
+			}
+			archivos = append(archivos, encontrados...)
+		}
+		sort.Strings(archivos)
+	}
+
+	casos := []Caso{}
+	for _, archivo := range archivos {
+		data, err := os.ReadFile(archivo)
+		if err != nil {
+			return nil, err
+		}
+		var af archivoFixtures
+		if err := yaml.Unmarshal(data, &af); err != nil {
+			return nil, fmt.Errorf("%s: %w", archivo, err)
+		}
+		for i, c := range af.Casos {
+			if c.Nombre == "" {
+				c.Nombre = fmt.Sprintf("%s#%d", filepath.Base(archivo), i+1)
+			}
+			casos = append(casos, c)
+		}
+	}
+	return casos, nil
+}
+
+// diferencia devuelve, ordenados, los elementos de a que no estan en b
+func diferencia(a, b map[string]bool) []string {
+	out := []string{}
+	for k := range a {
+		if !b[k] {
+			out = append(out, k)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
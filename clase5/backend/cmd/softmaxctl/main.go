@@ -0,0 +1,605 @@
+// softmaxctl entrena y usa el modelo softmax de la API sin tocar codigo.
+//
+// Uso (desde backend/):
+//
+//	go run ./cmd/softmaxctl train --data algorithms/bronco_dataset.csv --esquema algorithms/bronco_esquema.yaml
+//	go run ./cmd/softmaxctl train --data grande.jsonl --esquema esquema.yaml --streaming --lote 512 --epocas 5
+//	go run ./cmd/softmaxctl validate --data algorithms/bronco_dataset.csv --esquema algorithms/bronco_esquema.yaml
+//	go run ./cmd/softmaxctl eval --data algorithms/bronco_dataset.csv --min-accuracy 0.8 --confusion confusion.svg
+//	go run ./cmd/softmaxctl eval --data algorithms/bronco_dataset.csv --predicciones predicciones.parquet
+//	go run ./cmd/softmaxctl predict --data nuevos.csv -o predicciones.csv
+//	go run ./cmd/softmaxctl inspect
+//	go run ./cmd/softmaxctl export --formato csv -o pesos.csv
+//	go run ./cmd/softmaxctl runs list
+//	go run ./cmd/softmaxctl runs compare <id-a> <id-b>
+//	go run ./cmd/softmaxctl runs promote <id>
+//
+// Las columnas del CSV se leen con un esquema (--esquema, ver paquete
+// dataset); sin esquema son todas numericas. Cada train queda como un
+// experimento en --runs (ver paquete experimentos) y, salvo
+// --promover=false, se promueve al modelo de la API. train aparta filas
+// para validacion y prueba (--validacion, --prueba; estratificado con
+// --seed) y reporta las metricas de cada parte. Con --streaming lee el
+// CSV o JSONL de a lotes (--lote, --epocas, --buffer) sin cargarlo en
+// memoria; las partes se sortean fila por fila y no son estratificadas.
+//
+// Codigos de salida: 0 ok, 1 eval por debajo de --min-accuracy o validate
+// con errores, 2 error (uso, datos o modelo).
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"unmatch/backend/algorithms"
+	"unmatch/backend/dataset"
+	"unmatch/backend/entrenamiento"
+	"unmatch/backend/experimentos"
+	"unmatch/backend/graficos"
+)
+
+func uso() {
+	fmt.Fprintln(os.Stderr, "uso: softmaxctl train|validate|eval|predict|inspect|export [flags]")
+	fmt.Fprintln(os.Stderr, "     softmaxctl runs list|show|compare|promote [flags]")
+	fmt.Fprintln(os.Stderr, "     softmaxctl <subcomando> -h muestra los flags de cada uno")
+	os.Exit(2)
+}
+
+func fallar(err error) {
+	fmt.Fprintln(os.Stderr, "error:", err)
+	os.Exit(2)
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		uso()
+	}
+	args := os.Args[2:]
+	switch os.Args[1] {
+	case "train":
+		train(args)
+	case "validate":
+		validate(args)
+	case "eval":
+		eval(args)
+	case "predict":
+		predict(args)
+	case "inspect":
+		inspect(args)
+	case "export":
+		export(args)
+	case "runs":
+		runs(args)
+	default:
+		uso()
+	}
+}
+
+// flagsModelo agrega -model a un subcomando
+func flagsModelo(fs *flag.FlagSet) *string {
+	return fs.String("model", algorithms.DefaultSoftmaxModelPath, "modelo entrenado (JSON)")
+}
+
+func cargarModelo(path string) *algorithms.SoftmaxRegression {
+	model, err := algorithms.LoadSoftmaxRegression(path)
+	if err != nil {
+		fallar(err)
+	}
+	return model
+}
+
+// flagsDataset agrega --data, --esquema, --label, --delim e --imputar a
+// un subcomando
+type flagsDataset struct {
+	data, esquema, label, delim, imputar *string
+	imputarValor                         *float64
+}
+
+func nuevosFlagsDataset(fs *flag.FlagSet, dataPorDefecto, ayudaData string) flagsDataset {
+	return flagsDataset{
+		data:         fs.String("data", dataPorDefecto, ayudaData),
+		esquema:      fs.String("esquema", "", "esquema YAML/JSON de las columnas (sin esquema todas son numericas)"),
+		label:        fs.String("label", "", "columna de etiqueta (por defecto la del esquema o "+dataset.DefaultLabel+")"),
+		delim:        fs.String("delim", "", "delimitador del CSV (por defecto el del esquema o se detecta)"),
+		imputar:      fs.String("imputar", "", "estrategia para los faltantes de todas las features ("+strings.Join(dataset.Estrategias, ", ")+")"),
+		imputarValor: fs.Float64("imputar-valor", 0, "valor para --imputar constante o indicador"),
+	}
+}
+
+// leer carga el dataset con el esquema. Sin --esquema, si hay un modelo
+// entrenado (eval, predict) las columnas salen de sus features.
+func (f flagsDataset) leer(model *algorithms.SoftmaxRegression, etiquetaOpcional bool) *dataset.Dataset {
+	d, err := dataset.LeerCSV(f.path(), f.esquemaDe(model), etiquetaOpcional)
+	if err != nil {
+		fallar(err)
+	}
+	return d
+}
+
+// path devuelve --data, que es obligatorio
+func (f flagsDataset) path() string {
+	if *f.data == "" {
+		fmt.Fprintln(os.Stderr, "error: falta --data")
+		os.Exit(2)
+	}
+	return *f.data
+}
+
+// esquemaDe arma el esquema de los flags (ver leer)
+func (f flagsDataset) esquemaDe(model *algorithms.SoftmaxRegression) dataset.Esquema {
+	esq := dataset.EsquemaPorDefecto(dataset.DefaultLabel)
+	if model != nil {
+		if model.Label != "" {
+			esq.Etiqueta.Columna = model.Label
+		}
+		if len(model.FeatureNames) > 0 {
+			esq = dataset.EsquemaDesdeFeatures(esq.Etiqueta.Columna, entrenamiento.EntradaModelo(model))
+			esq.Etiqueta.Clases = model.Classes
+		}
+	}
+	if *f.esquema != "" {
+		e, err := dataset.CargarEsquema(*f.esquema)
+		if err != nil {
+			fallar(err)
+		}
+		esq = e
+	}
+	if *f.label != "" {
+		esq.Etiqueta.Columna = *f.label
+	}
+	if *f.delim != "" {
+		esq.Delimitador = *f.delim
+	}
+	if *f.imputar != "" {
+		esq.Imputacion.Imputacion = dataset.Imputacion{Estrategia: *f.imputar, Valor: *f.imputarValor}
+	}
+	return esq
+}
+
+// salida devuelve el archivo -o o stdout
+func salida(path string) (io.Writer, func()) {
+	if path == "" {
+		return os.Stdout, func() {}
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		fallar(err)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		fallar(err)
+	}
+	return f, func() {
+		if err := f.Close(); err != nil {
+			fallar(err)
+		}
+	}
+}
+
+func train(args []string) {
+	fs := flag.NewFlagSet("train", flag.ExitOnError)
+	fd := nuevosFlagsDataset(fs, dataset.DefaultDatasetPath, "dataset CSV con encabezado")
+	out := fs.String("o", algorithms.DefaultSoftmaxModelPath, "donde promover el modelo (el que sirve la API)")
+	runs := fs.String("runs", experimentos.DefaultDir, "directorio de los experimentos")
+	nota := fs.String("nota", "", "descripcion del experimento")
+	promover := fs.Bool("promover", true, "copiar el modelo a -o (con --promover=false solo queda en el experimento)")
+	hp := entrenamiento.HiperparametrosPorDefecto
+	fs.Float64Var(&hp.Lr, "lr", hp.Lr, "tasa de aprendizaje")
+	fs.IntVar(&hp.NIter, "iter", hp.NIter, "iteraciones de descenso por gradiente")
+	fs.Float64Var(&hp.RegLambda, "lambda", hp.RegLambda, "regularizacion L2")
+	fs.Int64Var(&hp.Seed, "seed", 0, "seed de la particion y los pesos iniciales (0 = al azar, queda en el manifiesto)")
+	prop := entrenamiento.ProporcionesPorDefecto
+	fs.Float64Var(&prop.Validacion, "validacion", prop.Validacion, "proporcion de filas para validacion")
+	fs.Float64Var(&prop.Prueba, "prueba", prop.Prueba, "proporcion de filas para prueba")
+	perdida := fs.String("perdida", "", "CSV con la curva de perdida (iter, loss y loss_validacion)")
+	puntos := fs.String("puntos", "", "filas de entrenamiento con sus predicciones (.csv, .jsonl o .parquet)")
+	predicciones := fs.String("predicciones", "", "filas de prueba con sus predicciones (.csv, .jsonl o .parquet)")
+	formato := fs.String("graficos", graficos.FormatoSVG, "formato de los graficos que van al lado de los CSV y en el experimento (svg, png o vacio = sin graficos)")
+	streaming := fs.Bool("streaming", false, "entrenar de a lotes sin cargar el dataset en memoria (CSV o JSONL, ver --lote, --epocas y --buffer)")
+	op := entrenamiento.StreamingPorDefecto
+	fs.IntVar(&op.Lote, "lote", op.Lote, "filas por paso de gradiente con --streaming")
+	fs.IntVar(&op.Epocas, "epocas", op.Epocas, "pasadas por los datos con --streaming (reemplaza a --iter)")
+	fs.IntVar(&op.Buffer, "buffer", op.Buffer, "filas del buffer de mezcla con --streaming")
+	fs.Parse(args)
+	if *formato != "" {
+		if err := entrenamiento.ValidarFormatoGrafico(*formato); err != nil {
+			fallar(err)
+		}
+	}
+	if err := prop.Validar(); err != nil {
+		fallar(err)
+	}
+	for _, path := range []string{*puntos, *predicciones} {
+		if path != "" {
+			if _, err := entrenamiento.FormatoPredicciones(path); err != nil {
+				fallar(err)
+			}
+		}
+	}
+
+	if *streaming {
+		if err := op.Validar(); err != nil {
+			fallar(err)
+		}
+		if *puntos != "" || *predicciones != "" {
+			fallar(fmt.Errorf("--puntos y --predicciones necesitan el dataset en memoria, no van con --streaming"))
+		}
+	}
+
+	var res *entrenamiento.Resultado
+	if *streaming {
+		res = entrenarStreaming(fd, hp, op, prop)
+	} else {
+		res = entrenarEnMemoria(fd, hp, prop)
+	}
+	model := res.Modelo
+	fmt.Printf("Accuracy entrenamiento: %.4f\n", res.Metricas.Entrenamiento.Accuracy)
+	if met := res.Metricas.Validacion; met != nil {
+		fmt.Printf("Accuracy validacion:    %.4f (log loss %.4f)\n", met.Accuracy, met.LogLoss)
+	}
+	if met := res.Metricas.Prueba; met != nil {
+		fmt.Printf("Accuracy prueba:        %.4f (log loss %.4f)\n", met.Accuracy, met.LogLoss)
+	}
+	if n := len(model.LossHistory); n > 0 {
+		fmt.Printf("Perdida final: %.6f\n", model.LossHistory[n-1])
+	}
+
+	reg := experimentos.NuevoRegistro(*runs)
+	reg.Graficos = *formato
+	exp, err := reg.Guardar(res, res.Manifiesto(*out, *fd.data, os.Args), *nota)
+	if err != nil {
+		fallar(err)
+	}
+	fmt.Printf("Experimento %s en %s (seed %d)\n", exp.ID, filepath.Join(reg.Dir, exp.ID), model.Seed)
+	if *promover {
+		if _, err := reg.Promover(exp.ID, *out); err != nil {
+			fallar(err)
+		}
+		fmt.Println("Modelo promovido a", *out)
+	}
+
+	if *perdida != "" {
+		if err := entrenamiento.ExportarPerdidaCSV(*perdida, model.LossHistory, res.PerdidaValidacion); err != nil {
+			fallar(err)
+		}
+		fmt.Println("Curva de perdida en", *perdida)
+		if *formato != "" {
+			path := entrenamiento.RutaGrafico(*perdida, "", *formato)
+			if err := entrenamiento.GraficarPerdida(path, model.LossHistory, res.PerdidaValidacion); err != nil {
+				fallar(err)
+			}
+			fmt.Println("Grafico de la perdida en", path)
+		}
+	}
+	if *predicciones != "" {
+		if res.Prueba == nil {
+			fallar(fmt.Errorf("--predicciones necesita filas de prueba (--prueba > 0)"))
+		}
+		if err := entrenamiento.ExportarPredicciones(*predicciones, entrenamiento.NuevasPredicciones(model, res.Prueba.X, res.Prueba.Y)); err != nil {
+			fallar(err)
+		}
+		fmt.Println("Predicciones de prueba en", *predicciones)
+	}
+	if *puntos != "" {
+		train := res.Entrenamiento
+		if err := entrenamiento.ExportarPredicciones(*puntos, entrenamiento.NuevasPredicciones(model, train.X, train.Y)); err != nil {
+			fallar(err)
+		}
+		fmt.Println("Puntos en", *puntos)
+		if *formato != "" {
+			escritos, err := entrenamiento.GraficarPuntos(*puntos, *formato, model, train.X, train.Y)
+			if err != nil {
+				fallar(err)
+			}
+			for _, path := range escritos {
+				fmt.Println("Grafico en", path)
+			}
+		}
+	}
+}
+
+// entrenarEnMemoria lee todo el dataset y lo particiona estratificado
+func entrenarEnMemoria(fd flagsDataset, hp entrenamiento.Hiperparametros, prop dataset.Proporciones) *entrenamiento.Resultado {
+	d := fd.leer(nil, false)
+	rep := dataset.Revisar(d)
+	imprimirProblemas(rep)
+	if !rep.Ok() {
+		os.Exit(2)
+	}
+	res, err := entrenamiento.EntrenarParticionado(d, hp, prop)
+	if err != nil {
+		fallar(err)
+	}
+	fmt.Printf("Muestras: %d (entrenamiento %d, validacion %d, prueba %d), features: %d\n", d.Muestras(),
+		len(res.Particion.Entrenamiento), len(res.Particion.Validacion), len(res.Particion.Prueba), len(d.Features))
+	return res
+}
+
+// entrenarStreaming lee el dataset de a lotes (ver entrenamiento.EntrenarStreaming)
+func entrenarStreaming(fd flagsDataset, hp entrenamiento.Hiperparametros, op entrenamiento.OpcionesStreaming, prop dataset.Proporciones) *entrenamiento.Resultado {
+	f, err := dataset.AbrirFuente(fd.path(), fd.esquemaDe(nil))
+	if err != nil {
+		fallar(err)
+	}
+	defer f.Close()
+	// lo que Revisar dice de los faltantes, con lo contado al abrir
+	esq, errores := f.Esquema(), false
+	for _, feat := range f.Features() {
+		n := f.Resumen().Faltantes[feat]
+		switch {
+		case n == 0:
+		case esq.Imputacion.Para(feat).Estrategia != "":
+			fmt.Fprintf(os.Stderr, "aviso: %s: %d faltantes, se imputan con %s\n", feat, n, esq.Imputacion.Para(feat).Estrategia)
+		default:
+			fmt.Fprintf(os.Stderr, "error: %s: %d faltantes y el esquema no dice como imputarlos\n", feat, n)
+			errores = true
+		}
+	}
+	if errores {
+		os.Exit(2)
+	}
+	res, err := entrenamiento.EntrenarStreaming(f, hp, op, prop)
+	if err != nil {
+		fallar(err)
+	}
+	filas := func(met *entrenamiento.Metricas) int {
+		if met == nil {
+			return 0
+		}
+		return met.Muestras
+	}
+	fmt.Printf("Muestras: %d (entrenamiento %d, validacion %d, prueba %d), features: %d\n", f.Resumen().Muestras,
+		res.Metricas.Entrenamiento.Muestras, filas(res.Metricas.Validacion), filas(res.Metricas.Prueba), len(f.Features()))
+	fmt.Printf("De a lotes: %d epocas, lotes de %d filas, buffer de mezcla de %d\n", op.Epocas, op.Lote, op.Buffer)
+	return res
+}
+
+// imprimirProblemas muestra errores y avisos de la revision del dataset
+func imprimirProblemas(rep dataset.Reporte) {
+	for _, e := range rep.Errores {
+		fmt.Fprintln(os.Stderr, "error:", e)
+	}
+	for _, a := range rep.Avisos {
+		fmt.Fprintln(os.Stderr, "aviso:", a)
+	}
+}
+
+func validate(args []string) {
+	fs := flag.NewFlagSet("validate", flag.ExitOnError)
+	fd := nuevosFlagsDataset(fs, dataset.DefaultDatasetPath, "dataset CSV a revisar")
+	comoJSON := fs.Bool("json", false, "reporte en JSON")
+	estricto := fs.Bool("estricto", false, "sale con codigo 1 tambien si hay avisos")
+	fs.Parse(args)
+
+	rep := dataset.Revisar(fd.leer(nil, false))
+	if *comoJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(rep); err != nil {
+			fallar(err)
+		}
+	} else {
+		fmt.Printf("Muestras: %d, features: %d\n", rep.Muestras, len(rep.Features))
+		fmt.Printf("  %-22s %10s %10s %10s %5s %5s\n", "feature", "min", "max", "media", "nan", "inf")
+		for _, f := range rep.Features {
+			fmt.Printf("  %-22s %10.4g %10.4g %10.4g %5d %5d\n", f.Feature, f.Min, f.Max, f.Media, f.NaN, f.Inf)
+		}
+		fmt.Println("Etiqueta:")
+		for _, c := range rep.Etiquetas {
+			nombre := ""
+			if c.Nombre != "" {
+				nombre = " " + c.Nombre
+			}
+			fmt.Printf("  %d%s: %d filas (%.1f%%)\n", c.Clase, nombre, c.Filas, c.Porcentaje)
+		}
+		for _, g := range rep.Duplicadas {
+			fmt.Printf("Filas repetidas: %v\n", g)
+		}
+		imprimirProblemas(rep)
+	}
+
+	if !rep.Ok() || (*estricto && len(rep.Avisos) > 0) {
+		os.Exit(1)
+	}
+}
+
+func eval(args []string) {
+	fs := flag.NewFlagSet("eval", flag.ExitOnError)
+	modelPath := flagsModelo(fs)
+	fd := nuevosFlagsDataset(fs, dataset.DefaultDatasetPath, "dataset CSV con la columna de etiqueta")
+	minAcc := fs.Float64("min-accuracy", 0, "sale con codigo 1 si la accuracy es menor")
+	comoJSON := fs.Bool("json", false, "metricas en JSON")
+	confusion := fs.String("confusion", "", "grafico de la matriz de confusion (.svg o .png)")
+	predicciones := fs.String("predicciones", "", "cada fila con su prediccion (.csv, .jsonl o .parquet)")
+	fs.Parse(args)
+	if *predicciones != "" {
+		if _, err := entrenamiento.FormatoPredicciones(*predicciones); err != nil {
+			fallar(err)
+		}
+	}
+
+	model := cargarModelo(*modelPath)
+	d := fd.leer(model, false)
+	met, err := entrenamiento.Evaluar(model, d)
+	if err != nil {
+		fallar(err)
+	}
+	if *predicciones != "" {
+		if err := entrenamiento.ExportarPredicciones(*predicciones, entrenamiento.NuevasPredicciones(model, d.X, d.Y)); err != nil {
+			fallar(err)
+		}
+		fmt.Fprintln(os.Stderr, "Predicciones en", *predicciones)
+	}
+	if *confusion != "" {
+		if err := graficos.MapaConfusion(*confusion, met.Confusion, model.Classes); err != nil {
+			fallar(err)
+		}
+		fmt.Fprintln(os.Stderr, "Matriz de confusion en", *confusion)
+	}
+
+	if *comoJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(met); err != nil {
+			fallar(err)
+		}
+	} else {
+		fmt.Printf("Muestras: %d\n", met.Muestras)
+		fmt.Printf("Accuracy: %.4f\n", met.Accuracy)
+		fmt.Printf("Log loss: %.4f\n", met.LogLoss)
+		fmt.Println("Matriz de confusion (fila = real, columna = predicha):")
+		for c, fila := range met.Confusion {
+			fmt.Printf("  %d: %v\n", c, fila)
+		}
+		for _, mc := range met.PorClase {
+			fmt.Printf("  clase %d: precision %.3f, recall %.3f, f1 %.3f (%d filas)\n", mc.Clase, mc.Precision, mc.Recall, mc.F1, mc.Soporte)
+		}
+	}
+
+	if met.Accuracy < *minAcc {
+		fmt.Fprintf(os.Stderr, "accuracy %.4f menor a %.4f\n", met.Accuracy, *minAcc)
+		os.Exit(1)
+	}
+}
+
+func predict(args []string) {
+	fs := flag.NewFlagSet("predict", flag.ExitOnError)
+	modelPath := flagsModelo(fs)
+	fd := nuevosFlagsDataset(fs, "", "CSV con las features (la etiqueta, si esta, se ignora)")
+	out := fs.String("o", "", "CSV de salida (por defecto stdout)")
+	fs.Parse(args)
+
+	model := cargarModelo(*modelPath)
+	d := fd.leer(model, true)
+	if err := entrenamiento.Preparar(model, d); err != nil {
+		fallar(err)
+	}
+
+	w, cerrar := salida(*out)
+	if err := entrenamiento.EscribirPredicciones(w, model.PredictProba(d.X), model.Classes); err != nil {
+		fallar(err)
+	}
+	cerrar()
+}
+
+// pesoFeature es una feature con su coeficiente para una clase
+type pesoFeature struct {
+	Feature string  `json:"feature"`
+	Peso    float64 `json:"peso"`
+}
+
+func inspect(args []string) {
+	fs := flag.NewFlagSet("inspect", flag.ExitOnError)
+	modelPath := flagsModelo(fs)
+	top := fs.Int("top", 5, "features de mayor peso (absoluto) por clase")
+	comoJSON := fs.Bool("json", false, "resumen en JSON")
+	fs.Parse(args)
+
+	model := cargarModelo(*modelPath)
+	nFeatures, nClases := model.W.Dims()
+	bias := make([]float64, nClases)
+	principales := make([][]pesoFeature, nClases)
+	for k := 0; k < nClases; k++ {
+		bias[k] = model.B.AtVec(k)
+		pesos := []pesoFeature{}
+		for i := 0; i < nFeatures; i++ {
+			pesos = append(pesos, pesoFeature{entrenamiento.NombreFeature(model, i), model.W.At(i, k)})
+		}
+		sort.SliceStable(pesos, func(a, b int) bool { return math.Abs(pesos[a].Peso) > math.Abs(pesos[b].Peso) })
+		if *top > 0 && len(pesos) > *top {
+			pesos = pesos[:*top]
+		}
+		principales[k] = pesos
+	}
+
+	if *comoJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		err := enc.Encode(map[string]interface{}{
+			"n_features":  nFeatures,
+			"n_classes":   nClases,
+			"features":    model.FeatureNames,
+			"label":       model.Label,
+			"lr":          model.Lr,
+			"n_iter":      model.NIter,
+			"reg_lambda":  model.RegLambda,
+			"bias":        bias,
+			"principales": principales,
+		})
+		if err != nil {
+			fallar(err)
+		}
+		return
+	}
+
+	fmt.Println("Modelo:", *modelPath)
+	fmt.Printf("Features: %d, clases: %d", nFeatures, nClases)
+	if model.Label != "" {
+		fmt.Printf(", etiqueta: %s", model.Label)
+	}
+	fmt.Println()
+	fmt.Printf("Hiperparametros: lr=%g n_iter=%d reg_lambda=%g seed=%d\n", model.Lr, model.NIter, model.RegLambda, model.Seed)
+	for _, imp := range model.Imputation {
+		fmt.Printf("Imputacion: %s con %s = %g", imp.Feature, imp.Strategy, imp.Value)
+		if imp.Indicator != "" {
+			fmt.Printf(" (indicadora %s)", imp.Indicator)
+		}
+		fmt.Println()
+	}
+	for k := 0; k < nClases; k++ {
+		nombre := ""
+		if k < len(model.Classes) {
+			nombre = " " + model.Classes[k]
+		}
+		fmt.Printf("Clase %d%s (bias %+.4f):\n", k, nombre, bias[k])
+		for _, p := range principales[k] {
+			fmt.Printf("  %-22s %+.4f\n", p.Feature, p.Peso)
+		}
+	}
+}
+
+func export(args []string) {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	modelPath := flagsModelo(fs)
+	formato := fs.String("formato", "csv", "csv (tabla feature x clase) o json (matrices)")
+	out := fs.String("o", "", "archivo de salida (por defecto stdout)")
+	fs.Parse(args)
+	if *formato != "csv" && *formato != "json" {
+		fmt.Fprintf(os.Stderr, "error: formato '%s' no existe, use csv o json\n", *formato)
+		os.Exit(2)
+	}
+
+	model := cargarModelo(*modelPath)
+	w, cerrar := salida(*out)
+	if *formato == "csv" {
+		if err := entrenamiento.ExportarPesosCSV(w, model); err != nil {
+			fallar(err)
+		}
+		cerrar()
+		return
+	}
+
+	nFeatures, nClases := model.W.Dims()
+	features := make([]string, nFeatures)
+	matriz := make([][]float64, nFeatures)
+	for i := range matriz {
+		features[i] = entrenamiento.NombreFeature(model, i)
+		matriz[i] = append([]float64(nil), model.W.RawRowView(i)...)
+	}
+	b := make([]float64, nClases)
+	for k := range b {
+		b[k] = model.B.AtVec(k)
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(map[string]interface{}{"features": features, "label": model.Label, "classes": model.Classes, "w": matriz, "b": b}); err != nil {
+		fallar(err)
+	}
+	cerrar()
+}
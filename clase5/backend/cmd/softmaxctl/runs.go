@@ -0,0 +1,202 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"unmatch/backend/algorithms"
+	"unmatch/backend/entrenamiento"
+	"unmatch/backend/experimentos"
+)
+
+func usoRuns() {
+	fmt.Fprintln(os.Stderr, "uso: softmaxctl runs list [--json]")
+	fmt.Fprintln(os.Stderr, "     softmaxctl runs show <id> [--json]")
+	fmt.Fprintln(os.Stderr, "     softmaxctl runs compare <id-a> <id-b> [--json]")
+	fmt.Fprintln(os.Stderr, "     softmaxctl runs promote <id> [-o modelo]")
+	os.Exit(2)
+}
+
+func runs(args []string) {
+	if len(args) < 1 {
+		usoRuns()
+	}
+	fs := flag.NewFlagSet("runs "+args[0], flag.ExitOnError)
+	dir := fs.String("runs", experimentos.DefaultDir, "directorio de los experimentos")
+	comoJSON := fs.Bool("json", false, "salida en JSON")
+	out := fs.String("o", algorithms.DefaultSoftmaxModelPath, "modelo al que se promueve (promote)")
+	pos := parsearConPosicionales(fs, args[1:])
+	reg := experimentos.NuevoRegistro(*dir)
+
+	switch args[0] {
+	case "list":
+		exps, err := reg.Listar()
+		if err != nil {
+			fallar(err)
+		}
+		if *comoJSON {
+			imprimirJSON(exps)
+			return
+		}
+		if len(exps) == 0 {
+			fmt.Println("No hay experimentos en", reg.Dir)
+			return
+		}
+		fmt.Printf("  %-20s %-20s %9s %9s %-13s %12s %20s %s\n", "id", "fecha", "accuracy", "log_loss", "evaluado", "perdida", "seed", "nota")
+		for _, e := range exps {
+			marca := " "
+			if e.Promovido {
+				marca = "*"
+			}
+			fmt.Printf("%s %-20s %-20s %9.4f %9.4f %-13s %12.6f %20d %s\n", marca, e.ID, e.Fecha.Format("2006-01-02 15:04:05"),
+				e.Metricas.Accuracy, e.Metricas.LogLoss, e.Evaluado, e.PerdidaFinal, e.Params.Hiperparametros.Seed, e.Params.Nota)
+		}
+		fmt.Println("(* = promovido)")
+
+	case "show":
+		if len(pos) != 1 {
+			usoRuns()
+		}
+		exp, err := reg.Cargar(pos[0])
+		if err != nil {
+			fallar(err)
+		}
+		if *comoJSON {
+			imprimirJSON(exp)
+			return
+		}
+		imprimirExperimento(exp)
+
+	case "compare":
+		if len(pos) != 2 {
+			usoRuns()
+		}
+		a, err := reg.Cargar(pos[0])
+		if err != nil {
+			fallar(err)
+		}
+		b, err := reg.Cargar(pos[1])
+		if err != nil {
+			fallar(err)
+		}
+		cmp := experimentos.Comparar(a, b)
+		if *comoJSON {
+			imprimirJSON(cmp)
+			return
+		}
+		fmt.Printf("A: %s  B: %s\n", a.ID, b.ID)
+		if !cmp.MismoDataset {
+			fmt.Println("Ojo: los experimentos no usan el mismo dataset")
+		}
+		if a.Evaluado != b.Evaluado {
+			fmt.Printf("Ojo: las metricas de A son de %s y las de B de %s\n", a.Evaluado, b.Evaluado)
+		}
+		if len(cmp.Params) == 0 {
+			fmt.Println("Mismos parametros")
+		} else {
+			fmt.Println("Parametros que cambian:")
+			nombres := []string{}
+			for p := range cmp.Params {
+				nombres = append(nombres, p)
+			}
+			sort.Strings(nombres)
+			for _, p := range nombres {
+				fmt.Printf("  %-12s %s -> %s\n", p, recortar(cmp.Params[p][0]), recortar(cmp.Params[p][1]))
+			}
+		}
+		fmt.Printf("  %-16s %12s %12s %12s\n", "metrica", "A", "B", "B - A")
+		for _, m := range cmp.Metricas {
+			marca := ""
+			if m.Mejora {
+				marca = " mejor"
+			}
+			fmt.Printf("  %-16s %12.6f %12.6f %+12.6f%s\n", m.Metrica, m.A, m.B, m.Diferencia, marca)
+		}
+
+	case "promote":
+		if len(pos) != 1 {
+			usoRuns()
+		}
+		exp, err := reg.Promover(pos[0], *out)
+		if err != nil {
+			fallar(err)
+		}
+		fmt.Printf("Experimento %s promovido a %s (accuracy %.4f)\n", exp.ID, *out, exp.Metricas.Accuracy)
+
+	default:
+		usoRuns()
+	}
+}
+
+// parsearConPosicionales acepta los flags antes o despues de los
+// argumentos posicionales (runs show <id> --json)
+func parsearConPosicionales(fs *flag.FlagSet, args []string) []string {
+	pos := []string{}
+	for {
+		fs.Parse(args)
+		args = fs.Args()
+		if len(args) == 0 {
+			return pos
+		}
+		pos = append(pos, args[0])
+		args = args[1:]
+	}
+}
+
+func imprimirExperimento(e experimentos.Experimento) {
+	p := e.Params
+	fmt.Println("Experimento:", e.ID)
+	fmt.Println("Fecha:", e.Fecha.Format("2006-01-02 15:04:05 MST"))
+	if p.Nota != "" {
+		fmt.Println("Nota:", p.Nota)
+	}
+	if e.Promovido {
+		fmt.Println("Promovido: si")
+	}
+	fmt.Printf("Dataset: %s (%d muestras, sha256 %s)\n", p.Dataset, p.Muestras, p.SHA256)
+	fmt.Printf("Hiperparametros: lr=%g n_iter=%d reg_lambda=%g seed=%d\n",
+		p.Hiperparametros.Lr, p.Hiperparametros.NIter, p.Hiperparametros.RegLambda, p.Hiperparametros.Seed)
+	fmt.Printf("Features (%d): %s\n", len(p.Features), strings.Join(p.Features, ", "))
+	for _, imp := range p.Imputacion {
+		fmt.Printf("Imputacion: %s con %s = %g\n", imp.Feature, imp.Strategy, imp.Value)
+	}
+	fmt.Printf("Particion: validacion %g, prueba %g\n", p.Proporciones.Validacion, p.Proporciones.Prueba)
+	partes := []struct {
+		nombre string
+		met    *entrenamiento.Metricas
+	}{
+		{"entrenamiento", &e.Particion.Entrenamiento},
+		{"validacion", e.Particion.Validacion},
+		{"prueba", e.Particion.Prueba},
+	}
+	for _, parte := range partes {
+		if parte.met != nil {
+			fmt.Printf("  %-13s %6d filas, accuracy %.4f, log loss %.4f\n", parte.nombre, parte.met.Muestras, parte.met.Accuracy, parte.met.LogLoss)
+		}
+	}
+	fmt.Printf("Perdida final: %.6f\n", e.PerdidaFinal)
+	fmt.Printf("Matriz de confusion de %s (fila = real, columna = predicha):\n", e.Evaluado)
+	for c, fila := range e.Metricas.Confusion {
+		fmt.Printf("  %d: %v\n", c, fila)
+	}
+}
+
+func imprimirJSON(v interface{}) {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		fallar(err)
+	}
+}
+
+// recortar acorta valores largos (features, sha256) para la tabla
+func recortar(s string) string {
+	if len(s) > 60 {
+		return s[:57] + "..."
+	}
+	return s
+}
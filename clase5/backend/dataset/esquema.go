@@ -0,0 +1,333 @@
+// Package dataset lee datasets CSV a partir de un esquema que dice que
+// es cada columna (numerica, booleana, categorica, texto o ignorada) y
+// cual es la etiqueta. Lo comparten el entrenamiento, la API y
+// cmd/softmaxctl para que las features tengan siempre el mismo nombre.
+package dataset
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultDatasetPath es el dataset de bronco que acompaña al repo
+const DefaultDatasetPath = "./algorithms/bronco_dataset.csv"
+
+// DefaultLabel es la columna de etiqueta de bronco_dataset.csv
+const DefaultLabel = "urgencia"
+
+// ErrDatasetInvalido: el CSV no se puede usar para entrenar o predecir
+var ErrDatasetInvalido = errors.New("dataset invalido")
+
+// ErrEsquemaInvalido: el esquema tiene tipos o columnas que no tienen sentido
+var ErrEsquemaInvalido = errors.New("esquema invalido")
+
+// Tipos de columna
+const (
+	TipoNumerico   = "numerico"
+	TipoBooleano   = "booleano"
+	TipoCategorico = "categorico" // one-hot: una feature "columna=valor" por categoria
+	TipoTexto      = "texto"      // se conserva en Dataset.Textos, no es feature
+	TipoIgnorar    = "ignorar"
+)
+
+// Tipos son los tipos de columna validos
+var Tipos = []string{TipoNumerico, TipoBooleano, TipoCategorico, TipoTexto, TipoIgnorar}
+
+// FaltantesPorDefecto son los valores que se leen como dato faltante
+var FaltantesPorDefecto = []string{"", "na", "n/a", "nan", "null", "?"}
+
+// Columna describe una columna del CSV
+type Columna struct {
+	Nombre string `yaml:"nombre" json:"nombre"`
+	Tipo   string `yaml:"tipo" json:"tipo"`
+	// Categorias fija el orden de las features one-hot; un valor fuera de
+	// la lista es un error. Si esta vacia se toman las del CSV (ordenadas).
+	Categorias []string `yaml:"categorias,omitempty" json:"categorias,omitempty"`
+}
+
+// Etiqueta describe la columna a predecir
+type Etiqueta struct {
+	Columna string `yaml:"columna" json:"columna"`
+	// Clases nombra las clases 0..K-1; la columna puede traer el nombre o
+	// el indice.
+	Clases []string `yaml:"clases,omitempty" json:"clases,omitempty"`
+	// Mapa traduce valores del CSV a clases, p. ej. {urgente: 2}
+	Mapa map[string]int `yaml:"mapa,omitempty" json:"mapa,omitempty"`
+}
+
+// Estrategias de imputacion de datos faltantes
+const (
+	ImputarMedia     = "media"
+	ImputarMediana   = "mediana"
+	ImputarModa      = "moda" // valor mas frecuente
+	ImputarConstante = "constante"
+	ImputarIndicador = "indicador" // constante + columna indicadora
+)
+
+// Estrategias son las estrategias de imputacion validas
+var Estrategias = []string{ImputarMedia, ImputarMediana, ImputarModa, ImputarConstante, ImputarIndicador}
+
+// Imputacion dice como completar los faltantes de una feature
+type Imputacion struct {
+	Estrategia string  `yaml:"estrategia,omitempty" json:"estrategia,omitempty"`
+	Valor      float64 `yaml:"valor,omitempty" json:"valor,omitempty"` // para constante e indicador
+	// Indicador agrega la feature "<columna>_faltante" (1 si faltaba)
+	Indicador bool `yaml:"indicador,omitempty" json:"indicador,omitempty"`
+}
+
+// ConfigImputacion es la imputacion de todas las features numericas y
+// booleanas, con excepciones por columna. Sin estrategia los faltantes
+// son un error al entrenar.
+type ConfigImputacion struct {
+	Imputacion `yaml:",inline"`
+	Columnas   map[string]Imputacion `yaml:"columnas,omitempty" json:"columnas,omitempty"`
+}
+
+// Para devuelve la imputacion de la feature (la general si no tiene una propia)
+func (c ConfigImputacion) Para(feature string) Imputacion {
+	if imp, ok := c.Columnas[feature]; ok {
+		return imp
+	}
+	return c.Imputacion
+}
+
+// Esquema dice como leer un CSV. Las columnas que no aparecen en
+// Columnas son del tipo Resto (numerico si esta vacio).
+type Esquema struct {
+	Delimitador string           `yaml:"delimitador,omitempty" json:"delimitador,omitempty"` // "," ";" "tab"...; vacio = se detecta
+	Faltantes   []string         `yaml:"faltantes,omitempty" json:"faltantes,omitempty"`     // vacio = FaltantesPorDefecto
+	Etiqueta    Etiqueta         `yaml:"etiqueta" json:"etiqueta"`
+	Columnas    []Columna        `yaml:"columnas,omitempty" json:"columnas,omitempty"`
+	Resto       string           `yaml:"resto,omitempty" json:"resto,omitempty"`
+	Imputacion  ConfigImputacion `yaml:"imputacion,omitempty" json:"imputacion,omitempty"`
+}
+
+// EsquemaPorDefecto es el comportamiento de siempre: la etiqueta es
+// label (entero 0..K-1) y las demas columnas son numericas.
+func EsquemaPorDefecto(label string) Esquema {
+	return Esquema{Etiqueta: Etiqueta{Columna: label}}
+}
+
+// EsquemaDesdeFeatures reconstruye como leer un CSV para un modelo que
+// guarda sus features: las "columna=valor" salen de una columna
+// categorica, las demas son numericas y el resto de las columnas se
+// ignora.
+func EsquemaDesdeFeatures(label string, features []string) Esquema {
+	e := Esquema{Etiqueta: Etiqueta{Columna: label}, Resto: TipoIgnorar}
+	vistas := map[string]bool{}
+	for _, f := range features {
+		nombre, tipo := f, TipoNumerico
+		if col, _, ok := strings.Cut(f, "="); ok {
+			nombre, tipo = col, TipoCategorico
+		}
+		if !vistas[nombre] && nombre != label {
+			vistas[nombre] = true
+			e.Columnas = append(e.Columnas, Columna{Nombre: nombre, Tipo: tipo})
+		}
+	}
+	return e
+}
+
+// LeerEsquema parsea un esquema YAML (o JSON, que es YAML valido). Una
+// clave que no existe es un error, asi un campo mal escrito no se ignora.
+func LeerEsquema(data []byte) (Esquema, error) {
+	var e Esquema
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&e); err != nil && err != io.EOF {
+		return e, fmt.Errorf("%w: %v", ErrEsquemaInvalido, err)
+	}
+	return e, e.Validar()
+}
+
+// CargarEsquema lee y valida el esquema de un archivo
+func CargarEsquema(path string) (Esquema, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Esquema{}, err
+	}
+	e, err := LeerEsquema(data)
+	if err != nil {
+		return e, fmt.Errorf("%s: %w", path, err)
+	}
+	return e, nil
+}
+
+// Validar revisa tipos, nombres repetidos y la etiqueta
+func (e Esquema) Validar() error {
+	if strings.TrimSpace(e.Etiqueta.Columna) == "" {
+		return fmt.Errorf("%w: falta etiqueta.columna", ErrEsquemaInvalido)
+	}
+	if _, err := e.delimitador(); err != nil {
+		return err
+	}
+	if e.Resto != "" && !tipoValido(e.Resto) {
+		return fmt.Errorf("%w: resto '%s' no es un tipo (%s)", ErrEsquemaInvalido, e.Resto, strings.Join(Tipos, ", "))
+	}
+	vistas := map[string]bool{}
+	for _, c := range e.Columnas {
+		switch {
+		case strings.TrimSpace(c.Nombre) == "":
+			return fmt.Errorf("%w: hay una columna sin nombre", ErrEsquemaInvalido)
+		case vistas[c.Nombre]:
+			return fmt.Errorf("%w: la columna '%s' esta repetida", ErrEsquemaInvalido, c.Nombre)
+		case c.Nombre == e.Etiqueta.Columna:
+			return fmt.Errorf("%w: '%s' es la etiqueta, no se declara en columnas", ErrEsquemaInvalido, c.Nombre)
+		case !tipoValido(c.Tipo):
+			return fmt.Errorf("%w: columna '%s': tipo '%s' no existe (%s)", ErrEsquemaInvalido, c.Nombre, c.Tipo, strings.Join(Tipos, ", "))
+		case len(c.Categorias) > 0 && c.Tipo != TipoCategorico:
+			return fmt.Errorf("%w: columna '%s': solo las categoricas llevan categorias", ErrEsquemaInvalido, c.Nombre)
+		}
+		if repetida := repetido(c.Categorias); repetida != "" {
+			return fmt.Errorf("%w: columna '%s': la categoria '%s' esta repetida", ErrEsquemaInvalido, c.Nombre, repetida)
+		}
+		vistas[c.Nombre] = true
+	}
+	if repetida := repetido(e.Etiqueta.Clases); repetida != "" {
+		return fmt.Errorf("%w: la clase '%s' esta repetida", ErrEsquemaInvalido, repetida)
+	}
+	if err := validarImputacion("imputacion", e.Imputacion.Imputacion); err != nil {
+		return err
+	}
+	for col, imp := range e.Imputacion.Columnas {
+		if err := validarImputacion("imputacion.columnas."+col, imp); err != nil {
+			return err
+		}
+	}
+	for valor, k := range e.Etiqueta.Mapa {
+		if k < 0 || (len(e.Etiqueta.Clases) > 0 && k >= len(e.Etiqueta.Clases)) {
+			return fmt.Errorf("%w: etiqueta.mapa: '%s' va a la clase %d, que no existe", ErrEsquemaInvalido, valor, k)
+		}
+	}
+	return nil
+}
+
+// columna devuelve la definicion de la columna nombre (o la de Resto)
+func (e Esquema) columna(nombre string) Columna {
+	for _, c := range e.Columnas {
+		if c.Nombre == nombre {
+			return c
+		}
+	}
+	tipo := e.Resto
+	if tipo == "" {
+		tipo = TipoNumerico
+	}
+	return Columna{Nombre: nombre, Tipo: tipo}
+}
+
+// delimitador traduce Delimitador a una runa (0 = detectar)
+func (e Esquema) delimitador() (rune, error) {
+	switch d := e.Delimitador; d {
+	case "":
+		return 0, nil
+	case "tab", `\t`, "\t":
+		return '\t', nil
+	default:
+		r := []rune(d)
+		if len(r) != 1 || r[0] == '"' || r[0] == '\r' || r[0] == '\n' {
+			return 0, fmt.Errorf("%w: delimitador '%s' invalido, use un solo caracter", ErrEsquemaInvalido, d)
+		}
+		return r[0], nil
+	}
+}
+
+// esFaltante dice si el valor (ya sin espacios) es un dato faltante
+func (e Esquema) esFaltante(v string) bool {
+	faltantes := e.Faltantes
+	if len(faltantes) == 0 {
+		faltantes = FaltantesPorDefecto
+	}
+	for _, f := range faltantes {
+		if strings.EqualFold(v, f) {
+			return true
+		}
+	}
+	return false
+}
+
+// clase traduce el valor de la etiqueta a un indice de clase
+func (e Esquema) clase(v string) (int, error) {
+	if len(e.Etiqueta.Mapa) > 0 {
+		if k, ok := e.Etiqueta.Mapa[v]; ok {
+			return k, nil
+		}
+		validos := make([]string, 0, len(e.Etiqueta.Mapa))
+		for m := range e.Etiqueta.Mapa {
+			validos = append(validos, m)
+		}
+		sort.Strings(validos)
+		return 0, fmt.Errorf("etiqueta '%s' no esta en el mapa (%s)", v, strings.Join(validos, ", "))
+	}
+	for k, c := range e.Etiqueta.Clases {
+		if strings.EqualFold(v, c) {
+			return k, nil
+		}
+	}
+	k, err := strconv.Atoi(v)
+	if err != nil || k < 0 {
+		if len(e.Etiqueta.Clases) > 0 {
+			return 0, fmt.Errorf("etiqueta '%s' no es una clase (%s) ni un indice", v, strings.Join(e.Etiqueta.Clases, ", "))
+		}
+		return 0, fmt.Errorf("etiqueta '%s' no es un entero >= 0", v)
+	}
+	if len(e.Etiqueta.Clases) > 0 && k >= len(e.Etiqueta.Clases) {
+		return 0, fmt.Errorf("etiqueta %d fuera de las %d clases", k, len(e.Etiqueta.Clases))
+	}
+	return k, nil
+}
+
+// booleano interpreta los valores de una columna booleana
+func booleano(v string) (float64, bool) {
+	switch strings.ToLower(v) {
+	case "1", "true", "t", "si", "sí", "s", "yes", "y", "verdadero", "v":
+		return 1, true
+	case "0", "false", "f", "no", "n", "falso":
+		return 0, true
+	}
+	return 0, false
+}
+
+func validarImputacion(campo string, imp Imputacion) error {
+	if imp.Estrategia == "" {
+		if imp.Indicador {
+			return fmt.Errorf("%w: %s: indicador necesita una estrategia", ErrEsquemaInvalido, campo)
+		}
+		return nil
+	}
+	for _, e := range Estrategias {
+		if imp.Estrategia == e {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s: estrategia '%s' no existe (%s)", ErrEsquemaInvalido, campo, imp.Estrategia, strings.Join(Estrategias, ", "))
+}
+
+func tipoValido(t string) bool {
+	for _, v := range Tipos {
+		if t == v {
+			return true
+		}
+	}
+	return false
+}
+
+// repetido devuelve el primer valor repetido de la lista ("" si no hay)
+func repetido(lista []string) string {
+	vistos := map[string]bool{}
+	for _, v := range lista {
+		if vistos[v] {
+			return v
+		}
+		vistos[v] = true
+	}
+	return ""
+}
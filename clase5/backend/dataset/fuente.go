@@ -0,0 +1,453 @@
+package dataset
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// Lote es un bloque de filas leido de una Fuente
+type Lote struct {
+	X     *mat.Dense
+	Y     []int
+	Filas []int // numero de cada fila en el archivo (filas de datos, desde 0)
+}
+
+// Muestras devuelve la cantidad de filas del lote
+func (l *Lote) Muestras() int {
+	return len(l.Filas)
+}
+
+// Fuente entrega un dataset de a lotes, para entrenar con archivos que no
+// entran en memoria. Leer devuelve hasta n filas e io.EOF cuando termina
+// la pasada; Reiniciar vuelve al principio para otra.
+type Fuente interface {
+	Features() []string
+	Leer(n int) (*Lote, error)
+	Reiniciar() error
+}
+
+// Resumen es lo que se sabe de un archivo despues de recorrerlo una vez
+type Resumen struct {
+	Muestras  int            `json:"muestras"`
+	SHA256    string         `json:"sha256"`
+	Clases    int            `json:"clases"`    // etiqueta mas alta + 1
+	PorClase  []int          `json:"por_clase"` // filas de cada clase
+	Faltantes map[string]int `json:"faltantes"` // por columna
+}
+
+// Formatos de archivo que lee FuenteArchivo
+const (
+	FuenteCSV   = "csv"
+	FuenteJSONL = "jsonl"
+)
+
+// registros lee un archivo registro por registro (io.EOF al final)
+type registros interface {
+	leer() ([]string, error)
+}
+
+// FuenteArchivo lee un CSV o un JSONL (un objeto por linea) con un
+// esquema, de a lotes. La memoria que usa depende del tamano del lote y
+// no del archivo.
+type FuenteArchivo struct {
+	path    string
+	formato string
+	esq     Esquema
+	claves  []string // columnas del JSONL, en el orden en que aparecen
+	lector  *lector
+	resumen Resumen
+
+	archivo *os.File
+	regs    registros
+	fila    int
+}
+
+// AbrirFuente abre path segun su extension (.jsonl o .ndjson es JSON
+// Lines, lo demas CSV). Recorre el archivo dos veces antes de devolver la
+// fuente: una para las columnas, las categorias y el SHA-256 y otra para
+// validar cada fila y armar el Resumen; un error de datos sale aca y no
+// en medio del entrenamiento. En JSONL una clave que falta en una fila es
+// un dato faltante; los valores pueden ser numeros, textos, booleanos o
+// null.
+func AbrirFuente(path string, esq Esquema) (*FuenteArchivo, error) {
+	if err := esq.Validar(); err != nil {
+		return nil, err
+	}
+	f := &FuenteArchivo{path: path, formato: FuenteCSV, esq: esq}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".jsonl", ".ndjson":
+		f.formato = FuenteJSONL
+	}
+
+	if err := f.explorar(); err != nil {
+		f.Close()
+		return nil, err
+	}
+	if err := f.resumir(); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return f, f.Reiniciar()
+}
+
+// explorar es la primera pasada: encabezado, categorias y SHA-256
+func (f *FuenteArchivo) explorar() error {
+	suma := sha256.New()
+	if f.formato == FuenteJSONL {
+		// las columnas son todas las claves que aparecen
+		if err := f.abrir(suma); err != nil {
+			return err
+		}
+		vistas := map[string]bool{}
+		jr := f.regs.(*registrosJSONL)
+		for {
+			err := jr.siguiente()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return err
+			}
+			for _, c := range jr.leidas {
+				if !vistas[c] {
+					vistas[c] = true
+					f.claves = append(f.claves, c)
+				}
+			}
+		}
+		if len(f.claves) == 0 {
+			return fmt.Errorf("%w: %s no tiene filas de datos", ErrDatasetInvalido, f.path)
+		}
+		f.resumen.SHA256 = hex.EncodeToString(suma.Sum(nil))
+		suma = nil
+	}
+
+	encabezado, err := f.abrirConEncabezado(suma)
+	if err != nil {
+		return err
+	}
+	if f.lector, err = nuevoLector(encabezado, f.esq, f.path, false); err != nil {
+		return err
+	}
+	vistas := f.lector.nuevasVistas()
+	for {
+		row, err := f.regs.leer()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		f.lector.verCategorias(row, vistas)
+	}
+	if suma != nil {
+		f.resumen.SHA256 = hex.EncodeToString(suma.Sum(nil))
+	}
+	return f.lector.fijarCategorias(vistas)
+}
+
+// resumir es la segunda pasada: convierte cada fila y cuenta
+func (f *FuenteArchivo) resumir() error {
+	if err := f.Reiniciar(); err != nil {
+		return err
+	}
+	f.resumen.Faltantes = map[string]int{}
+	x := make([]float64, len(f.lector.features))
+	for {
+		row, err := f.regs.leer()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		k, err := f.lector.convertir(row, f.numeroFila(f.fila), x, f.resumen.Faltantes, nil)
+		if err != nil {
+			return err
+		}
+		f.fila++
+		for len(f.resumen.PorClase) <= k {
+			f.resumen.PorClase = append(f.resumen.PorClase, 0)
+		}
+		f.resumen.PorClase[k]++
+	}
+	if f.fila == 0 {
+		return fmt.Errorf("%w: %s no tiene filas de datos", ErrDatasetInvalido, f.path)
+	}
+	f.resumen.Muestras = f.fila
+	f.resumen.Clases = len(f.resumen.PorClase)
+	return nil
+}
+
+// numeroFila es el numero de fila del archivo para los errores (en CSV
+// el encabezado es la fila 1, en JSONL es la linea)
+func (f *FuenteArchivo) numeroFila(fila int) int {
+	if f.formato == FuenteJSONL {
+		return f.regs.(*registrosJSONL).linea
+	}
+	return fila + 2
+}
+
+// abrir (re)abre el archivo; si suma no es nil se le pasan los bytes leidos
+func (f *FuenteArchivo) abrir(suma hash.Hash) error {
+	if f.archivo != nil {
+		f.archivo.Close()
+	}
+	archivo, err := os.Open(f.path)
+	if err != nil {
+		return err
+	}
+	f.archivo, f.fila = archivo, 0
+	var r io.Reader = archivo
+	if suma != nil {
+		r = io.TeeReader(archivo, suma)
+	}
+	br := bufio.NewReaderSize(r, 64<<10)
+	if inicio, _ := br.Peek(3); bytes.Equal(inicio, []byte("\ufeff")) {
+		br.Discard(3)
+	}
+
+	if f.formato == FuenteJSONL {
+		f.regs = &registrosJSONL{r: br, nombre: f.path, claves: f.claves}
+		return nil
+	}
+	delim, _ := f.esq.delimitador()
+	if delim == 0 {
+		// el encabezado entra en el buffer
+		inicio, _ := br.Peek(br.Size())
+		delim = detectarDelimitador(inicio)
+	}
+	cr := csv.NewReader(br)
+	cr.Comma = delim
+	cr.FieldsPerRecord = -1
+	cr.ReuseRecord = true
+	f.regs = &registrosCSV{r: cr, nombre: f.path}
+	return nil
+}
+
+// abrirConEncabezado abre el archivo y devuelve las columnas
+func (f *FuenteArchivo) abrirConEncabezado(suma hash.Hash) ([]string, error) {
+	if err := f.abrir(suma); err != nil {
+		return nil, err
+	}
+	if f.formato == FuenteJSONL {
+		return f.claves, nil
+	}
+	encabezado, err := f.regs.leer()
+	if err == io.EOF {
+		return nil, fmt.Errorf("%w: %s esta vacio", ErrDatasetInvalido, f.path)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return append([]string(nil), encabezado...), nil
+}
+
+// Reiniciar vuelve a la primera fila
+func (f *FuenteArchivo) Reiniciar() error {
+	_, err := f.abrirConEncabezado(nil)
+	return err
+}
+
+// Leer devuelve las proximas n filas (menos al final del archivo) o
+// io.EOF si no quedan
+func (f *FuenteArchivo) Leer(n int) (*Lote, error) {
+	if n <= 0 {
+		return nil, fmt.Errorf("Leer: el lote debe tener al menos una fila (%d)", n)
+	}
+	x := make([]float64, 0, n*len(f.lector.features))
+	lote := &Lote{Y: make([]int, 0, n), Filas: make([]int, 0, n)}
+	fila := make([]float64, len(f.lector.features))
+	faltantes := map[string]int{}
+	for lote.Muestras() < n {
+		row, err := f.regs.leer()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		k, err := f.lector.convertir(row, f.numeroFila(f.fila), fila, faltantes, nil)
+		if err != nil {
+			return nil, err
+		}
+		x = append(x, fila...)
+		lote.Y = append(lote.Y, k)
+		lote.Filas = append(lote.Filas, f.fila)
+		f.fila++
+	}
+	if lote.Muestras() == 0 {
+		return nil, io.EOF
+	}
+	lote.X = mat.NewDense(lote.Muestras(), len(f.lector.features), x)
+	return lote, nil
+}
+
+// Close cierra el archivo
+func (f *FuenteArchivo) Close() error {
+	if f.archivo == nil {
+		return nil
+	}
+	err := f.archivo.Close()
+	f.archivo = nil
+	return err
+}
+
+// Features son las columnas de X, como las de Dataset.Features
+func (f *FuenteArchivo) Features() []string {
+	return f.lector.features
+}
+
+// Resumen devuelve lo contado al abrir el archivo
+func (f *FuenteArchivo) Resumen() Resumen {
+	return f.resumen
+}
+
+// Esquema es el esquema con el que se lee
+func (f *FuenteArchivo) Esquema() Esquema {
+	return f.esq
+}
+
+// Label es la columna de etiqueta
+func (f *FuenteArchivo) Label() string {
+	return f.esq.Etiqueta.Columna
+}
+
+// Clases son los nombres de las clases, si el esquema los da
+func (f *FuenteArchivo) Clases() []string {
+	return f.esq.Etiqueta.Clases
+}
+
+// Categorias son las categorias de cada columna categorica
+func (f *FuenteArchivo) Categorias() map[string][]string {
+	return f.lector.categorias
+}
+
+// registrosCSV lee registros de un csv.Reader
+type registrosCSV struct {
+	r      *csv.Reader
+	nombre string
+}
+
+func (c *registrosCSV) leer() ([]string, error) {
+	row, err := c.r.Read()
+	if err == io.EOF {
+		return nil, err
+	}
+	if err != nil {
+		var pe *csv.ParseError
+		if errors.As(err, &pe) {
+			return nil, fmt.Errorf("%w: %s: fila %d: %v", ErrDatasetInvalido, c.nombre, pe.Line, pe.Err)
+		}
+		return nil, fmt.Errorf("%w: %s: %v", ErrDatasetInvalido, c.nombre, err)
+	}
+	return row, nil
+}
+
+// registrosJSONL lee un objeto JSON por linea (las lineas vacias se
+// saltean) y lo pasa a un registro con las columnas claves
+type registrosJSONL struct {
+	r      *bufio.Reader
+	nombre string
+	claves []string
+	indice map[string]int
+	linea  int
+	// clave y valor (como texto) de cada campo del ultimo objeto
+	leidas  []string
+	valores []string
+}
+
+// siguiente lee el proximo objeto en leidas y valores
+func (j *registrosJSONL) siguiente() error {
+	for {
+		linea, err := j.r.ReadBytes('\n')
+		if len(linea) == 0 && err != nil {
+			if err == io.EOF {
+				return io.EOF
+			}
+			return err
+		}
+		j.linea++
+		if len(bytes.TrimSpace(linea)) == 0 {
+			continue
+		}
+		if err := j.objeto(linea); err != nil {
+			return fmt.Errorf("%w: %s: linea %d: %v", ErrDatasetInvalido, j.nombre, j.linea, err)
+		}
+		return nil
+	}
+}
+
+// objeto separa los campos de un objeto JSON, en su orden
+func (j *registrosJSONL) objeto(linea []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(linea))
+	dec.UseNumber()
+	if t, err := dec.Token(); err != nil || t != json.Delim('{') {
+		return fmt.Errorf("se esperaba un objeto JSON")
+	}
+	j.leidas, j.valores = j.leidas[:0], j.valores[:0]
+	for dec.More() {
+		t, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		clave := t.(string)
+		var v interface{}
+		if err := dec.Decode(&v); err != nil {
+			return err
+		}
+		texto := ""
+		switch v := v.(type) {
+		case nil:
+		case string:
+			texto = v
+		case json.Number:
+			texto = v.String()
+		case bool:
+			texto = fmt.Sprint(v)
+		default:
+			return fmt.Errorf("'%s' no es un numero, texto, booleano ni null", clave)
+		}
+		j.leidas = append(j.leidas, clave)
+		j.valores = append(j.valores, texto)
+	}
+	if _, err := dec.Token(); err != nil {
+		return err
+	}
+	if dec.More() {
+		return fmt.Errorf("hay mas de un objeto en la linea")
+	}
+	return nil
+}
+
+func (j *registrosJSONL) leer() ([]string, error) {
+	if err := j.siguiente(); err != nil {
+		return nil, err
+	}
+	if j.indice == nil {
+		j.indice = map[string]int{}
+		for i, c := range j.claves {
+			j.indice[c] = i
+		}
+	}
+	row := make([]string, len(j.claves))
+	for i, c := range j.leidas {
+		if pos, ok := j.indice[c]; ok {
+			row[pos] = j.valores[i]
+		}
+	}
+	return row, nil
+}
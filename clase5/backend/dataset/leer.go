@@ -0,0 +1,435 @@
+package dataset
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// Dataset es el resultado de leer un CSV con un esquema. X tiene una
+// columna por feature (NaN donde faltaba el dato); Y es nil si el CSV no
+// trae la etiqueta.
+type Dataset struct {
+	Features []string
+	Label    string
+	Clases   []string // nombres de las clases 0..K-1, si el esquema los da
+	X        *mat.Dense
+	Y        []int
+	// Columnas categoricas y sus categorias (features "columna=valor")
+	Categorias map[string][]string
+	// Columnas de texto, una entrada por fila
+	Textos map[string][]string
+	// Datos faltantes por columna del CSV
+	Faltantes map[string]int
+	// Esquema con el que se leyo y SHA-256 (hex) del CSV, para poder
+	// repetir el entrenamiento
+	Esquema Esquema
+	SHA256  string
+}
+
+// Muestras devuelve la cantidad de filas
+func (d *Dataset) Muestras() int {
+	r, _ := d.X.Dims()
+	return r
+}
+
+// FeaturesConFaltantes lista las features que tienen algun NaN en X
+func (d *Dataset) FeaturesConFaltantes() []string {
+	out := []string{}
+	for j, f := range d.Features {
+		for _, v := range mat.Col(nil, j, d.X) {
+			if math.IsNaN(v) {
+				out = append(out, f)
+				break
+			}
+		}
+	}
+	return out
+}
+
+// LeerCSV abre path y lo lee con el esquema (ver Leer)
+func LeerCSV(path string, esq Esquema, etiquetaOpcional bool) (*Dataset, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Leer(f, path, esq, etiquetaOpcional)
+}
+
+// Leer lee un CSV con encabezado. Se quita el BOM de UTF-8, el
+// delimitador se detecta si el esquema no lo fija y los campos pueden ir
+// entre comillas. Si etiquetaOpcional es true el CSV puede no traer la
+// columna de etiqueta (para predecir). nombre solo se usa en los errores.
+func Leer(r io.Reader, nombre string, esq Esquema, etiquetaOpcional bool) (*Dataset, error) {
+	if err := esq.Validar(); err != nil {
+		return nil, err
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	suma := sha256.Sum256(data)
+	data = bytes.TrimPrefix(data, []byte("\ufeff"))
+
+	delim, _ := esq.delimitador()
+	if delim == 0 {
+		delim = detectarDelimitador(data)
+	}
+	cr := csv.NewReader(bytes.NewReader(data))
+	cr.Comma = delim
+	cr.FieldsPerRecord = -1 // la cantidad de columnas se controla abajo
+	records, err := cr.ReadAll()
+	if err != nil {
+		var pe *csv.ParseError
+		if errors.As(err, &pe) {
+			return nil, fmt.Errorf("%w: %s: fila %d: %v", ErrDatasetInvalido, nombre, pe.Line, pe.Err)
+		}
+		return nil, fmt.Errorf("%w: %s: %v", ErrDatasetInvalido, nombre, err)
+	}
+	if len(records) < 2 {
+		return nil, fmt.Errorf("%w: %s no tiene filas de datos", ErrDatasetInvalido, nombre)
+	}
+
+	l, err := nuevoLector(records[0], esq, nombre, etiquetaOpcional)
+	if err != nil {
+		return nil, err
+	}
+	filas := records[1:]
+	for n, row := range filas {
+		if len(row) != len(l.header) {
+			return nil, fmt.Errorf("%w: fila %d: tiene %d columnas, se esperaban %d", ErrDatasetInvalido, n+2, len(row), len(l.header))
+		}
+	}
+	vistas := l.nuevasVistas()
+	for _, row := range filas {
+		l.verCategorias(row, vistas)
+	}
+	if err := l.fijarCategorias(vistas); err != nil {
+		return nil, err
+	}
+
+	d := &Dataset{
+		Features:   l.features,
+		Label:      esq.Etiqueta.Columna,
+		Clases:     append([]string(nil), esq.Etiqueta.Clases...),
+		Categorias: l.categorias,
+		Textos:     map[string][]string{},
+		Faltantes:  map[string]int{},
+		Esquema:    esq,
+		SHA256:     hex.EncodeToString(suma[:]),
+	}
+	X := mat.NewDense(len(filas), len(d.Features), nil)
+	if l.labelIdx != -1 {
+		d.Y = make([]int, 0, len(filas))
+	}
+	for n, row := range filas {
+		k, err := l.convertir(row, n+2, X.RawRowView(n), d.Faltantes, d.Textos) // el encabezado es la fila 1
+		if err != nil {
+			return nil, err
+		}
+		if l.labelIdx != -1 {
+			d.Y = append(d.Y, k)
+		}
+	}
+	d.X = X
+	return d, nil
+}
+
+// lector convierte los registros de un CSV (o de un JSONL, ver
+// FuenteArchivo) en filas de X segun el esquema
+type lector struct {
+	esq        Esquema
+	nombre     string
+	header     []string
+	cols       []Columna
+	labelIdx   int // -1 si no esta la etiqueta
+	indice     map[string]int
+	categorias map[string][]string
+	posicion   map[string]map[string]int
+	inicio     []int // primera feature de cada columna
+	features   []string
+}
+
+// nuevoLector arma el lector para el encabezado del archivo nombre
+func nuevoLector(encabezado []string, esq Esquema, nombre string, etiquetaOpcional bool) (*lector, error) {
+	l := &lector{
+		esq:      esq,
+		nombre:   nombre,
+		header:   make([]string, len(encabezado)),
+		cols:     make([]Columna, len(encabezado)),
+		labelIdx: -1,
+		indice:   map[string]int{},
+	}
+	for j, h := range encabezado {
+		l.header[j] = strings.TrimSpace(h)
+		if _, ok := l.indice[l.header[j]]; ok {
+			return nil, fmt.Errorf("%w: %s: la columna '%s' esta repetida", ErrDatasetInvalido, nombre, l.header[j])
+		}
+		l.indice[l.header[j]] = j
+		if l.header[j] == esq.Etiqueta.Columna {
+			l.labelIdx = j
+			continue
+		}
+		l.cols[j] = esq.columna(l.header[j])
+	}
+	if l.labelIdx == -1 && !etiquetaOpcional {
+		return nil, fmt.Errorf("%w: no se encontro la columna '%s' en %s", ErrDatasetInvalido, esq.Etiqueta.Columna, nombre)
+	}
+	for _, c := range esq.Columnas {
+		if _, ok := l.indice[c.Nombre]; !ok && c.Tipo != TipoIgnorar && c.Tipo != TipoTexto {
+			return nil, fmt.Errorf("%w: la columna '%s' del esquema no esta en %s", ErrDatasetInvalido, c.Nombre, nombre)
+		}
+	}
+	return l, nil
+}
+
+// categorica dice si la columna j es categorica y sus categorias salen
+// de los datos (no del esquema)
+func (l *lector) categorica(j int) bool {
+	return j != l.labelIdx && l.cols[j].Tipo == TipoCategorico && len(l.cols[j].Categorias) == 0
+}
+
+// nuevasVistas prepara el registro de categorias vistas de cada columna
+// categorica sin categorias en el esquema
+func (l *lector) nuevasVistas() map[string]map[string]bool {
+	vistas := map[string]map[string]bool{}
+	for j, c := range l.cols {
+		if l.categorica(j) {
+			vistas[c.Nombre] = map[string]bool{}
+		}
+	}
+	return vistas
+}
+
+// verCategorias anota las categorias de row
+func (l *lector) verCategorias(row []string, vistas map[string]map[string]bool) {
+	for j := range l.cols {
+		if !l.categorica(j) || j >= len(row) {
+			continue
+		}
+		if v := strings.TrimSpace(row[j]); !l.esq.esFaltante(v) {
+			vistas[l.cols[j].Nombre][v] = true
+		}
+	}
+}
+
+// fijarCategorias ordena las categorias vistas (las del esquema quedan
+// en su orden) y arma las features
+func (l *lector) fijarCategorias(vistas map[string]map[string]bool) error {
+	l.categorias = map[string][]string{}
+	l.posicion = map[string]map[string]int{}
+	for j, c := range l.cols {
+		if j == l.labelIdx || c.Tipo != TipoCategorico {
+			continue
+		}
+		cats := c.Categorias
+		if len(cats) == 0 {
+			for v := range vistas[c.Nombre] {
+				cats = append(cats, v)
+			}
+			sort.Strings(cats)
+		}
+		l.categorias[c.Nombre] = cats
+		l.posicion[c.Nombre] = map[string]int{}
+		for k, v := range cats {
+			l.posicion[c.Nombre][v] = k
+		}
+	}
+
+	l.inicio = make([]int, len(l.cols))
+	l.features = nil
+	for j, c := range l.cols {
+		l.inicio[j] = len(l.features)
+		if j == l.labelIdx {
+			continue
+		}
+		switch c.Tipo {
+		case TipoNumerico, TipoBooleano:
+			l.features = append(l.features, c.Nombre)
+		case TipoCategorico:
+			for _, v := range l.categorias[c.Nombre] {
+				l.features = append(l.features, c.Nombre+"="+v)
+			}
+		}
+	}
+	if len(l.features) == 0 {
+		return fmt.Errorf("%w: %s no tiene columnas de features", ErrDatasetInvalido, l.nombre)
+	}
+	return nil
+}
+
+// convertir escribe row en x (una posicion por feature) y devuelve la
+// clase (0 si no esta la etiqueta). fila es el numero de fila para los
+// errores. Cuenta los faltantes por columna y, si textos no es nil,
+// guarda las columnas de texto.
+func (l *lector) convertir(row []string, fila int, x []float64, faltantes map[string]int, textos map[string][]string) (int, error) {
+	if len(row) != len(l.header) {
+		return 0, fmt.Errorf("%w: fila %d: tiene %d columnas, se esperaban %d", ErrDatasetInvalido, fila, len(row), len(l.header))
+	}
+	for i := range x {
+		x[i] = 0
+	}
+	clase := 0
+	for j, val := range row {
+		val = strings.TrimSpace(val)
+		c := l.cols[j]
+		if j == l.labelIdx {
+			if l.esq.esFaltante(val) {
+				return 0, fmt.Errorf("%w: fila %d: falta la etiqueta", ErrDatasetInvalido, fila)
+			}
+			k, err := l.esq.clase(val)
+			if err != nil {
+				return 0, fmt.Errorf("%w: fila %d: %v", ErrDatasetInvalido, fila, err)
+			}
+			clase = k
+			continue
+		}
+		if c.Tipo == TipoIgnorar {
+			continue
+		}
+		if l.esq.esFaltante(val) {
+			faltantes[c.Nombre]++
+			switch c.Tipo {
+			case TipoNumerico, TipoBooleano:
+				x[l.inicio[j]] = math.NaN()
+			case TipoTexto:
+				if textos != nil {
+					textos[c.Nombre] = append(textos[c.Nombre], "")
+				}
+			}
+			continue
+		}
+		switch c.Tipo {
+		case TipoNumerico:
+			v, err := strconv.ParseFloat(val, 64)
+			if err != nil {
+				return 0, fmt.Errorf("%w: fila %d, columna %s: '%s' no es un numero", ErrDatasetInvalido, fila, c.Nombre, val)
+			}
+			x[l.inicio[j]] = v
+		case TipoBooleano:
+			v, ok := booleano(val)
+			if !ok {
+				return 0, fmt.Errorf("%w: fila %d, columna %s: '%s' no es un booleano (si/no, true/false, 1/0)", ErrDatasetInvalido, fila, c.Nombre, val)
+			}
+			x[l.inicio[j]] = v
+		case TipoCategorico:
+			k, ok := l.posicion[c.Nombre][val]
+			if !ok {
+				return 0, fmt.Errorf("%w: fila %d, columna %s: categoria '%s' no esta en el esquema (%s)", ErrDatasetInvalido, fila, c.Nombre, val, strings.Join(l.categorias[c.Nombre], ", "))
+			}
+			x[l.inicio[j]+k] = 1
+		case TipoTexto:
+			if textos != nil {
+				textos[c.Nombre] = append(textos[c.Nombre], val)
+			}
+		}
+	}
+	return clase, nil
+}
+
+// detectarDelimitador elige entre , ; tab y | el que mas aparece en el
+// encabezado (fuera de comillas); por defecto la coma.
+func detectarDelimitador(data []byte) rune {
+	linea := data
+	if i := bytes.IndexByte(data, '\n'); i >= 0 {
+		linea = data[:i]
+	}
+	cuenta := map[rune]int{}
+	entreComillas := false
+	for _, r := range string(linea) {
+		if r == '"' {
+			entreComillas = !entreComillas
+			continue
+		}
+		if !entreComillas {
+			cuenta[r]++
+		}
+	}
+	mejor := ','
+	for _, r := range []rune{';', '\t', '|'} {
+		if cuenta[r] > cuenta[mejor] {
+			mejor = r
+		}
+	}
+	return mejor
+}
+
+// Alinear deja las columnas de X en el orden de las features del
+// modelo; las columnas que el modelo no usa se descartan y las
+// categorias que no aparecen en este CSV quedan en 0. Si el modelo no
+// guarda nombres solo se controla la cantidad de columnas.
+func (d *Dataset) Alinear(features []string, nFeatures int) error {
+	if len(features) == 0 {
+		if _, nCols := d.X.Dims(); nCols != nFeatures {
+			return fmt.Errorf("%w: el modelo espera %d features y el CSV tiene %d", ErrDatasetInvalido, nFeatures, nCols)
+		}
+		return nil
+	}
+
+	idx := map[string]int{}
+	for j, f := range d.Features {
+		idx[f] = j
+	}
+	faltan := []string{}
+	for _, f := range features {
+		if _, ok := idx[f]; !ok && !d.esCategoria(f) {
+			faltan = append(faltan, f)
+		}
+	}
+	if len(faltan) > 0 {
+		return fmt.Errorf("%w: faltan las columnas %s", ErrDatasetInvalido, strings.Join(faltan, ", "))
+	}
+
+	X := mat.NewDense(d.Muestras(), len(features), nil)
+	for j, f := range features {
+		if i, ok := idx[f]; ok {
+			X.SetCol(j, mat.Col(nil, i, d.X))
+		}
+	}
+	d.X = X
+	d.Features = append([]string(nil), features...)
+	return nil
+}
+
+// esCategoria dice si la feature es "columna=valor" de una columna
+// categorica del dataset
+func (d *Dataset) esCategoria(feature string) bool {
+	col, _, ok := strings.Cut(feature, "=")
+	if !ok {
+		return false
+	}
+	_, ok = d.Categorias[col]
+	return ok
+}
+
+// Matriz arma X a partir de registros por nombre de feature, en el
+// orden de features. Una feature one-hot ("columna=valor") que no viene
+// en el registro vale 0; cualquier otra queda como faltante (NaN).
+func Matriz(features []string, registros []map[string]float64) (*mat.Dense, error) {
+	if len(registros) == 0 {
+		return nil, fmt.Errorf("%w: no hay registros", ErrDatasetInvalido)
+	}
+	X := mat.NewDense(len(registros), len(features), nil)
+	for i, r := range registros {
+		for j, f := range features {
+			v, ok := r[f]
+			if !ok && !strings.Contains(f, "=") {
+				v = math.NaN()
+			}
+			X.Set(i, j, v)
+		}
+	}
+	return X, nil
+}
@@ -0,0 +1,140 @@
+package dataset
+
+import (
+	"io"
+	"math/rand"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// mezcla es la Fuente de Mezclar
+type mezcla struct {
+	f    Fuente
+	tam  int
+	rng  *rand.Rand
+	fin  bool // f ya termino la pasada
+	x    [][]float64
+	y    []int
+	fila []int
+}
+
+// Mezclar entrega las filas de f en otro orden sin leerla entera: junta
+// hasta tam filas y saca una al azar, que se reemplaza por la siguiente
+// de f (un buffer de mezcla). Con tam mayor o igual a las filas de f la
+// mezcla es completa; con menos, cada fila sale a lo sumo tam lugares
+// antes de donde estaba. La memoria queda acotada por tam. Cada pasada
+// sale en otro orden, el mismo para la misma seed.
+func Mezclar(f Fuente, tam int, seed int64) Fuente {
+	if tam < 1 {
+		tam = 1
+	}
+	return &mezcla{f: f, tam: tam, rng: rand.New(rand.NewSource(seed))}
+}
+
+func (m *mezcla) Features() []string {
+	return m.f.Features()
+}
+
+func (m *mezcla) Reiniciar() error {
+	m.fin = false
+	m.x, m.y, m.fila = m.x[:0], m.y[:0], m.fila[:0]
+	return m.f.Reiniciar()
+}
+
+// llenar completa el buffer con filas de f
+func (m *mezcla) llenar() error {
+	for !m.fin && len(m.x) < m.tam {
+		lote, err := m.f.Leer(m.tam - len(m.x))
+		if err == io.EOF {
+			m.fin = true
+			break
+		}
+		if err != nil {
+			return err
+		}
+		for i := 0; i < lote.Muestras(); i++ {
+			m.x = append(m.x, append([]float64(nil), lote.X.RawRowView(i)...))
+			m.y = append(m.y, lote.Y[i])
+			m.fila = append(m.fila, lote.Filas[i])
+		}
+	}
+	return nil
+}
+
+func (m *mezcla) Leer(n int) (*Lote, error) {
+	lote := &Lote{}
+	var datos []float64
+	// el buffer se completa de a bloques: al empezar y si se vacia
+	if err := m.llenar(); err != nil {
+		return nil, err
+	}
+	for lote.Muestras() < n {
+		if len(m.x) == 0 {
+			if err := m.llenar(); err != nil {
+				return nil, err
+			}
+			if len(m.x) == 0 {
+				break
+			}
+		}
+		i := m.rng.Intn(len(m.x))
+		datos = append(datos, m.x[i]...)
+		lote.Y = append(lote.Y, m.y[i])
+		lote.Filas = append(lote.Filas, m.fila[i])
+
+		ultima := len(m.x) - 1
+		m.x[i], m.y[i], m.fila[i] = m.x[ultima], m.y[ultima], m.fila[ultima]
+		m.x, m.y, m.fila = m.x[:ultima], m.y[:ultima], m.fila[:ultima]
+	}
+	if lote.Muestras() == 0 {
+		return nil, io.EOF
+	}
+	lote.X = mat.NewDense(lote.Muestras(), len(m.Features()), datos)
+	return lote, nil
+}
+
+// filtro es la Fuente de Filtrar
+type filtro struct {
+	f       Fuente
+	incluir func(fila int) bool
+}
+
+// Filtrar entrega solo las filas de f para las que incluir(numero de
+// fila) es true, por ejemplo las de una parte (ver ParteDeFila)
+func Filtrar(f Fuente, incluir func(fila int) bool) Fuente {
+	return &filtro{f: f, incluir: incluir}
+}
+
+func (f *filtro) Features() []string {
+	return f.f.Features()
+}
+
+func (f *filtro) Reiniciar() error {
+	return f.f.Reiniciar()
+}
+
+func (f *filtro) Leer(n int) (*Lote, error) {
+	lote := &Lote{}
+	var datos []float64
+	for lote.Muestras() < n {
+		leido, err := f.f.Leer(n - lote.Muestras())
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		for i, fila := range leido.Filas {
+			if f.incluir(fila) {
+				datos = append(datos, leido.X.RawRowView(i)...)
+				lote.Y = append(lote.Y, leido.Y[i])
+				lote.Filas = append(lote.Filas, fila)
+			}
+		}
+	}
+	if lote.Muestras() == 0 {
+		return nil, io.EOF
+	}
+	lote.X = mat.NewDense(lote.Muestras(), len(f.Features()), datos)
+	return lote, nil
+}
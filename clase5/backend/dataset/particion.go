@@ -0,0 +1,151 @@
+package dataset
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// Proporciones de validacion y prueba; entrenamiento es el resto
+type Proporciones struct {
+	Validacion float64 `json:"validacion"`
+	Prueba     float64 `json:"prueba"`
+}
+
+// Validar controla que queden filas para entrenar
+func (p Proporciones) Validar() error {
+	if p.Validacion < 0 || p.Prueba < 0 || p.Validacion+p.Prueba >= 1 {
+		return fmt.Errorf("las proporciones de validacion (%v) y prueba (%v) deben ser positivas y sumar menos de 1", p.Validacion, p.Prueba)
+	}
+	return nil
+}
+
+// Particion son las filas de cada parte (indices desde 0 sobre las filas
+// de datos del CSV, en orden)
+type Particion struct {
+	Seed          int64        `json:"seed"`
+	Proporciones  Proporciones `json:"proporciones"`
+	Entrenamiento []int        `json:"entrenamiento"`
+	Validacion    []int        `json:"validacion"`
+	Prueba        []int        `json:"prueba"`
+	// PorFila indica que cada fila se sorteo con ParteDeFila; las listas
+	// quedan vacias (se recalculan con la seed)
+	PorFila bool `json:"por_fila,omitempty"`
+}
+
+// Particionar separa las filas en entrenamiento, validacion y prueba
+// manteniendo la proporcion de cada clase (estratificado). Cada clase
+// deja al menos una fila en entrenamiento, asi que las clases con muy
+// pocas filas pueden no aparecer en validacion o prueba. Con la misma
+// seed la particion es la misma.
+func Particionar(y []int, p Proporciones, seed int64) (Particion, error) {
+	if err := p.Validar(); err != nil {
+		return Particion{}, err
+	}
+	part := Particion{Seed: seed, Proporciones: p, Entrenamiento: []int{}, Validacion: []int{}, Prueba: []int{}}
+
+	porClase := map[int][]int{}
+	clases := []int{}
+	for i, yi := range y {
+		if _, ok := porClase[yi]; !ok {
+			clases = append(clases, yi)
+		}
+		porClase[yi] = append(porClase[yi], i)
+	}
+	sort.Ints(clases)
+
+	rng := rand.New(rand.NewSource(seed))
+	for _, c := range clases {
+		filas := porClase[c]
+		rng.Shuffle(len(filas), func(a, b int) { filas[a], filas[b] = filas[b], filas[a] })
+		n := len(filas)
+		nPrueba := int(math.Round(float64(n) * p.Prueba))
+		nVal := int(math.Round(float64(n) * p.Validacion))
+		for nPrueba+nVal > n-1 {
+			// primero se achica la parte mas grande
+			if nVal >= nPrueba && nVal > 0 {
+				nVal--
+			} else {
+				nPrueba--
+			}
+		}
+		part.Prueba = append(part.Prueba, filas[:nPrueba]...)
+		part.Validacion = append(part.Validacion, filas[nPrueba:nPrueba+nVal]...)
+		part.Entrenamiento = append(part.Entrenamiento, filas[nPrueba+nVal:]...)
+	}
+	sort.Ints(part.Entrenamiento)
+	sort.Ints(part.Validacion)
+	sort.Ints(part.Prueba)
+	return part, nil
+}
+
+// Partes de una particion
+const (
+	ParteEntrenamiento = "entrenamiento"
+	ParteValidacion    = "validacion"
+	PartePrueba        = "prueba"
+)
+
+// ParteDeFila sortea la parte de una fila sin mirar las demas, para los
+// datasets que se leen de a lotes (ver Fuente): la fila cae en prueba o
+// validacion con esas probabilidades segun un hash de la seed y el numero
+// de fila. No es estratificada y las proporciones son aproximadas.
+func (p Proporciones) ParteDeFila(seed int64, fila int) string {
+	// splitmix64
+	z := uint64(seed) + uint64(fila+1)*0x9e3779b97f4a7c15
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	z ^= z >> 31
+	u := float64(z>>11) / (1 << 53)
+	switch {
+	case u < p.Prueba:
+		return PartePrueba
+	case u < p.Prueba+p.Validacion:
+		return ParteValidacion
+	}
+	return ParteEntrenamiento
+}
+
+// Subconjunto es un dataset con las filas idx de d. Conserva el esquema
+// y el SHA-256 del archivo de origen.
+func (d *Dataset) Subconjunto(idx []int) *Dataset {
+	_, cols := d.X.Dims()
+	sub := &Dataset{
+		Features:   d.Features,
+		Label:      d.Label,
+		Clases:     d.Clases,
+		Categorias: d.Categorias,
+		Textos:     map[string][]string{},
+		Faltantes:  d.Faltantes,
+		Esquema:    d.Esquema,
+		SHA256:     d.SHA256,
+	}
+	if len(idx) == 0 {
+		// mat no admite matrices de 0 filas
+		sub.X = &mat.Dense{}
+		if d.Y != nil {
+			sub.Y = []int{}
+		}
+		return sub
+	}
+	sub.X = mat.NewDense(len(idx), cols, nil)
+	for i, fila := range idx {
+		sub.X.SetRow(i, d.X.RawRowView(fila))
+	}
+	if d.Y != nil {
+		sub.Y = make([]int, len(idx))
+		for i, fila := range idx {
+			sub.Y[i] = d.Y[fila]
+		}
+	}
+	for col, valores := range d.Textos {
+		sub.Textos[col] = make([]string, len(idx))
+		for i, fila := range idx {
+			sub.Textos[col][i] = valores[fila]
+		}
+	}
+	return sub
+}
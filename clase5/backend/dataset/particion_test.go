@@ -0,0 +1,181 @@
+package dataset
+
+import (
+	"math"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+// etiquetas arma y con tamanos[c] filas de cada clase c, intercaladas
+func etiquetas(tamanos ...int) []int {
+	y := []int{}
+	for quedan := true; quedan; {
+		quedan = false
+		for c := range tamanos {
+			if tamanos[c] > 0 {
+				y = append(y, c)
+				tamanos[c]--
+				quedan = true
+			}
+		}
+	}
+	return y
+}
+
+// contarPorClase devuelve cuantas filas de cada clase hay en filas
+func contarPorClase(y, filas []int) map[int]int {
+	n := map[int]int{}
+	for _, f := range filas {
+		n[y[f]]++
+	}
+	return n
+}
+
+func TestParticionarEstratificado(t *testing.T) {
+	casos := []struct {
+		nombre string
+		n      int // filas de la clase
+		prop   Proporciones
+		entr   int
+		val    int
+		prueba int
+	}{
+		{"70/15/15 con 20 filas", 20, Proporciones{Validacion: 0.15, Prueba: 0.15}, 14, 3, 3},
+		{"70/15/15 con 10 filas redondea hacia arriba", 10, Proporciones{Validacion: 0.15, Prueba: 0.15}, 6, 2, 2},
+		{"sin validacion ni prueba", 7, Proporciones{}, 7, 0, 0},
+		{"una fila queda en entrenamiento", 1, Proporciones{Validacion: 0.3, Prueba: 0.3}, 1, 0, 0},
+		{"dos filas: se achica validacion", 2, Proporciones{Validacion: 0.3, Prueba: 0.3}, 1, 0, 1},
+		{"dos filas con poca proporcion", 2, Proporciones{Validacion: 0.15, Prueba: 0.15}, 2, 0, 0},
+		{"tres filas, una por parte", 3, Proporciones{Validacion: 0.4, Prueba: 0.4}, 1, 1, 1},
+		{"se achica la parte mas grande", 4, Proporciones{Validacion: 0.5, Prueba: 0.4}, 1, 1, 2},
+	}
+	for _, c := range casos {
+		t.Run(c.nombre, func(t *testing.T) {
+			// tres clases del mismo tamano: cada una se parte igual
+			y := etiquetas(c.n, c.n, c.n)
+			part, err := Particionar(y, c.prop, 7)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for clase := 0; clase < 3; clase++ {
+				got := [3]int{contarPorClase(y, part.Entrenamiento)[clase], contarPorClase(y, part.Validacion)[clase], contarPorClase(y, part.Prueba)[clase]}
+				if want := [3]int{c.entr, c.val, c.prueba}; got != want {
+					t.Errorf("clase %d: entrenamiento/validacion/prueba = %v, se esperaba %v", clase, got, want)
+				}
+			}
+			revisarParticion(t, part, len(y))
+		})
+	}
+}
+
+// revisarParticion controla que las partes esten ordenadas, no se pisen y
+// cubran todas las filas
+func revisarParticion(t *testing.T, part Particion, n int) {
+	t.Helper()
+	todas := []int{}
+	for _, parte := range [][]int{part.Entrenamiento, part.Validacion, part.Prueba} {
+		if !sort.IntsAreSorted(parte) {
+			t.Errorf("parte sin ordenar: %v", parte)
+		}
+		todas = append(todas, parte...)
+	}
+	sort.Ints(todas)
+	for i, f := range todas {
+		if f != i {
+			t.Fatalf("las partes no cubren las %d filas una sola vez: %v", n, todas)
+		}
+	}
+	if len(todas) != n {
+		t.Fatalf("las partes tienen %d filas, el dataset %d", len(todas), n)
+	}
+}
+
+func TestParticionarClasesChicas(t *testing.T) {
+	// clases de 1 y 2 filas junto a una grande: siempre entrenan
+	y := etiquetas(30, 1, 2)
+	for seed := int64(1); seed <= 20; seed++ {
+		part, err := Particionar(y, Proporciones{Validacion: 0.25, Prueba: 0.25}, seed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		entr := contarPorClase(y, part.Entrenamiento)
+		for clase := 0; clase < 3; clase++ {
+			if entr[clase] == 0 {
+				t.Errorf("seed %d: la clase %d no tiene filas de entrenamiento", seed, clase)
+			}
+		}
+		revisarParticion(t, part, len(y))
+	}
+}
+
+func TestParticionarMismaSeed(t *testing.T) {
+	y := etiquetas(40, 25, 10)
+	prop := Proporciones{Validacion: 0.15, Prueba: 0.15}
+	a, err := Particionar(y, prop, 42)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := Particionar(y, prop, 42)
+	if !reflect.DeepEqual(a, b) {
+		t.Error("la misma seed dio particiones distintas")
+	}
+	c, _ := Particionar(y, prop, 43)
+	if reflect.DeepEqual(a.Prueba, c.Prueba) {
+		t.Error("las seeds 42 y 43 dieron la misma prueba")
+	}
+}
+
+func TestParticionarProporcionesInvalidas(t *testing.T) {
+	for _, p := range []Proporciones{{Validacion: -0.1}, {Validacion: 0.5, Prueba: 0.5}, {Prueba: 1.2}} {
+		if _, err := Particionar([]int{0, 1}, p, 1); err == nil {
+			t.Errorf("%+v: se esperaba un error", p)
+		}
+	}
+}
+
+func TestParteDeFila(t *testing.T) {
+	casos := []struct {
+		nombre string
+		prop   Proporciones
+	}{
+		{"70/15/15", Proporciones{Validacion: 0.15, Prueba: 0.15}},
+		{"80/20/0", Proporciones{Validacion: 0.2}},
+		{"todo entrenamiento", Proporciones{}},
+	}
+	const filas = 20000
+	for _, c := range casos {
+		t.Run(c.nombre, func(t *testing.T) {
+			n := map[string]int{}
+			for f := 0; f < filas; f++ {
+				parte := c.prop.ParteDeFila(7, f)
+				if otra := c.prop.ParteDeFila(7, f); otra != parte {
+					t.Fatalf("fila %d: %s y despues %s con la misma seed", f, parte, otra)
+				}
+				n[parte]++
+			}
+			esperadas := map[string]float64{
+				ParteEntrenamiento: 1 - c.prop.Validacion - c.prop.Prueba,
+				ParteValidacion:    c.prop.Validacion,
+				PartePrueba:        c.prop.Prueba,
+			}
+			for parte, p := range esperadas {
+				if got := float64(n[parte]) / filas; math.Abs(got-p) > 0.015 {
+					t.Errorf("%s: %.3f de las filas, se esperaba %.3f", parte, got, p)
+				}
+			}
+		})
+	}
+
+	// otra seed cambia el sorteo
+	prop := Proporciones{Validacion: 0.15, Prueba: 0.15}
+	distintas := 0
+	for f := 0; f < 1000; f++ {
+		if prop.ParteDeFila(7, f) != prop.ParteDeFila(8, f) {
+			distintas++
+		}
+	}
+	if distintas == 0 {
+		t.Error("las seeds 7 y 8 sortean igual todas las filas")
+	}
+}
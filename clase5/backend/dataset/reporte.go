@@ -0,0 +1,184 @@
+package dataset
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// PrefijoProbabilidad marca las features que son probabilidades (0 a 1),
+// como las a_* de bronco que vienen del modelo de lenguaje
+const PrefijoProbabilidad = "a_"
+
+// ResumenFeature describe los valores de una feature
+type ResumenFeature struct {
+	Feature string  `json:"feature"`
+	Min     float64 `json:"min"` // sobre los valores finitos
+	Max     float64 `json:"max"`
+	Media   float64 `json:"media"`
+	NaN     int     `json:"nan"` // faltantes
+	Inf     int     `json:"inf"`
+	// Solo para PrefijoProbabilidad: valores fuera de [0, 1]
+	FueraDeRango int `json:"fuera_de_rango,omitempty"`
+}
+
+// ConteoClase es cuantas filas tiene cada clase
+type ConteoClase struct {
+	Clase      int     `json:"clase"`
+	Nombre     string  `json:"nombre,omitempty"`
+	Filas      int     `json:"filas"`
+	Porcentaje float64 `json:"porcentaje"`
+}
+
+// Reporte es la revision de un dataset antes de entrenar. Errores impide
+// entrenar; Avisos son cosas a mirar.
+type Reporte struct {
+	Muestras  int              `json:"muestras"`
+	Features  []ResumenFeature `json:"features"`
+	Etiquetas []ConteoClase    `json:"etiquetas,omitempty"`
+	// Grupos de filas del CSV (contando el encabezado como fila 1) con
+	// las mismas features y etiqueta
+	Duplicadas [][]int  `json:"duplicadas"`
+	Errores    []string `json:"errores"`
+	Avisos     []string `json:"avisos"`
+}
+
+// Ok dice si se puede entrenar con el dataset
+func (r Reporte) Ok() bool {
+	return len(r.Errores) == 0
+}
+
+// Revisar calcula rangos, NaN/Inf, probabilidades fuera de [0, 1],
+// distribucion de la etiqueta y filas duplicadas. Los faltantes son un
+// error solo si el esquema no dice como imputarlos.
+func Revisar(d *Dataset) Reporte {
+	n := d.Muestras()
+	r := Reporte{Muestras: n, Features: []ResumenFeature{}, Duplicadas: [][]int{}, Errores: []string{}, Avisos: []string{}}
+
+	for j, f := range d.Features {
+		res := ResumenFeature{Feature: f}
+		finitos, suma := 0, 0.0
+		for i := 0; i < n; i++ {
+			v := d.X.At(i, j)
+			switch {
+			case math.IsNaN(v):
+				res.NaN++
+				continue
+			case math.IsInf(v, 0):
+				res.Inf++
+				continue
+			}
+			if finitos == 0 || v < res.Min {
+				res.Min = v
+			}
+			if finitos == 0 || v > res.Max {
+				res.Max = v
+			}
+			finitos++
+			suma += v
+			if strings.HasPrefix(f, PrefijoProbabilidad) && (v < 0 || v > 1) {
+				res.FueraDeRango++
+			}
+		}
+		if finitos > 0 {
+			res.Media = suma / float64(finitos)
+		}
+		r.Features = append(r.Features, res)
+
+		if res.Inf > 0 {
+			r.Errores = append(r.Errores, fmt.Sprintf("%s: %d valores infinitos", f, res.Inf))
+		}
+		if res.NaN > 0 {
+			if imp := d.Esquema.Imputacion.Para(f); imp.Estrategia != "" {
+				r.Avisos = append(r.Avisos, fmt.Sprintf("%s: %d faltantes, se imputan con %s", f, res.NaN, imp.Estrategia))
+			} else {
+				r.Errores = append(r.Errores, fmt.Sprintf("%s: %d faltantes y el esquema no dice como imputarlos", f, res.NaN))
+			}
+		}
+		if res.FueraDeRango > 0 {
+			r.Avisos = append(r.Avisos, fmt.Sprintf("%s: %d probabilidades fuera de [0, 1]", f, res.FueraDeRango))
+		}
+		if finitos > 1 && res.Min == res.Max {
+			r.Avisos = append(r.Avisos, fmt.Sprintf("%s: vale siempre %g", f, res.Min))
+		}
+	}
+
+	if d.Y != nil {
+		r.Etiquetas = distribucion(d.Y, d.Clases)
+		presentes := 0
+		for _, c := range r.Etiquetas {
+			if c.Filas == 0 {
+				r.Avisos = append(r.Avisos, fmt.Sprintf("la clase %s no tiene filas", nombreClase(c)))
+			} else {
+				presentes++
+			}
+		}
+		if presentes < 2 {
+			r.Errores = append(r.Errores, "la etiqueta necesita al menos 2 clases con filas")
+		}
+	}
+
+	grupos := map[string][]int{}
+	orden := []string{}
+	for i := 0; i < n; i++ {
+		clave := claveFila(d, i)
+		if _, ok := grupos[clave]; !ok {
+			orden = append(orden, clave)
+		}
+		grupos[clave] = append(grupos[clave], i+2)
+	}
+	repetidas := 0
+	for _, clave := range orden {
+		if filas := grupos[clave]; len(filas) > 1 {
+			r.Duplicadas = append(r.Duplicadas, filas)
+			repetidas += len(filas) - 1
+		}
+	}
+	if repetidas > 0 {
+		r.Avisos = append(r.Avisos, fmt.Sprintf("%d filas repetidas en %d grupos", repetidas, len(r.Duplicadas)))
+	}
+	return r
+}
+
+// distribucion cuenta las filas de cada clase 0..K-1 (K = max(y)+1 o la
+// cantidad de clases con nombre, lo que sea mayor)
+func distribucion(y []int, clases []string) []ConteoClase {
+	k := len(clases)
+	for _, v := range y {
+		k = max(k, v+1)
+	}
+	out := make([]ConteoClase, k)
+	for c := range out {
+		out[c].Clase = c
+		if c < len(clases) {
+			out[c].Nombre = clases[c]
+		}
+	}
+	for _, v := range y {
+		out[v].Filas++
+	}
+	for c := range out {
+		out[c].Porcentaje = math.Round(float64(out[c].Filas)/float64(len(y))*1000) / 10
+	}
+	return out
+}
+
+func nombreClase(c ConteoClase) string {
+	if c.Nombre != "" {
+		return fmt.Sprintf("%d (%s)", c.Clase, c.Nombre)
+	}
+	return strconv.Itoa(c.Clase)
+}
+
+// claveFila identifica una fila por sus features y su etiqueta
+func claveFila(d *Dataset, i int) string {
+	partes := make([]string, 0, len(d.Features)+1)
+	for _, v := range d.X.RawRowView(i) {
+		partes = append(partes, strconv.FormatFloat(v, 'g', -1, 64))
+	}
+	if d.Y != nil {
+		partes = append(partes, "y="+strconv.Itoa(d.Y[i]))
+	}
+	return strings.Join(partes, ",")
+}
@@ -0,0 +1,90 @@
+// Package entrenamiento entrena algorithms.SoftmaxRegression sobre un
+// dataset.Dataset, lo evalua y exporta sus resultados. Lo usan
+// cmd/softmaxctl y la API.
+package entrenamiento
+
+import (
+	"fmt"
+	"strings"
+
+	"unmatch/backend/algorithms"
+	"unmatch/backend/dataset"
+)
+
+// Hiperparametros de algorithms.SoftmaxRegression. Seed 0 deja que Fit
+// elija una (queda guardada en el modelo).
+type Hiperparametros struct {
+	Lr        float64 `json:"lr"`
+	NIter     int     `json:"n_iter"`
+	RegLambda float64 `json:"reg_lambda"`
+	Seed      int64   `json:"seed"`
+}
+
+// HiperparametrosPorDefecto son los que usaba TrainSoftmaxBronco
+var HiperparametrosPorDefecto = Hiperparametros{Lr: 0.1, NIter: 3000, RegLambda: 1e-3}
+
+// Validar revisa que los hiperparametros tengan sentido
+func (hp Hiperparametros) Validar() error {
+	switch {
+	case hp.Lr <= 0:
+		return fmt.Errorf("lr debe ser mayor a 0 (%v)", hp.Lr)
+	case hp.NIter <= 0:
+		return fmt.Errorf("las iteraciones deben ser mayores a 0 (%d)", hp.NIter)
+	case hp.RegLambda < 0:
+		return fmt.Errorf("lambda no puede ser negativo (%v)", hp.RegLambda)
+	}
+	return nil
+}
+
+// Entrenar revisa d (ver dataset.Revisar), imputa sus faltantes segun
+// el esquema y ajusta un modelo softmax. El modelo guarda los nombres de
+// las features, de la etiqueta y de las clases y la imputacion; d queda
+// imputado, con las columnas del modelo.
+func Entrenar(d *dataset.Dataset, hp Hiperparametros) (*algorithms.SoftmaxRegression, error) {
+	return entrenar(d, hp, nil)
+}
+
+// entrenar es Entrenar; antesDeFit (si no es nil) recibe el modelo con
+// la imputacion ya ajustada justo antes de Fit, por ejemplo para
+// engancharle OnIteration.
+func entrenar(d *dataset.Dataset, hp Hiperparametros, antesDeFit func(*algorithms.SoftmaxRegression) error) (*algorithms.SoftmaxRegression, error) {
+	if err := hp.Validar(); err != nil {
+		return nil, err
+	}
+	if d.Y == nil {
+		return nil, fmt.Errorf("%w: falta la columna de etiqueta '%s'", dataset.ErrDatasetInvalido, d.Label)
+	}
+	if d.Muestras() == 0 {
+		return nil, fmt.Errorf("%w: no hay filas para entrenar", dataset.ErrDatasetInvalido)
+	}
+	if rep := dataset.Revisar(d); !rep.Ok() {
+		return nil, fmt.Errorf("%w: %s", dataset.ErrDatasetInvalido, strings.Join(rep.Errores, "; "))
+	}
+
+	imps, err := AjustarImputacion(d)
+	if err != nil {
+		return nil, err
+	}
+	if d.X, d.Features, err = Imputar(d.X, d.Features, imps); err != nil {
+		return nil, err
+	}
+
+	model := algorithms.NewSoftmaxRegression(hp.Lr, hp.NIter, hp.RegLambda)
+	model.Seed = hp.Seed
+	model.FeatureNames = append([]string(nil), d.Features...)
+	model.Label = d.Label
+	model.Imputation = imps
+	if antesDeFit != nil {
+		if err := antesDeFit(model); err != nil {
+			return nil, err
+		}
+	}
+	model.Fit(d.X, d.Y)
+	model.OnIteration = nil
+	// Fit usa max(y)+1 clases: los nombres de clases que no aparecen al
+	// final no entran en el modelo
+	if _, nClases := model.W.Dims(); len(d.Clases) >= nClases {
+		model.Classes = append([]string(nil), d.Clases[:nClases]...)
+	}
+	return model, nil
+}
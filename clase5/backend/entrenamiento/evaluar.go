@@ -0,0 +1,134 @@
+package entrenamiento
+
+import (
+	"fmt"
+	"math"
+
+	"gonum.org/v1/gonum/mat"
+
+	"unmatch/backend/algorithms"
+	"unmatch/backend/dataset"
+)
+
+// MetricaClase son precision, recall y F1 de una clase
+type MetricaClase struct {
+	Clase     int     `json:"clase"`
+	Precision float64 `json:"precision"`
+	Recall    float64 `json:"recall"`
+	F1        float64 `json:"f1"`
+	Soporte   int     `json:"soporte"` // filas con esa etiqueta real
+}
+
+// Metricas resume la evaluacion de un modelo sobre un dataset
+type Metricas struct {
+	Muestras  int            `json:"muestras"`
+	Accuracy  float64        `json:"accuracy"`
+	LogLoss   float64        `json:"log_loss"`
+	Confusion [][]int        `json:"confusion"` // fila = clase real, columna = predicha
+	PorClase  []MetricaClase `json:"por_clase"`
+}
+
+// Evaluar compara las predicciones del modelo con las etiquetas de d.
+// d se prepara para el modelo (ver Preparar).
+func Evaluar(model *algorithms.SoftmaxRegression, d *dataset.Dataset) (Metricas, error) {
+	if d.Y == nil {
+		return Metricas{}, fmt.Errorf("%w: falta la columna de etiqueta '%s'", dataset.ErrDatasetInvalido, d.Label)
+	}
+	if err := Preparar(model, d); err != nil {
+		return Metricas{}, err
+	}
+	return Medir(model, d.X, d.Y), nil
+}
+
+// Medir calcula las metricas sobre X ya preparada para el modelo (por
+// ejemplo la que deja Entrenar).
+func Medir(model *algorithms.SoftmaxRegression, X *mat.Dense, y []int) Metricas {
+	m := nuevaMedicion(model)
+	m.agregar(X, y)
+	return m.metricas()
+}
+
+// medicion junta las predicciones de varios lotes para calcular las
+// metricas al final sin tener todas las filas en memoria
+type medicion struct {
+	model     *algorithms.SoftmaxRegression
+	nClases   int
+	muestras  int
+	aciertos  int
+	logLoss   float64
+	confusion [][]int
+}
+
+func nuevaMedicion(model *algorithms.SoftmaxRegression) *medicion {
+	_, nClases := model.W.Dims()
+	m := &medicion{model: model, nClases: nClases}
+	m.agrandar(nClases)
+	return m
+}
+
+// agrandar lleva la matriz de confusion a k clases
+func (m *medicion) agrandar(k int) {
+	for i := range m.confusion {
+		for len(m.confusion[i]) < k {
+			m.confusion[i] = append(m.confusion[i], 0)
+		}
+	}
+	for len(m.confusion) < k {
+		m.confusion = append(m.confusion, make([]int, k))
+	}
+}
+
+// agregar suma las filas de X (ya preparada) con sus etiquetas
+func (m *medicion) agregar(X *mat.Dense, y []int) {
+	// etiquetas que el modelo nunca vio agrandan la matriz de confusion
+	for _, yi := range y {
+		if yi+1 > len(m.confusion) {
+			m.agrandar(yi + 1)
+		}
+	}
+
+	probs := m.model.PredictProba(X)
+	pred := m.model.Predict(X)
+	m.muestras += len(y)
+	for i, yi := range y {
+		m.confusion[yi][pred[i]]++
+		if yi == pred[i] {
+			m.aciertos++
+		}
+		p := 1e-15
+		if yi < m.nClases {
+			p = math.Max(probs.At(i, yi), 1e-15)
+		}
+		m.logLoss -= math.Log(p)
+	}
+}
+
+func (m *medicion) metricas() Metricas {
+	k := len(m.confusion)
+	met := Metricas{Muestras: m.muestras, LogLoss: m.logLoss, Confusion: m.confusion}
+	if met.Muestras > 0 {
+		met.Accuracy = float64(m.aciertos) / float64(met.Muestras)
+		met.LogLoss /= float64(met.Muestras)
+	}
+
+	for c := 0; c < k; c++ {
+		mc := MetricaClase{Clase: c}
+		predichas := 0
+		for r := 0; r < k; r++ {
+			predichas += met.Confusion[r][c]
+			mc.Soporte += met.Confusion[c][r]
+		}
+		tp := float64(met.Confusion[c][c])
+		if predichas > 0 {
+			mc.Precision = tp / float64(predichas)
+		}
+		if mc.Soporte > 0 {
+			mc.Recall = tp / float64(mc.Soporte)
+		}
+		if mc.Precision+mc.Recall > 0 {
+			mc.F1 = 2 * mc.Precision * mc.Recall / (mc.Precision + mc.Recall)
+		}
+		met.PorClase = append(met.PorClase, mc)
+	}
+	return met
+}
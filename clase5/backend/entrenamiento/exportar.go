@@ -0,0 +1,135 @@
+package entrenamiento
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+
+	"gonum.org/v1/gonum/mat"
+
+	"unmatch/backend/algorithms"
+)
+
+// argmax devuelve la clase de mayor probabilidad de una fila
+func argmax(row []float64) int {
+	yPred := 0
+	for k := 1; k < len(row); k++ {
+		if row[k] > row[yPred] {
+			yPred = k
+		}
+	}
+	return yPred
+}
+
+// ExportarPerdidaCSV escribe el historial de pérdida a un CSV, con la
+// de validacion si no es nil.
+// Formato columnas: iter, loss[, loss_validacion]
+func ExportarPerdidaCSV(path string, loss, validacion []float64) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	w := csv.NewWriter(f)
+	header := []string{"iter", "loss"}
+	if validacion != nil {
+		header = append(header, "loss_validacion")
+	}
+	if err := w.Write(header); err != nil {
+		return err
+	}
+	for i, v := range loss {
+		record := []string{strconv.Itoa(i), fmt.Sprintf("%f", v)}
+		if validacion != nil {
+			record = append(record, "")
+			if i < len(validacion) {
+				record[2] = fmt.Sprintf("%f", validacion[i])
+			}
+		}
+		if err := w.Write(record); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
+
+// EscribirPredicciones escribe una fila por muestra con la clase
+// predicha (y su nombre, si el modelo tiene clases) y la probabilidad
+// de cada clase.
+// Formato columnas: fila, y_pred, [clase], p0, p1, ..., pK
+func EscribirPredicciones(out io.Writer, probs *mat.Dense, clases []string) error {
+	w := csv.NewWriter(out)
+	rows, nClasses := probs.Dims()
+	conNombre := len(clases) == nClasses
+	header := []string{"fila", "y_pred"}
+	if conNombre {
+		header = append(header, "clase")
+	}
+	for k := 0; k < nClasses; k++ {
+		header = append(header, fmt.Sprintf("p%d", k))
+	}
+	if err := w.Write(header); err != nil {
+		return err
+	}
+	for i := 0; i < rows; i++ {
+		pRow := probs.RawRowView(i)
+		yPred := argmax(pRow)
+		record := []string{strconv.Itoa(i + 1), strconv.Itoa(yPred)}
+		if conNombre {
+			record = append(record, clases[yPred])
+		}
+		for _, p := range pRow {
+			record = append(record, strconv.FormatFloat(p, 'f', 6, 64))
+		}
+		if err := w.Write(record); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
+
+// ExportarPesosCSV escribe la matriz W con una fila por feature (con su
+// nombre si el modelo lo guarda) y una ultima fila "bias" con B.
+// Formato columnas: feature, clase_0, ..., clase_K
+func ExportarPesosCSV(out io.Writer, model *algorithms.SoftmaxRegression) error {
+	w := csv.NewWriter(out)
+	nFeatures, nClasses := model.W.Dims()
+	header := []string{"feature"}
+	for k := 0; k < nClasses; k++ {
+		header = append(header, fmt.Sprintf("clase_%d", k))
+	}
+	if err := w.Write(header); err != nil {
+		return err
+	}
+	for i := 0; i < nFeatures; i++ {
+		record := []string{NombreFeature(model, i)}
+		for _, v := range model.W.RawRowView(i) {
+			record = append(record, strconv.FormatFloat(v, 'g', -1, 64))
+		}
+		if err := w.Write(record); err != nil {
+			return err
+		}
+	}
+	bias := []string{"bias"}
+	for k := 0; k < nClasses; k++ {
+		bias = append(bias, strconv.FormatFloat(model.B.AtVec(k), 'g', -1, 64))
+	}
+	if err := w.Write(bias); err != nil {
+		return err
+	}
+	w.Flush()
+	return w.Error()
+}
+
+// NombreFeature devuelve el nombre guardado en el modelo o "x<i+1>"
+func NombreFeature(model *algorithms.SoftmaxRegression, i int) string {
+	if i < len(model.FeatureNames) {
+		return model.FeatureNames[i]
+	}
+	return fmt.Sprintf("x%d", i+1)
+}
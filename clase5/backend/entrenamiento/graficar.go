@@ -0,0 +1,65 @@
+package entrenamiento
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"gonum.org/v1/gonum/mat"
+
+	"unmatch/backend/algorithms"
+	"unmatch/backend/graficos"
+)
+
+// RutaGrafico es el grafico que va al lado de un CSV:
+// ("weights/loss.csv", "_p0", "svg") -> "weights/loss_p0.svg"
+func RutaGrafico(csvPath, sufijo, formato string) string {
+	return strings.TrimSuffix(csvPath, filepath.Ext(csvPath)) + sufijo + "." + formato
+}
+
+// ValidarFormatoGrafico controla que formato sea svg o png
+func ValidarFormatoGrafico(formato string) error {
+	for _, f := range graficos.Formatos {
+		if formato == f {
+			return nil
+		}
+	}
+	return fmt.Errorf("formato de grafico '%s' no existe, use %s", formato, strings.Join(graficos.Formatos, " o "))
+}
+
+// GraficarPerdida dibuja la curva de perdida de entrenamiento y, si no
+// es nil, la de validacion
+func GraficarPerdida(path string, loss, validacion []float64) error {
+	series := []graficos.Serie{{Nombre: "entrenamiento", Valores: loss}}
+	if validacion != nil {
+		series = append(series, graficos.Serie{Nombre: "validacion", Valores: validacion})
+	}
+	return graficos.CurvaPerdida(path, series...)
+}
+
+// GraficarPuntos guarda al lado de base las regiones de decision
+// (base_regiones) y la probabilidad de cada clase (base_p0, base_p1, ...)
+// de un modelo de 2 features, con las muestras de X. Devuelve los
+// archivos escritos; si el modelo no tiene 2 features no escribe nada.
+func GraficarPuntos(base, formato string, model *algorithms.SoftmaxRegression, X *mat.Dense, y []int) ([]string, error) {
+	nFeatures, nClases := model.W.Dims()
+	if _, cols := X.Dims(); nFeatures != 2 || cols != 2 {
+		return nil, nil
+	}
+	nombres := [2]string{NombreFeature(model, 0), NombreFeature(model, 1)}
+	escritos := []string{}
+
+	path := RutaGrafico(base, "_regiones", formato)
+	if err := graficos.RegionesDecision(path, model, X, y, model.Classes, nombres); err != nil {
+		return escritos, err
+	}
+	escritos = append(escritos, path)
+	for k := 0; k < nClases; k++ {
+		path := RutaGrafico(base, fmt.Sprintf("_p%d", k), formato)
+		if err := graficos.MapaProbabilidad(path, model, X, y, k, model.Classes, nombres); err != nil {
+			return escritos, err
+		}
+		escritos = append(escritos, path)
+	}
+	return escritos, nil
+}
@@ -0,0 +1,170 @@
+package entrenamiento
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"gonum.org/v1/gonum/mat"
+
+	"unmatch/backend/algorithms"
+	"unmatch/backend/dataset"
+)
+
+// SufijoIndicador forma el nombre de la columna indicadora de una feature
+const SufijoIndicador = "_faltante"
+
+// AjustarImputacion calcula sobre los datos de entrenamiento el valor de
+// relleno de cada feature que tiene estrategia en el esquema de d. Las
+// features one-hot no se imputan (un faltante ya es todo 0).
+func AjustarImputacion(d *dataset.Dataset) ([]algorithms.Imputation, error) {
+	out := []algorithms.Imputation{}
+	for j, f := range d.Features {
+		imp := d.Esquema.Imputacion.Para(f)
+		if imp.Estrategia == "" || strings.Contains(f, "=") {
+			continue
+		}
+		valores := []float64{}
+		for _, v := range mat.Col(nil, j, d.X) {
+			if !math.IsNaN(v) && !math.IsInf(v, 0) {
+				valores = append(valores, v)
+			}
+		}
+
+		ajuste := algorithms.Imputation{Feature: f, Strategy: imp.Estrategia, Value: imp.Valor}
+		switch imp.Estrategia {
+		case dataset.ImputarMedia, dataset.ImputarMediana, dataset.ImputarModa:
+			if len(valores) == 0 {
+				return nil, fmt.Errorf("%w: %s no tiene valores para calcular la %s", dataset.ErrDatasetInvalido, f, imp.Estrategia)
+			}
+		}
+		switch imp.Estrategia {
+		case dataset.ImputarMedia:
+			ajuste.Value = media(valores)
+		case dataset.ImputarMediana:
+			ajuste.Value = mediana(valores)
+		case dataset.ImputarModa:
+			ajuste.Value = moda(valores)
+		}
+		if imp.Indicador || imp.Estrategia == dataset.ImputarIndicador {
+			ajuste.Indicator = f + SufijoIndicador
+		}
+		out = append(out, ajuste)
+	}
+	return out, nil
+}
+
+// Imputar reemplaza los NaN de X (una columna por feature) por el valor
+// de cada imputacion y agrega al final las columnas indicadoras.
+// Devuelve la nueva X y sus features.
+func Imputar(X *mat.Dense, features []string, imps []algorithms.Imputation) (*mat.Dense, []string, error) {
+	if len(imps) == 0 {
+		return X, features, nil
+	}
+	idx := map[string]int{}
+	for j, f := range features {
+		idx[f] = j
+	}
+	nuevas := append([]string(nil), features...)
+	for _, imp := range imps {
+		if _, ok := idx[imp.Feature]; !ok {
+			return nil, nil, fmt.Errorf("%w: falta la columna %s", dataset.ErrDatasetInvalido, imp.Feature)
+		}
+		if imp.Indicator != "" {
+			nuevas = append(nuevas, imp.Indicator)
+		}
+	}
+
+	rows, cols := X.Dims()
+	out := mat.NewDense(rows, len(nuevas), nil)
+	out.Slice(0, rows, 0, cols).(*mat.Dense).Copy(X)
+	ind := cols
+	for _, imp := range imps {
+		j := idx[imp.Feature]
+		for i := 0; i < rows; i++ {
+			if !math.IsNaN(out.At(i, j)) {
+				continue
+			}
+			out.Set(i, j, imp.Value)
+			if imp.Indicator != "" {
+				out.Set(i, ind, 1)
+			}
+		}
+		if imp.Indicator != "" {
+			ind++
+		}
+	}
+	return out, nuevas, nil
+}
+
+// EntradaModelo devuelve las features que el modelo lee de los datos
+// (sin las columnas indicadoras que agrega la imputacion)
+func EntradaModelo(model *algorithms.SoftmaxRegression) []string {
+	indicadoras := map[string]bool{}
+	for _, imp := range model.Imputation {
+		if imp.Indicator != "" {
+			indicadoras[imp.Indicator] = true
+		}
+	}
+	out := []string{}
+	for _, f := range model.FeatureNames {
+		if !indicadoras[f] {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+// Preparar deja d listo para el modelo: alinea las columnas con sus
+// features, imputa los faltantes como en el entrenamiento y controla
+// que no quede ninguno.
+func Preparar(model *algorithms.SoftmaxRegression, d *dataset.Dataset) error {
+	nFeatures, _ := model.W.Dims()
+	entrada := EntradaModelo(model)
+	if err := d.Alinear(entrada, nFeatures-(len(model.FeatureNames)-len(entrada))); err != nil {
+		return err
+	}
+	X, features, err := Imputar(d.X, d.Features, model.Imputation)
+	if err != nil {
+		return err
+	}
+	d.X, d.Features = X, features
+	if faltan := d.FeaturesConFaltantes(); len(faltan) > 0 {
+		return fmt.Errorf("%w: faltan valores de %s y el modelo no los imputa", dataset.ErrDatasetInvalido, strings.Join(faltan, ", "))
+	}
+	return nil
+}
+
+func media(v []float64) float64 {
+	suma := 0.0
+	for _, x := range v {
+		suma += x
+	}
+	return suma / float64(len(v))
+}
+
+func mediana(v []float64) float64 {
+	s := append([]float64(nil), v...)
+	sort.Float64s(s)
+	n := len(s)
+	if n%2 == 1 {
+		return s[n/2]
+	}
+	return (s[n/2-1] + s[n/2]) / 2
+}
+
+// moda devuelve el valor mas frecuente (el menor si hay empate)
+func moda(v []float64) float64 {
+	cuenta := map[float64]int{}
+	for _, x := range v {
+		cuenta[x]++
+	}
+	mejor, veces := 0.0, 0
+	for x, n := range cuenta {
+		if n > veces || (n == veces && x < mejor) {
+			mejor, veces = x, n
+		}
+	}
+	return mejor
+}
@@ -0,0 +1,115 @@
+package entrenamiento
+
+import (
+	"encoding/json"
+	"os"
+	"os/exec"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"unmatch/backend/algorithms"
+	"unmatch/backend/dataset"
+)
+
+// DatasetManifiesto identifica los datos con los que se entreno
+type DatasetManifiesto struct {
+	Origen   string           `json:"origen"` // path del CSV o nombre del archivo subido
+	SHA256   string           `json:"sha256"`
+	Muestras int              `json:"muestras"`
+	Esquema  *dataset.Esquema `json:"esquema,omitempty"` // si se leyo de un CSV
+}
+
+// Manifiesto es todo lo necesario para repetir un entrenamiento: con el
+// mismo dataset (SHA-256), esquema, hiperparametros (incluida la seed) y
+// commit se obtienen exactamente los mismos pesos.
+type Manifiesto struct {
+	Modelo          string            `json:"modelo"`
+	Fecha           time.Time         `json:"fecha"`
+	Dataset         DatasetManifiesto `json:"dataset"`
+	Hiperparametros Hiperparametros   `json:"hiperparametros"`
+	Features        []string          `json:"features"`
+	GoVersion       string            `json:"go_version"`
+	GitCommit       string            `json:"git_commit,omitempty"`
+	GitModificado   bool              `json:"git_modificado,omitempty"` // habia cambios sin commitear
+	Comando         []string          `json:"comando,omitempty"`
+	Accuracy        float64           `json:"accuracy_entrenamiento"`
+	PerdidaFinal    float64           `json:"perdida_final"`
+	// Filas de entrenamiento, validacion y prueba (ver Resultado.Manifiesto)
+	Particion *dataset.Particion `json:"particion,omitempty"`
+	Metricas  *MetricasParticion `json:"metricas,omitempty"`
+	// Lotes y epocas si se entreno de a lotes (ver EntrenarStreaming)
+	Streaming *OpcionesStreaming `json:"streaming,omitempty"`
+}
+
+// RutaManifiesto es donde va el manifiesto de un modelo:
+// weights/softmax_model.json -> weights/softmax_model.manifiesto.json
+func RutaManifiesto(modelPath string) string {
+	return strings.TrimSuffix(modelPath, ".json") + ".manifiesto.json"
+}
+
+// NuevoManifiesto describe el entrenamiento de model sobre d (ya
+// preparado por Entrenar). comando es la linea de comandos, si la hay.
+func NuevoManifiesto(modelPath, origen string, d *dataset.Dataset, model *algorithms.SoftmaxRegression, comando []string) Manifiesto {
+	var esq *dataset.Esquema
+	if d.Esquema.Etiqueta.Columna != "" {
+		copia := d.Esquema
+		esq = &copia
+	}
+	m := nuevoManifiesto(modelPath, DatasetManifiesto{Origen: origen, SHA256: d.SHA256, Muestras: d.Muestras(), Esquema: esq}, model, comando)
+	m.Accuracy = model.Accuracy(d.X, d.Y)
+	return m
+}
+
+// nuevoManifiesto es lo que no depende de tener el dataset en memoria
+func nuevoManifiesto(modelPath string, datos DatasetManifiesto, model *algorithms.SoftmaxRegression, comando []string) Manifiesto {
+	m := Manifiesto{
+		Modelo:          modelPath,
+		Fecha:           time.Now().UTC().Truncate(time.Second),
+		Dataset:         datos,
+		Hiperparametros: Hiperparametros{Lr: model.Lr, NIter: model.NIter, RegLambda: model.RegLambda, Seed: model.Seed},
+		Features:        model.FeatureNames,
+		GoVersion:       runtime.Version(),
+		Comando:         comando,
+	}
+	if n := len(model.LossHistory); n > 0 {
+		m.PerdidaFinal = model.LossHistory[n-1]
+	}
+	m.GitCommit, m.GitModificado = commitGit()
+	return m
+}
+
+// Guardar escribe el manifiesto en JSON
+func (m Manifiesto) Guardar(path string) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// commitGit toma el commit de la informacion de compilacion (go build) o,
+// con go run, de git en el directorio actual. Sin git devuelve "".
+func commitGit() (string, bool) {
+	if info, ok := debug.ReadBuildInfo(); ok {
+		commit, modificado := "", false
+		for _, s := range info.Settings {
+			switch s.Key {
+			case "vcs.revision":
+				commit = s.Value
+			case "vcs.modified":
+				modificado = s.Value == "true"
+			}
+		}
+		if commit != "" {
+			return commit, modificado
+		}
+	}
+	out, err := exec.Command("git", "rev-parse", "HEAD").Output()
+	if err != nil {
+		return "", false
+	}
+	estado, err := exec.Command("git", "status", "--porcelain", "--untracked-files=no").Output()
+	return strings.TrimSpace(string(out)), err == nil && len(strings.TrimSpace(string(estado))) > 0
+}
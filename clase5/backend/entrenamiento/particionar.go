@@ -0,0 +1,155 @@
+package entrenamiento
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"unmatch/backend/algorithms"
+	"unmatch/backend/dataset"
+)
+
+// ProporcionesPorDefecto son las de softmaxctl train: 70% entrenamiento,
+// 15% validacion y 15% prueba
+var ProporcionesPorDefecto = dataset.Proporciones{Validacion: 0.15, Prueba: 0.15}
+
+// MetricasParticion son las metricas de cada parte (validacion y prueba
+// solo si tienen filas)
+type MetricasParticion struct {
+	Entrenamiento Metricas  `json:"entrenamiento"`
+	Validacion    *Metricas `json:"validacion,omitempty"`
+	Prueba        *Metricas `json:"prueba,omitempty"`
+}
+
+// Resultado es un entrenamiento con sus partes ya preparadas para el
+// modelo (Validacion y Prueba son nil si no tienen filas)
+type Resultado struct {
+	Modelo        *algorithms.SoftmaxRegression
+	Particion     dataset.Particion
+	Entrenamiento *dataset.Dataset
+	Validacion    *dataset.Dataset
+	Prueba        *dataset.Dataset
+	// PerdidaValidacion es la perdida sobre validacion en cada iteracion
+	// (alineada con Modelo.LossHistory)
+	PerdidaValidacion []float64
+	Metricas          MetricasParticion
+	muestras          int // filas del dataset completo
+	// datos y streaming solo en EntrenarStreaming, que no tiene los
+	// datasets de cada parte
+	datos     *DatasetManifiesto
+	streaming *OpcionesStreaming
+}
+
+// EntrenarParticionado separa d en entrenamiento, validacion y prueba
+// (estratificado, ver dataset.Particionar), entrena con la primera
+// (Entrenar, asi la imputacion solo mira esas filas), sigue la perdida
+// de validacion en cada iteracion y mide las tres partes. La seed de
+// hp (elegida al azar si es 0) sortea la particion y los pesos.
+func EntrenarParticionado(d *dataset.Dataset, hp Hiperparametros, prop dataset.Proporciones) (*Resultado, error) {
+	if err := hp.Validar(); err != nil {
+		return nil, err
+	}
+	if d.Y == nil {
+		return nil, fmt.Errorf("%w: falta la columna de etiqueta '%s'", dataset.ErrDatasetInvalido, d.Label)
+	}
+	if hp.Seed == 0 {
+		hp.Seed = time.Now().UnixNano()
+	}
+	part, err := dataset.Particionar(d.Y, prop, hp.Seed)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &Resultado{Particion: part, Entrenamiento: d.Subconjunto(part.Entrenamiento), muestras: d.Muestras()}
+	if len(part.Validacion) > 0 {
+		res.Validacion = d.Subconjunto(part.Validacion)
+	}
+	if len(part.Prueba) > 0 {
+		res.Prueba = d.Subconjunto(part.Prueba)
+	}
+
+	res.Modelo, err = entrenar(res.Entrenamiento, hp, func(model *algorithms.SoftmaxRegression) error {
+		if res.Validacion == nil {
+			return nil
+		}
+		// mismas columnas que entrenamiento: alcanza con imputar
+		val := res.Validacion
+		X, features, err := Imputar(val.X, val.Features, model.Imputation)
+		if err != nil {
+			return err
+		}
+		val.X, val.Features = X, features
+		if faltan := val.FeaturesConFaltantes(); len(faltan) > 0 {
+			return fmt.Errorf("%w: faltan valores de %s en validacion y el modelo no los imputa", dataset.ErrDatasetInvalido, strings.Join(faltan, ", "))
+		}
+		model.OnIteration = func(int) {
+			res.PerdidaValidacion = append(res.PerdidaValidacion, model.Loss(val.X, val.Y))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	res.Metricas.Entrenamiento = Medir(res.Modelo, res.Entrenamiento.X, res.Entrenamiento.Y)
+	if res.Validacion != nil {
+		met := Medir(res.Modelo, res.Validacion.X, res.Validacion.Y)
+		res.Metricas.Validacion = &met
+	}
+	if res.Prueba != nil {
+		if err := Preparar(res.Modelo, res.Prueba); err != nil {
+			return nil, err
+		}
+		met := Medir(res.Modelo, res.Prueba.X, res.Prueba.Y)
+		res.Metricas.Prueba = &met
+	}
+	return res, nil
+}
+
+// ResultadoSinParticion envuelve un modelo entrenado con todo d (ya
+// preparado), como el de POST /softmax/train
+func ResultadoSinParticion(model *algorithms.SoftmaxRegression, d *dataset.Dataset) *Resultado {
+	todas := make([]int, d.Muestras())
+	for i := range todas {
+		todas[i] = i
+	}
+	return &Resultado{
+		Modelo:        model,
+		Particion:     dataset.Particion{Seed: model.Seed, Entrenamiento: todas, Validacion: []int{}, Prueba: []int{}},
+		Entrenamiento: d,
+		Metricas:      MetricasParticion{Entrenamiento: Medir(model, d.X, d.Y)},
+		muestras:      d.Muestras(),
+	}
+}
+
+// Evaluacion devuelve las metricas que resumen el modelo: las de prueba
+// si hay, si no las de validacion y si no las de entrenamiento, con el
+// nombre de la parte.
+func (r *Resultado) Evaluacion() (Metricas, string) {
+	switch {
+	case r.Metricas.Prueba != nil:
+		return *r.Metricas.Prueba, "prueba"
+	case r.Metricas.Validacion != nil:
+		return *r.Metricas.Validacion, "validacion"
+	}
+	return r.Metricas.Entrenamiento, "entrenamiento"
+}
+
+// Manifiesto describe el entrenamiento (ver NuevoManifiesto) con la
+// particion y las metricas de cada parte
+func (r *Resultado) Manifiesto(modelPath, origen string, comando []string) Manifiesto {
+	var m Manifiesto
+	if r.datos != nil {
+		datos := *r.datos
+		datos.Origen = origen
+		m = nuevoManifiesto(modelPath, datos, r.Modelo, comando)
+		m.Accuracy = r.Metricas.Entrenamiento.Accuracy
+		m.Streaming = r.streaming
+	} else {
+		m = NuevoManifiesto(modelPath, origen, r.Entrenamiento, r.Modelo, comando)
+	}
+	m.Dataset.Muestras = r.muestras
+	part, met := r.Particion, r.Metricas
+	m.Particion, m.Metricas = &part, &met
+	return m
+}
@@ -0,0 +1,108 @@
+package entrenamiento
+
+import (
+	"reflect"
+	"testing"
+
+	"gonum.org/v1/gonum/mat"
+
+	"unmatch/backend/dataset"
+)
+
+// datasetDeClases arma un dataset de 2 features con tamanos[c] filas de
+// la clase c, alrededor del punto (c, c)
+func datasetDeClases(tamanos ...int) *dataset.Dataset {
+	data := []float64{}
+	y := []int{}
+	for c, n := range tamanos {
+		for i := 0; i < n; i++ {
+			d := float64(i%5) * 0.05
+			data = append(data, float64(c)+d, float64(c)-d)
+			y = append(y, c)
+		}
+	}
+	return &dataset.Dataset{
+		Features: []string{"x1", "x2"},
+		Label:    "clase",
+		X:        mat.NewDense(len(y), 2, data),
+		Y:        y,
+		Textos:   map[string][]string{},
+	}
+}
+
+func TestEntrenarParticionado(t *testing.T) {
+	prop := dataset.Proporciones{Validacion: 0.2, Prueba: 0.2}
+	casos := []struct {
+		nombre        string
+		tamanos       []int
+		entrenamiento int
+		validacion    int
+		prueba        int
+	}{
+		{"clases iguales", []int{10, 10, 10}, 18, 6, 6},
+		{"una clase de 2 filas", []int{10, 10, 2}, 14, 4, 4},
+		{"una clase de 1 fila", []int{10, 10, 1}, 13, 4, 4},
+	}
+	for _, c := range casos {
+		t.Run(c.nombre, func(t *testing.T) {
+			hp := Hiperparametros{Lr: 0.1, NIter: 20, Seed: 5}
+			res, err := EntrenarParticionado(datasetDeClases(c.tamanos...), hp, prop)
+			if err != nil {
+				t.Fatal(err)
+			}
+			p := res.Particion
+			if got := [3]int{len(p.Entrenamiento), len(p.Validacion), len(p.Prueba)}; got != [3]int{c.entrenamiento, c.validacion, c.prueba} {
+				t.Errorf("entrenamiento/validacion/prueba = %v, se esperaba %v", got, [3]int{c.entrenamiento, c.validacion, c.prueba})
+			}
+			if res.Entrenamiento.Muestras() != len(p.Entrenamiento) {
+				t.Errorf("el dataset de entrenamiento tiene %d filas y la particion %d", res.Entrenamiento.Muestras(), len(p.Entrenamiento))
+			}
+			// cada clase entrena, asi el modelo tiene todas las salidas
+			vistas := map[int]bool{}
+			for _, yi := range res.Entrenamiento.Y {
+				vistas[yi] = true
+			}
+			if len(vistas) != len(c.tamanos) {
+				t.Errorf("entrenaron %d clases de %d", len(vistas), len(c.tamanos))
+			}
+			if _, k := res.Modelo.W.Dims(); k != len(c.tamanos) {
+				t.Errorf("el modelo tiene %d clases, se esperaban %d", k, len(c.tamanos))
+			}
+			if len(res.PerdidaValidacion) != hp.NIter {
+				t.Errorf("%d perdidas de validacion para %d iteraciones", len(res.PerdidaValidacion), hp.NIter)
+			}
+			if res.Metricas.Validacion == nil || res.Metricas.Prueba == nil {
+				t.Error("faltan las metricas de validacion o prueba")
+			}
+		})
+	}
+}
+
+func TestEntrenarParticionadoMismaSeed(t *testing.T) {
+	prop := dataset.Proporciones{Validacion: 0.15, Prueba: 0.15}
+	hp := Hiperparametros{Lr: 0.1, NIter: 20, Seed: 11}
+	a, err := EntrenarParticionado(datasetDeClases(12, 9, 6), hp, prop)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := EntrenarParticionado(datasetDeClases(12, 9, 6), hp, prop)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(a.Particion, b.Particion) {
+		t.Error("la misma seed dio particiones distintas")
+	}
+	if !mat.Equal(a.Modelo.W, b.Modelo.W) {
+		t.Error("la misma seed dio pesos distintos")
+	}
+}
+
+func TestEntrenarParticionadoEligeSeed(t *testing.T) {
+	res, err := EntrenarParticionado(datasetDeClases(5, 5), Hiperparametros{Lr: 0.1, NIter: 5}, dataset.Proporciones{Prueba: 0.2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Particion.Seed == 0 || res.Particion.Seed != res.Modelo.Seed {
+		t.Errorf("seed de la particion %d y del modelo %d", res.Particion.Seed, res.Modelo.Seed)
+	}
+}
@@ -0,0 +1,253 @@
+package entrenamiento
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"gonum.org/v1/gonum/mat"
+
+	"unmatch/backend/algorithms"
+	"unmatch/backend/parquet"
+)
+
+// Formatos de ExportarPredicciones (la extension del archivo)
+const (
+	FormatoCSV     = "csv"
+	FormatoJSONL   = "jsonl"
+	FormatoParquet = "parquet"
+)
+
+// FormatosPredicciones son los formatos que acepta ExportarPredicciones
+var FormatosPredicciones = []string{FormatoCSV, FormatoJSONL, FormatoParquet}
+
+// Predicciones son las filas de X con lo que predijo el modelo, para
+// exportar (por ejemplo las de prueba, o las de un dataset 2D para
+// graficar).
+type Predicciones struct {
+	Features []string   // nombre de cada columna de X
+	Clases   []string   // nombre de cada clase (vacio si el modelo no los tiene)
+	X        *mat.Dense // ya preparada para el modelo
+	Y        []int      // clase real (nil si no se conoce)
+	Probs    *mat.Dense
+}
+
+// NuevasPredicciones predice las filas de X (ya preparada) con el modelo;
+// y puede ser nil
+func NuevasPredicciones(model *algorithms.SoftmaxRegression, X *mat.Dense, y []int) Predicciones {
+	_, cols := X.Dims()
+	features := make([]string, cols)
+	for j := range features {
+		features[j] = NombreFeature(model, j)
+	}
+	p := Predicciones{Features: features, X: X, Y: y, Probs: model.PredictProba(X)}
+	if _, nClases := model.W.Dims(); len(model.Classes) == nClases {
+		p.Clases = model.Classes
+	}
+	return p
+}
+
+// nombreClase devuelve el nombre de la clase k ("" si no lo hay)
+func (p Predicciones) nombreClase(k int) string {
+	if k >= 0 && k < len(p.Clases) {
+		return p.Clases[k]
+	}
+	return ""
+}
+
+// Columnas arma la tabla que se exporta, en este orden: las features con
+// su nombre, y_true y clase_true (si se conoce la clase real), y_pred y
+// clase_pred, la probabilidad de cada clase (p0, ..., pK), confianza (la
+// probabilidad de la clase predicha) y correcto (si se conoce la clase
+// real). Las columnas clase_* solo estan si el modelo tiene los nombres.
+func (p Predicciones) Columnas() []parquet.Columna {
+	filas, cols := p.X.Dims()
+	_, nClases := p.Probs.Dims()
+	conNombre := len(p.Clases) > 0
+
+	columnas := make([]parquet.Columna, 0, cols+nClases+6)
+	for j := 0; j < cols; j++ {
+		columnas = append(columnas, parquet.Columna{Nombre: p.Features[j], Tipo: parquet.Real, Reales: mat.Col(nil, j, p.X)})
+	}
+	pred := make([]int64, filas)
+	confianza := make([]float64, filas)
+	for i := 0; i < filas; i++ {
+		pRow := p.Probs.RawRowView(i)
+		k := argmax(pRow)
+		pred[i], confianza[i] = int64(k), pRow[k]
+	}
+	if p.Y != nil {
+		reales := make([]int64, filas)
+		nombres := make([]string, filas)
+		for i, yi := range p.Y {
+			reales[i], nombres[i] = int64(yi), p.nombreClase(yi)
+		}
+		columnas = append(columnas, parquet.Columna{Nombre: "y_true", Tipo: parquet.Entero, Enteros: reales})
+		if conNombre {
+			columnas = append(columnas, parquet.Columna{Nombre: "clase_true", Tipo: parquet.Texto, Textos: nombres})
+		}
+	}
+	columnas = append(columnas, parquet.Columna{Nombre: "y_pred", Tipo: parquet.Entero, Enteros: pred})
+	if conNombre {
+		nombres := make([]string, filas)
+		for i, k := range pred {
+			nombres[i] = p.nombreClase(int(k))
+		}
+		columnas = append(columnas, parquet.Columna{Nombre: "clase_pred", Tipo: parquet.Texto, Textos: nombres})
+	}
+	for k := 0; k < nClases; k++ {
+		columnas = append(columnas, parquet.Columna{Nombre: fmt.Sprintf("p%d", k), Tipo: parquet.Real, Reales: mat.Col(nil, k, p.Probs)})
+	}
+	columnas = append(columnas, parquet.Columna{Nombre: "confianza", Tipo: parquet.Real, Reales: confianza})
+	if p.Y != nil {
+		correcto := make([]bool, filas)
+		for i, yi := range p.Y {
+			correcto[i] = int64(yi) == pred[i]
+		}
+		columnas = append(columnas, parquet.Columna{Nombre: "correcto", Tipo: parquet.Booleano, Booleanos: correcto})
+	}
+	return columnas
+}
+
+// FormatoPredicciones devuelve el formato de path segun su extension
+func FormatoPredicciones(path string) (string, error) {
+	formato := strings.ToLower(strings.TrimPrefix(filepath.Ext(path), "."))
+	for _, f := range FormatosPredicciones {
+		if formato == f {
+			return formato, nil
+		}
+	}
+	return "", fmt.Errorf("'%s' debe terminar en .csv, .jsonl o .parquet", path)
+}
+
+// ExportarPredicciones escribe p en path, en CSV, JSON Lines o Parquet
+// segun la extension (.csv, .jsonl, .parquet). Ver Columnas.
+func ExportarPredicciones(path string, p Predicciones) error {
+	formato, err := FormatoPredicciones(path)
+	if err != nil {
+		return err
+	}
+	if rows, _ := p.X.Dims(); rows == 0 {
+		return fmt.Errorf("ExportarPredicciones: no hay filas")
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if err := p.Escribir(f, formato); err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+// Escribir escribe p en el formato pedido
+func (p Predicciones) Escribir(out io.Writer, formato string) error {
+	columnas := p.Columnas()
+	switch formato {
+	case FormatoCSV:
+		return escribirTablaCSV(out, columnas)
+	case FormatoJSONL:
+		return escribirTablaJSONL(out, columnas)
+	case FormatoParquet:
+		return parquet.Escribir(out, columnas)
+	}
+	return fmt.Errorf("formato '%s' no existe, use %s", formato, strings.Join(FormatosPredicciones, ", "))
+}
+
+// valorTexto es el valor de la fila i como texto de CSV
+func valorTexto(c parquet.Columna, i int) string {
+	switch c.Tipo {
+	case parquet.Booleano:
+		return strconv.FormatBool(c.Booleanos[i])
+	case parquet.Entero:
+		return strconv.FormatInt(c.Enteros[i], 10)
+	case parquet.Real:
+		return strconv.FormatFloat(c.Reales[i], 'g', -1, 64)
+	}
+	return c.Textos[i]
+}
+
+// valorJSON es el valor de la fila i para JSON (NaN e Inf van como null)
+func valorJSON(c parquet.Columna, i int) interface{} {
+	switch c.Tipo {
+	case parquet.Booleano:
+		return c.Booleanos[i]
+	case parquet.Entero:
+		return c.Enteros[i]
+	case parquet.Real:
+		if v := c.Reales[i]; !math.IsNaN(v) && !math.IsInf(v, 0) {
+			return v
+		}
+		return nil
+	}
+	return c.Textos[i]
+}
+
+// filasTabla es la cantidad de filas de la tabla
+func filasTabla(columnas []parquet.Columna) int {
+	if len(columnas) == 0 {
+		return 0
+	}
+	return columnas[0].Filas()
+}
+
+func escribirTablaCSV(out io.Writer, columnas []parquet.Columna) error {
+	w := csv.NewWriter(out)
+	record := make([]string, len(columnas))
+	for j, c := range columnas {
+		record[j] = c.Nombre
+	}
+	if err := w.Write(record); err != nil {
+		return err
+	}
+	for i := 0; i < filasTabla(columnas); i++ {
+		for j, c := range columnas {
+			record[j] = valorTexto(c, i)
+		}
+		if err := w.Write(record); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
+
+// escribirTablaJSONL escribe un objeto por fila con las claves en el
+// orden de las columnas
+func escribirTablaJSONL(out io.Writer, columnas []parquet.Columna) error {
+	w := bufio.NewWriter(out)
+	claves := make([][]byte, len(columnas))
+	for j, c := range columnas {
+		clave, err := json.Marshal(c.Nombre)
+		if err != nil {
+			return err
+		}
+		claves[j] = clave
+	}
+	for i := 0; i < filasTabla(columnas); i++ {
+		w.WriteByte('{')
+		for j, c := range columnas {
+			if j > 0 {
+				w.WriteByte(',')
+			}
+			valor, err := json.Marshal(valorJSON(c, i))
+			if err != nil {
+				return err
+			}
+			w.Write(claves[j])
+			w.WriteByte(':')
+			w.Write(valor)
+		}
+		w.WriteString("}\n")
+	}
+	return w.Flush()
+}
@@ -0,0 +1,294 @@
+package entrenamiento
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"time"
+
+	"gonum.org/v1/gonum/mat"
+
+	"unmatch/backend/algorithms"
+	"unmatch/backend/dataset"
+)
+
+// OpcionesStreaming de EntrenarStreaming
+type OpcionesStreaming struct {
+	Lote   int `json:"lote"`   // filas por paso de gradiente
+	Epocas int `json:"epocas"` // pasadas por las filas de entrenamiento
+	Buffer int `json:"buffer"` // filas del buffer de mezcla
+}
+
+// StreamingPorDefecto son las de softmaxctl train --streaming
+var StreamingPorDefecto = OpcionesStreaming{Lote: 256, Epocas: 10, Buffer: 10000}
+
+// Validar revisa que las opciones tengan sentido
+func (op OpcionesStreaming) Validar() error {
+	switch {
+	case op.Lote <= 0:
+		return fmt.Errorf("el lote debe tener al menos una fila (%d)", op.Lote)
+	case op.Epocas <= 0:
+		return fmt.Errorf("las epocas deben ser mayores a 0 (%d)", op.Epocas)
+	case op.Buffer < op.Lote:
+		return fmt.Errorf("el buffer de mezcla (%d) no puede ser menor que el lote (%d)", op.Buffer, op.Lote)
+	}
+	return nil
+}
+
+// EntrenarStreaming entrena sin cargar f en memoria: en cada epoca pasa
+// por las filas de entrenamiento mezcladas con un buffer (ver
+// dataset.Mezclar) y da un paso de gradiente por lote (PartialFit). La
+// memoria depende del lote y del buffer, no del archivo.
+//
+// Las partes se sortean fila por fila (dataset.Proporciones.ParteDeFila),
+// asi que no son estratificadas y sus tamanos son aproximados. La
+// imputacion se ajusta con una pasada por las filas de entrenamiento y
+// solo admite media, constante e indicador. La seed de hp (elegida al
+// azar si es 0) sortea las partes, la mezcla y los pesos; hp.NIter se
+// reemplaza por las epocas, y LossHistory y PerdidaValidacion tienen un
+// valor por epoca. El Resultado no tiene los datasets de cada parte.
+func EntrenarStreaming(f *dataset.FuenteArchivo, hp Hiperparametros, op OpcionesStreaming, prop dataset.Proporciones) (*Resultado, error) {
+	hp.NIter = op.Epocas
+	if err := hp.Validar(); err != nil {
+		return nil, err
+	}
+	if err := op.Validar(); err != nil {
+		return nil, err
+	}
+	if err := prop.Validar(); err != nil {
+		return nil, err
+	}
+	if f.Label() == "" {
+		return nil, fmt.Errorf("%w: el esquema no tiene columna de etiqueta", dataset.ErrDatasetInvalido)
+	}
+	if hp.Seed == 0 {
+		hp.Seed = time.Now().UnixNano()
+	}
+	resumen := f.Resumen()
+	parte := func(nombre string) func(int) bool {
+		return func(fila int) bool { return prop.ParteDeFila(hp.Seed, fila) == nombre }
+	}
+	entrenamiento := dataset.Filtrar(f, parte(dataset.ParteEntrenamiento))
+	validacion := dataset.Filtrar(f, parte(dataset.ParteValidacion))
+
+	imps, err := ajustarImputacionFuente(entrenamiento, f.Esquema(), op.Lote)
+	if err != nil {
+		return nil, err
+	}
+	preparar := func(lote *dataset.Lote) (*mat.Dense, error) {
+		X, features, err := Imputar(lote.X, f.Features(), imps)
+		if err != nil {
+			return nil, err
+		}
+		return X, revisarLote(X, features, lote.Filas)
+	}
+
+	model := algorithms.NewSoftmaxRegression(hp.Lr, hp.NIter, hp.RegLambda)
+	model.Seed = hp.Seed
+	model.FeatureNames = append([]string(nil), f.Features()...)
+	for _, imp := range imps {
+		if imp.Indicator != "" {
+			model.FeatureNames = append(model.FeatureNames, imp.Indicator)
+		}
+	}
+	model.Label = f.Label()
+	model.Imputation = imps
+
+	res := &Resultado{
+		Modelo:    model,
+		Particion: dataset.Particion{Seed: hp.Seed, Proporciones: prop, Entrenamiento: []int{}, Validacion: []int{}, Prueba: []int{}, PorFila: true},
+		muestras:  resumen.Muestras,
+		datos:     &DatasetManifiesto{SHA256: resumen.SHA256, Muestras: resumen.Muestras},
+		streaming: &op,
+	}
+	if esq := f.Esquema(); esq.Etiqueta.Columna != "" {
+		res.datos.Esquema = &esq
+	}
+
+	mezcla := dataset.Mezclar(entrenamiento, op.Buffer, hp.Seed)
+	for epoca := 0; epoca < op.Epocas; epoca++ {
+		perdida, filas, err := recorrer(mezcla, op.Lote, func(lote *dataset.Lote) (float64, error) {
+			X, err := preparar(lote)
+			if err != nil {
+				return 0, err
+			}
+			return model.PartialFit(X, lote.Y, resumen.Clases), nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		if filas == 0 {
+			return nil, fmt.Errorf("%w: no quedaron filas para entrenar", dataset.ErrDatasetInvalido)
+		}
+		model.LossHistory = append(model.LossHistory, perdida)
+
+		perdida, filas, err = recorrer(validacion, op.Lote, func(lote *dataset.Lote) (float64, error) {
+			X, err := preparar(lote)
+			if err != nil {
+				return 0, err
+			}
+			return model.Loss(X, lote.Y), nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		if filas > 0 {
+			res.PerdidaValidacion = append(res.PerdidaValidacion, perdida)
+		}
+	}
+	// como en Entrenar, los nombres de clases que no aparecen no entran
+	if clases := f.Clases(); len(clases) >= resumen.Clases {
+		model.Classes = append([]string(nil), clases[:resumen.Clases]...)
+	}
+
+	if err := medirPartes(res, f, op.Lote, prop, preparar); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// recorrer pasa por todos los lotes de f y devuelve el promedio de lo que
+// devuelve paso, pesado por las filas de cada lote, y la cantidad de filas
+func recorrer(f dataset.Fuente, tam int, paso func(*dataset.Lote) (float64, error)) (float64, int, error) {
+	if err := f.Reiniciar(); err != nil {
+		return 0, 0, err
+	}
+	suma, filas := 0.0, 0
+	for {
+		lote, err := f.Leer(tam)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return 0, 0, err
+		}
+		v, err := paso(lote)
+		if err != nil {
+			return 0, 0, err
+		}
+		suma += v * float64(lote.Muestras())
+		filas += lote.Muestras()
+	}
+	if filas == 0 {
+		return 0, 0, nil
+	}
+	return suma / float64(filas), filas, nil
+}
+
+// medirPartes calcula las metricas de cada parte en una sola pasada
+func medirPartes(res *Resultado, f dataset.Fuente, tam int, prop dataset.Proporciones, preparar func(*dataset.Lote) (*mat.Dense, error)) error {
+	mediciones := map[string]*medicion{}
+	for _, nombre := range []string{dataset.ParteEntrenamiento, dataset.ParteValidacion, dataset.PartePrueba} {
+		mediciones[nombre] = nuevaMedicion(res.Modelo)
+	}
+	_, _, err := recorrer(f, tam, func(lote *dataset.Lote) (float64, error) {
+		X, err := preparar(lote)
+		if err != nil {
+			return 0, err
+		}
+		porParte := map[string][]int{}
+		for i, fila := range lote.Filas {
+			nombre := prop.ParteDeFila(res.Particion.Seed, fila)
+			porParte[nombre] = append(porParte[nombre], i)
+		}
+		for nombre, idx := range porParte {
+			_, cols := X.Dims()
+			sub := mat.NewDense(len(idx), cols, nil)
+			y := make([]int, len(idx))
+			for i, r := range idx {
+				sub.SetRow(i, X.RawRowView(r))
+				y[i] = lote.Y[r]
+			}
+			mediciones[nombre].agregar(sub, y)
+		}
+		return 0, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	res.Metricas.Entrenamiento = mediciones[dataset.ParteEntrenamiento].metricas()
+	if m := mediciones[dataset.ParteValidacion]; m.muestras > 0 {
+		met := m.metricas()
+		res.Metricas.Validacion = &met
+	}
+	if m := mediciones[dataset.PartePrueba]; m.muestras > 0 {
+		met := m.metricas()
+		res.Metricas.Prueba = &met
+	}
+	return nil
+}
+
+// ajustarImputacionFuente es AjustarImputacion sobre las filas de f,
+// sumando de a lotes. La mediana y la moda necesitan todos los valores a
+// la vez y no se pueden usar.
+func ajustarImputacionFuente(f dataset.Fuente, esq dataset.Esquema, tam int) ([]algorithms.Imputation, error) {
+	features := f.Features()
+	out := []algorithms.Imputation{}
+	columnas := []int{}
+	for j, feat := range features {
+		imp := esq.Imputacion.Para(feat)
+		if imp.Estrategia == "" || strings.Contains(feat, "=") {
+			continue
+		}
+		switch imp.Estrategia {
+		case dataset.ImputarMediana, dataset.ImputarModa:
+			return nil, fmt.Errorf("%w: la imputacion por %s de %s no se puede calcular de a lotes, use media o constante", dataset.ErrDatasetInvalido, imp.Estrategia, feat)
+		}
+		ajuste := algorithms.Imputation{Feature: feat, Strategy: imp.Estrategia, Value: imp.Valor}
+		if imp.Indicador || imp.Estrategia == dataset.ImputarIndicador {
+			ajuste.Indicator = feat + SufijoIndicador
+		}
+		out = append(out, ajuste)
+		columnas = append(columnas, j)
+	}
+
+	suma := make([]float64, len(out))
+	cuenta := make([]int, len(out))
+	_, filas, err := recorrer(f, tam, func(lote *dataset.Lote) (float64, error) {
+		for i := 0; i < lote.Muestras(); i++ {
+			fila := lote.X.RawRowView(i)
+			for k, j := range columnas {
+				if v := fila[j]; !math.IsNaN(v) && !math.IsInf(v, 0) {
+					suma[k] += v
+					cuenta[k]++
+				}
+			}
+		}
+		return 0, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if filas == 0 {
+		return nil, fmt.Errorf("%w: no quedaron filas para entrenar", dataset.ErrDatasetInvalido)
+	}
+	for k := range out {
+		if out[k].Strategy != dataset.ImputarMedia {
+			continue
+		}
+		if cuenta[k] == 0 {
+			return nil, fmt.Errorf("%w: %s no tiene valores para calcular la %s", dataset.ErrDatasetInvalido, out[k].Feature, out[k].Strategy)
+		}
+		out[k].Value = suma[k] / float64(cuenta[k])
+	}
+	return out, nil
+}
+
+// revisarLote controla que a un lote ya imputado no le queden faltantes
+// ni infinitos (lo que dataset.Revisar controla con todo el dataset)
+func revisarLote(X *mat.Dense, features []string, filas []int) error {
+	rows, cols := X.Dims()
+	for i := 0; i < rows; i++ {
+		for j := 0; j < cols; j++ {
+			switch v := X.At(i, j); {
+			case math.IsNaN(v):
+				return fmt.Errorf("%w: falta %s en la fila de datos %d y el modelo no lo imputa", dataset.ErrDatasetInvalido, features[j], filas[i]+1)
+			case math.IsInf(v, 0):
+				return fmt.Errorf("%w: %s es infinito en la fila de datos %d", dataset.ErrDatasetInvalido, features[j], filas[i]+1)
+			}
+		}
+	}
+	return nil
+}
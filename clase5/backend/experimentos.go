@@ -0,0 +1,113 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/gofiber/fiber/v2"
+
+	"unmatch/backend/algorithms"
+	"unmatch/backend/entrenamiento"
+	"unmatch/backend/experimentos"
+)
+
+// registro guarda cada entrenamiento de la API (ver softmaxctl runs)
+var registro = experimentos.NuevoRegistro(experimentos.DefaultDir)
+
+// registrarSoftmax guarda el entrenamiento como experimento y lo promueve
+// al modelo de la API. Devuelve el id del experimento ("" si no se pudo
+// registrar; en ese caso el modelo se guarda directo) y el error si algo
+// no quedo en disco.
+func registrarSoftmax(res *entrenamiento.Resultado, manifiesto entrenamiento.Manifiesto) (string, error) {
+	exp, err := registro.Guardar(res, manifiesto, "")
+	if err != nil {
+		if errModelo := guardarSoftmax(res.Modelo, manifiesto); errModelo != nil {
+			return "", fmt.Errorf("no se pudo registrar el experimento (%v) ni guardar el modelo: %w", err, errModelo)
+		}
+		return "", fmt.Errorf("no se pudo registrar el experimento: %w", err)
+	}
+	if _, err := registro.Promover(exp.ID, softmaxModelPath); err != nil {
+		return exp.ID, fmt.Errorf("no se pudo promover el experimento %s: %w", exp.ID, err)
+	}
+	return exp.ID, nil
+}
+
+// errorRegistro responde cuando el modelo se entreno (y ya lo usa la API)
+// pero no quedo registrado como experimento; detalle dice que fallo
+func errorRegistro(c *fiber.Ctx, id string, seed int64, err error) error {
+	return c.Status(500).JSON(fiber.Map{
+		"error":       "El modelo se entreno pero no quedo registrado como experimento.",
+		"detalle":     err.Error(),
+		"experimento": id,
+		"seed":        seed,
+	})
+}
+
+// GET /experimentos
+func listarExperimentos(c *fiber.Ctx) error {
+	exps, err := registro.Listar()
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(exps)
+}
+
+// GET /experimentos/:id
+func obtenerExperimento(c *fiber.Ctx) error {
+	exp, err := registro.Cargar(c.Params("id"))
+	if err != nil {
+		return errorExperimento(c, err)
+	}
+	return c.JSON(exp)
+}
+
+// GET /experimentos/comparar?a=<id>&b=<id>
+func compararExperimentos(c *fiber.Ctx) error {
+	if c.Query("a") == "" || c.Query("b") == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "Indique los experimentos con ?a=<id>&b=<id>."})
+	}
+	a, err := registro.Cargar(c.Query("a"))
+	if err != nil {
+		return errorExperimento(c, err)
+	}
+	b, err := registro.Cargar(c.Query("b"))
+	if err != nil {
+		return errorExperimento(c, err)
+	}
+	return c.JSON(experimentos.Comparar(a, b))
+}
+
+// GET /experimentos/:id/archivos/:archivo
+//
+// Devuelve un archivo del experimento: params.json, perdida.csv,
+// perdida.svg, confusion.svg, puntos_regiones.svg, ...
+func archivoExperimento(c *fiber.Ctx) error {
+	path, err := registro.RutaArchivo(c.Params("id"), c.Params("archivo"))
+	if err != nil {
+		return errorExperimento(c, err)
+	}
+	return c.SendFile(path)
+}
+
+// POST /experimentos/:id/promover
+//
+// Copia el modelo del experimento al de la API y lo recarga.
+func promoverExperimento(c *fiber.Ctx) error {
+	exp, err := registro.Promover(c.Params("id"), softmaxModelPath)
+	if err != nil {
+		return errorExperimento(c, err)
+	}
+	model, err := algorithms.LoadSoftmaxRegression(softmaxModelPath)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	setModelo(model)
+	return c.JSON(fiber.Map{"mensaje": "Experimento promovido", "experimento": exp})
+}
+
+func errorExperimento(c *fiber.Ctx, err error) error {
+	if errors.Is(err, experimentos.ErrExperimentoNoEncontrado) {
+		return c.Status(404).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+}
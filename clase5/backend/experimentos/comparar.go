@@ -0,0 +1,90 @@
+package experimentos
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DiferenciaMetrica es una metrica de los dos experimentos
+type DiferenciaMetrica struct {
+	Metrica    string  `json:"metrica"`
+	A          float64 `json:"a"`
+	B          float64 `json:"b"`
+	Diferencia float64 `json:"diferencia"` // b - a
+	// Mejora dice si b es mejor que a (menor perdida, mayor el resto)
+	Mejora bool `json:"mejora"`
+}
+
+// Comparacion pone dos experimentos lado a lado
+type Comparacion struct {
+	A            Experimento          `json:"a"`
+	B            Experimento          `json:"b"`
+	MismoDataset bool                 `json:"mismo_dataset"`
+	Params       map[string][2]string `json:"params"` // solo los que cambian: param -> [a, b]
+	Metricas     []DiferenciaMetrica  `json:"metricas"`
+}
+
+// Comparar contrasta los parametros y las metricas de a y b
+func Comparar(a, b Experimento) Comparacion {
+	cmp := Comparacion{
+		A: a, B: b,
+		MismoDataset: a.Params.SHA256 == b.Params.SHA256,
+		Params:       map[string][2]string{},
+	}
+
+	pa, pb := a.Params, b.Params
+	params := []struct {
+		nombre string
+		a, b   string
+	}{
+		{"lr", fmt.Sprint(pa.Hiperparametros.Lr), fmt.Sprint(pb.Hiperparametros.Lr)},
+		{"n_iter", fmt.Sprint(pa.Hiperparametros.NIter), fmt.Sprint(pb.Hiperparametros.NIter)},
+		{"reg_lambda", fmt.Sprint(pa.Hiperparametros.RegLambda), fmt.Sprint(pb.Hiperparametros.RegLambda)},
+		{"seed", fmt.Sprint(pa.Hiperparametros.Seed), fmt.Sprint(pb.Hiperparametros.Seed)},
+		{"dataset", pa.Dataset, pb.Dataset},
+		{"sha256", pa.SHA256, pb.SHA256},
+		{"muestras", fmt.Sprint(pa.Muestras), fmt.Sprint(pb.Muestras)},
+		{"features", strings.Join(pa.Features, ","), strings.Join(pb.Features, ",")},
+		{"imputacion", resumenImputacion(pa), resumenImputacion(pb)},
+		{"validacion", fmt.Sprint(pa.Proporciones.Validacion), fmt.Sprint(pb.Proporciones.Validacion)},
+		{"prueba", fmt.Sprint(pa.Proporciones.Prueba), fmt.Sprint(pb.Proporciones.Prueba)},
+		{"evaluado", a.Evaluado, b.Evaluado},
+	}
+	for _, p := range params {
+		if p.a != p.b {
+			cmp.Params[p.nombre] = [2]string{p.a, p.b}
+		}
+	}
+
+	ma, mb := a.Metricas, b.Metricas
+	agregar := func(nombre string, va, vb float64, menorEsMejor bool) {
+		mejora := vb > va
+		if menorEsMejor {
+			mejora = vb < va
+		}
+		cmp.Metricas = append(cmp.Metricas, DiferenciaMetrica{Metrica: nombre, A: va, B: vb, Diferencia: vb - va, Mejora: mejora})
+	}
+	agregar("accuracy", ma.Accuracy, mb.Accuracy, false)
+	agregar("log_loss", ma.LogLoss, mb.LogLoss, true)
+	agregar("perdida_final", a.PerdidaFinal, b.PerdidaFinal, true)
+	for k := 0; k < max(len(ma.PorClase), len(mb.PorClase)); k++ {
+		var fa, fb float64
+		if k < len(ma.PorClase) {
+			fa = ma.PorClase[k].F1
+		}
+		if k < len(mb.PorClase) {
+			fb = mb.PorClase[k].F1
+		}
+		agregar(fmt.Sprintf("f1_clase_%d", k), fa, fb, false)
+	}
+	return cmp
+}
+
+// resumenImputacion describe la imputacion en una linea
+func resumenImputacion(p Params) string {
+	partes := []string{}
+	for _, imp := range p.Imputacion {
+		partes = append(partes, fmt.Sprintf("%s:%s=%g", imp.Feature, imp.Strategy, imp.Value))
+	}
+	return strings.Join(partes, ",")
+}
@@ -0,0 +1,368 @@
+// Package experimentos guarda cada entrenamiento del modelo softmax en
+// su propio directorio (parametros, metricas, curva de perdida, matriz
+// de confusion, sus graficos y modelo) para listarlos, compararlos y
+// promover uno al modelo que sirve la API.
+package experimentos
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"unmatch/backend/algorithms"
+	"unmatch/backend/dataset"
+	"unmatch/backend/entrenamiento"
+	"unmatch/backend/graficos"
+)
+
+// DefaultDir es donde softmaxctl y la API guardan los experimentos
+const DefaultDir = "./weights/experimentos"
+
+// Archivos de cada experimento
+const (
+	ArchivoParams    = "params.json"
+	ArchivoMetricas  = "metricas.json"
+	ArchivoPerdida   = "perdida.csv"
+	ArchivoConfusion = "confusion.csv" // de la parte evaluada (prueba si hay)
+	ArchivoPrueba    = "prueba.csv"    // predicciones sobre las filas de prueba
+	ArchivoModelo    = "modelo.json"   // su manifiesto va en modelo.manifiesto.json
+	archivoPromovido = "promovido.json"
+)
+
+// ErrExperimentoNoEncontrado: no hay un experimento con ese id
+var ErrExperimentoNoEncontrado = errors.New("experimento no encontrado")
+
+// formatoID es el de nuevoID: fecha-hora con sufijo opcional. Un id que
+// no lo cumple (., .., rutas) no es un experimento.
+var formatoID = regexp.MustCompile(`^[0-9]{8}-[0-9]{6}(-[0-9]+)?$`)
+
+// Params son los parametros del experimento
+type Params struct {
+	Hiperparametros entrenamiento.Hiperparametros `json:"hiperparametros"`
+	Dataset         string                        `json:"dataset"` // origen
+	SHA256          string                        `json:"sha256"`
+	Muestras        int                           `json:"muestras"`
+	Features        []string                      `json:"features"`
+	Imputacion      []algorithms.Imputation       `json:"imputacion,omitempty"`
+	Proporciones    dataset.Proporciones          `json:"proporciones"`
+	Nota            string                        `json:"nota,omitempty"`
+}
+
+// Experimento es un entrenamiento guardado. Metricas son las de la parte
+// Evaluado: prueba si la hubo, si no validacion o entrenamiento.
+type Experimento struct {
+	ID           string                          `json:"id"`
+	Fecha        time.Time                       `json:"fecha"`
+	Params       Params                          `json:"params"`
+	Metricas     entrenamiento.Metricas          `json:"metricas"`
+	Evaluado     string                          `json:"evaluado"`
+	Particion    entrenamiento.MetricasParticion `json:"particion"`
+	PerdidaFinal float64                         `json:"perdida_final"`
+	Promovido    bool                            `json:"promovido"`
+}
+
+// metricasArchivo es metricas.json
+type metricasArchivo struct {
+	Fecha        time.Time                       `json:"fecha"`
+	PerdidaFinal float64                         `json:"perdida_final"`
+	Metricas     entrenamiento.Metricas          `json:"metricas"`
+	Evaluado     string                          `json:"evaluado"`
+	Particion    entrenamiento.MetricasParticion `json:"particion"`
+}
+
+// promocion es promovido.json: que experimento sirve la API
+type promocion struct {
+	ID      string    `json:"id"`
+	Fecha   time.Time `json:"fecha"`
+	Destino string    `json:"destino"`
+}
+
+// Registro es el directorio con un subdirectorio por experimento
+type Registro struct {
+	Dir string
+	// Graficos es el formato (svg o png) de los graficos de cada
+	// experimento; vacio = sin graficos
+	Graficos string
+}
+
+// NuevoRegistro usa dir (DefaultDir si esta vacio) y graficos SVG
+func NuevoRegistro(dir string) Registro {
+	if dir == "" {
+		dir = DefaultDir
+	}
+	return Registro{Dir: dir, Graficos: graficos.FormatoSVG}
+}
+
+// RutaArchivo es un archivo del experimento id (params.json,
+// perdida.svg, ...)
+func (r Registro) RutaArchivo(id, nombre string) (string, error) {
+	if !formatoID.MatchString(id) || nombre == "" || nombre == "." || nombre == ".." || filepath.Base(nombre) != nombre {
+		return "", fmt.Errorf("%w: %s/%s", ErrExperimentoNoEncontrado, id, nombre)
+	}
+	path := filepath.Join(r.Dir, id, nombre)
+	if _, err := os.Stat(path); err != nil {
+		return "", fmt.Errorf("%w: %s/%s", ErrExperimentoNoEncontrado, id, nombre)
+	}
+	return path, nil
+}
+
+// RutaModelo es el modelo del experimento id
+func (r Registro) RutaModelo(id string) string {
+	return filepath.Join(r.Dir, id, ArchivoModelo)
+}
+
+// Guardar crea el directorio del experimento con el modelo de res, su
+// manifiesto, las metricas de cada parte, la curva de perdida (con la
+// de validacion), la matriz de confusion de la parte evaluada y las
+// predicciones de prueba, con sus graficos (y las regiones de decision
+// si el modelo tiene 2 features).
+func (r Registro) Guardar(res *entrenamiento.Resultado, manifiesto entrenamiento.Manifiesto, nota string) (Experimento, error) {
+	model := res.Modelo
+	met, evaluado := res.Evaluacion()
+	id, err := r.nuevoID(manifiesto.Fecha)
+	if err != nil {
+		return Experimento{}, err
+	}
+	dir := filepath.Join(r.Dir, id)
+
+	exp := Experimento{
+		ID:    id,
+		Fecha: manifiesto.Fecha,
+		Params: Params{
+			Hiperparametros: manifiesto.Hiperparametros,
+			Dataset:         manifiesto.Dataset.Origen,
+			SHA256:          manifiesto.Dataset.SHA256,
+			Muestras:        manifiesto.Dataset.Muestras,
+			Features:        model.FeatureNames,
+			Imputacion:      model.Imputation,
+			Proporciones:    res.Particion.Proporciones,
+			Nota:            nota,
+		},
+		Metricas:     met,
+		Evaluado:     evaluado,
+		Particion:    res.Metricas,
+		PerdidaFinal: manifiesto.PerdidaFinal,
+	}
+
+	manifiesto.Modelo = filepath.Join(dir, ArchivoModelo)
+	pasos := []func() error{
+		func() error { return model.SaveToFile(manifiesto.Modelo) },
+		func() error { return manifiesto.Guardar(entrenamiento.RutaManifiesto(manifiesto.Modelo)) },
+		func() error { return escribirJSON(filepath.Join(dir, ArchivoParams), exp.Params) },
+		func() error {
+			return escribirJSON(filepath.Join(dir, ArchivoMetricas), metricasArchivo{exp.Fecha, exp.PerdidaFinal, met, evaluado, res.Metricas})
+		},
+		func() error {
+			return entrenamiento.ExportarPerdidaCSV(filepath.Join(dir, ArchivoPerdida), model.LossHistory, res.PerdidaValidacion)
+		},
+		func() error { return escribirConfusion(filepath.Join(dir, ArchivoConfusion), met.Confusion) },
+	}
+	if res.Prueba != nil {
+		pasos = append(pasos, func() error {
+			return entrenamiento.ExportarPredicciones(filepath.Join(dir, ArchivoPrueba), entrenamiento.NuevasPredicciones(model, res.Prueba.X, res.Prueba.Y))
+		})
+	}
+	if f := r.Graficos; f != "" {
+		pasos = append(pasos,
+			func() error {
+				return entrenamiento.GraficarPerdida(entrenamiento.RutaGrafico(filepath.Join(dir, ArchivoPerdida), "", f), model.LossHistory, res.PerdidaValidacion)
+			},
+			func() error {
+				return graficos.MapaConfusion(entrenamiento.RutaGrafico(filepath.Join(dir, ArchivoConfusion), "", f), met.Confusion, model.Classes)
+			},
+		)
+		// entrenado de a lotes no hay filas en memoria para graficar
+		if res.Entrenamiento != nil {
+			pasos = append(pasos, func() error {
+				_, err := entrenamiento.GraficarPuntos(filepath.Join(dir, "puntos"), f, model, res.Entrenamiento.X, res.Entrenamiento.Y)
+				return err
+			})
+		}
+	}
+	for _, paso := range pasos {
+		if err := paso(); err != nil {
+			os.RemoveAll(dir)
+			return Experimento{}, err
+		}
+	}
+	return exp, nil
+}
+
+// nuevoID reserva un directorio fecha-hora (con sufijo si ya existe)
+func (r Registro) nuevoID(fecha time.Time) (string, error) {
+	if err := os.MkdirAll(r.Dir, 0o755); err != nil {
+		return "", err
+	}
+	base := fecha.UTC().Format("20060102-150405")
+	for n := 1; ; n++ {
+		id := base
+		if n > 1 {
+			id = fmt.Sprintf("%s-%d", base, n)
+		}
+		err := os.Mkdir(filepath.Join(r.Dir, id), 0o755)
+		if err == nil {
+			return id, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return "", err
+		}
+	}
+}
+
+// Cargar lee un experimento
+func (r Registro) Cargar(id string) (Experimento, error) {
+	if !formatoID.MatchString(id) {
+		return Experimento{}, fmt.Errorf("%w: %s", ErrExperimentoNoEncontrado, id)
+	}
+	dir := filepath.Join(r.Dir, id)
+	exp := Experimento{ID: id}
+	if err := leerJSON(filepath.Join(dir, ArchivoParams), &exp.Params); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return exp, fmt.Errorf("%w: %s", ErrExperimentoNoEncontrado, id)
+		}
+		return exp, err
+	}
+	var met metricasArchivo
+	if err := leerJSON(filepath.Join(dir, ArchivoMetricas), &met); err != nil {
+		return exp, err
+	}
+	exp.Fecha, exp.Metricas, exp.PerdidaFinal = met.Fecha, met.Metricas, met.PerdidaFinal
+	exp.Evaluado, exp.Particion = met.Evaluado, met.Particion
+	if exp.Evaluado == "" {
+		// experimentos de antes de la particion: todo era entrenamiento
+		exp.Evaluado, exp.Particion.Entrenamiento = "entrenamiento", met.Metricas
+	}
+	exp.Promovido = r.promovido() == id
+	return exp, nil
+}
+
+// Listar devuelve los experimentos del mas nuevo al mas viejo. Los
+// directorios incompletos se saltean.
+func (r Registro) Listar() ([]Experimento, error) {
+	entradas, err := os.ReadDir(r.Dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return []Experimento{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	out := []Experimento{}
+	for _, e := range entradas {
+		if !e.IsDir() {
+			continue
+		}
+		exp, err := r.Cargar(e.Name())
+		if err != nil {
+			continue
+		}
+		out = append(out, exp)
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		if !out[i].Fecha.Equal(out[j].Fecha) {
+			return out[i].Fecha.After(out[j].Fecha)
+		}
+		// mismo segundo: el sufijo mas largo (-10 despues de -9) es el mas nuevo
+		if len(out[i].ID) != len(out[j].ID) {
+			return len(out[i].ID) > len(out[j].ID)
+		}
+		return out[i].ID > out[j].ID
+	})
+	return out, nil
+}
+
+// Promover copia el modelo del experimento (y su manifiesto) a destino,
+// el modelo que sirve la API, y lo anota como promovido.
+func (r Registro) Promover(id, destino string) (Experimento, error) {
+	exp, err := r.Cargar(id)
+	if err != nil {
+		return exp, err
+	}
+	origen := r.RutaModelo(id)
+	if _, err := algorithms.LoadSoftmaxRegression(origen); err != nil {
+		return exp, fmt.Errorf("el modelo del experimento %s no se puede cargar: %w", id, err)
+	}
+	if err := os.MkdirAll(filepath.Dir(destino), 0o755); err != nil {
+		return exp, err
+	}
+	if err := copiar(origen, destino); err != nil {
+		return exp, err
+	}
+	if err := copiar(entrenamiento.RutaManifiesto(origen), entrenamiento.RutaManifiesto(destino)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return exp, err
+	}
+	if err := escribirJSON(filepath.Join(r.Dir, archivoPromovido), promocion{ID: id, Fecha: time.Now().UTC().Truncate(time.Second), Destino: destino}); err != nil {
+		return exp, err
+	}
+	exp.Promovido = true
+	return exp, nil
+}
+
+// promovido devuelve el id del experimento promovido ("" si ninguno)
+func (r Registro) promovido() string {
+	var p promocion
+	if err := leerJSON(filepath.Join(r.Dir, archivoPromovido), &p); err != nil {
+		return ""
+	}
+	return p.ID
+}
+
+func escribirJSON(path string, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+func leerJSON(path string, v interface{}) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// escribirConfusion guarda la matriz con una fila por clase real
+// Formato columnas: real, pred_0, ..., pred_K
+func escribirConfusion(path string, confusion [][]int) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	w := csv.NewWriter(f)
+	header := []string{"real"}
+	for k := range confusion {
+		header = append(header, "pred_"+strconv.Itoa(k))
+	}
+	if err := w.Write(header); err != nil {
+		return err
+	}
+	for real, fila := range confusion {
+		record := []string{strconv.Itoa(real)}
+		for _, n := range fila {
+			record = append(record, strconv.Itoa(n))
+		}
+		if err := w.Write(record); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
+
+func copiar(origen, destino string) error {
+	data, err := os.ReadFile(origen)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(destino, data, 0o644)
+}
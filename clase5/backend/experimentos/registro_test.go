@@ -0,0 +1,52 @@
+package experimentos
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestIDInvalido(t *testing.T) {
+	// el registro vive dentro de otro directorio con un params.json: con
+	// ".." se leeria ese
+	raiz := t.TempDir()
+	if err := os.WriteFile(filepath.Join(raiz, ArchivoParams), []byte("{}"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	r := NuevoRegistro(filepath.Join(raiz, "experimentos"))
+	if err := os.MkdirAll(r.Dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, id := range []string{"", ".", "..", "../experimentos", "20261018-185607/..", "abc", "20261018-185607-", "20261018-1856"} {
+		if _, err := r.Cargar(id); !errors.Is(err, ErrExperimentoNoEncontrado) {
+			t.Errorf("Cargar(%q) = %v, se esperaba ErrExperimentoNoEncontrado", id, err)
+		}
+		if _, err := r.RutaArchivo(id, ArchivoParams); !errors.Is(err, ErrExperimentoNoEncontrado) {
+			t.Errorf("RutaArchivo(%q) = %v, se esperaba ErrExperimentoNoEncontrado", id, err)
+		}
+	}
+}
+
+func TestRutaArchivo(t *testing.T) {
+	r := NuevoRegistro(t.TempDir())
+	for _, id := range []string{"20261018-185607", "20261018-185607-2"} {
+		dir := filepath.Join(r.Dir, id)
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, ArchivoParams), []byte("{}"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		path, err := r.RutaArchivo(id, ArchivoParams)
+		if err != nil || path != filepath.Join(dir, ArchivoParams) {
+			t.Errorf("RutaArchivo(%q) = %q, %v", id, path, err)
+		}
+		for _, nombre := range []string{"", ".", "..", "../" + ArchivoParams, ArchivoMetricas} {
+			if _, err := r.RutaArchivo(id, nombre); !errors.Is(err, ErrExperimentoNoEncontrado) {
+				t.Errorf("RutaArchivo(%q, %q) = %v, se esperaba ErrExperimentoNoEncontrado", id, nombre, err)
+			}
+		}
+	}
+}
@@ -2,7 +2,11 @@ module unmatch/backend
 
 go 1.23.0
 
-require github.com/mndrix/golog v0.0.0-20170330170653-a28e2a269775
+require (
+	github.com/mndrix/golog v0.0.0-20170330170653-a28e2a269775
+	golang.org/x/image v0.25.0
+	gopkg.in/yaml.v3 v3.0.1
+)
 
 require (
 	github.com/google/uuid v1.6.0 // indirect
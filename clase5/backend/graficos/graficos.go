@@ -0,0 +1,360 @@
+package graficos
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"strconv"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// Tamano de todos los graficos, en pixeles
+const (
+	Ancho = 640
+	Alto  = 480
+)
+
+// celdas por lado de la rejilla de los mapas 2D
+const celdas = 80
+
+var (
+	negro  = color.RGBA{0x22, 0x22, 0x22, 0xff}
+	gris   = color.RGBA{0x99, 0x99, 0x99, 0xff}
+	blanco = color.RGBA{0xff, 0xff, 0xff, 0xff}
+	azul   = color.RGBA{0x1f, 0x4e, 0x9a, 0xff}
+)
+
+// paleta es el color de cada clase (se repite si hay mas clases)
+var paleta = []color.RGBA{
+	{0x1f, 0x77, 0xb4, 0xff}, {0xff, 0x7f, 0x0e, 0xff}, {0x2c, 0xa0, 0x2c, 0xff},
+	{0xd6, 0x27, 0x28, 0xff}, {0x94, 0x67, 0xbd, 0xff}, {0x8c, 0x56, 0x4b, 0xff},
+	{0xe3, 0x77, 0xc2, 0xff}, {0x7f, 0x7f, 0x7f, 0xff}, {0xbc, 0xbd, 0x22, 0xff},
+	{0x17, 0xbe, 0xcf, 0xff},
+}
+
+func colorClase(k int) color.RGBA {
+	return paleta[k%len(paleta)]
+}
+
+// mezclar va de a (t = 0) a b (t = 1)
+func mezclar(a, b color.RGBA, t float64) color.RGBA {
+	t = math.Max(0, math.Min(1, t))
+	c := func(x, y uint8) uint8 { return uint8(math.Round(float64(x) + t*(float64(y)-float64(x)))) }
+	return color.RGBA{c(a.R, b.R), c(a.G, b.G), c(a.B, b.B), 0xff}
+}
+
+func nombreClase(clases []string, k int) string {
+	if k >= 0 && k < len(clases) {
+		return clases[k]
+	}
+	return strconv.Itoa(k)
+}
+
+// Clasificador da las probabilidades (una columna por clase) de cada
+// fila de X, como algorithms.SoftmaxRegression
+type Clasificador interface {
+	PredictProba(X *mat.Dense) *mat.Dense
+}
+
+// Serie es una curva de la que se grafica un valor por iteracion
+type Serie struct {
+	Nombre  string
+	Valores []float64
+}
+
+// ejes ubica los datos (x en [xMin, xMax], y en [yMin, yMax]) dentro del
+// area del grafico
+type ejes struct {
+	x0, y0, w, h           float64 // area en pixeles
+	xMin, xMax, yMin, yMax float64
+}
+
+func nuevosEjes(xMin, xMax, yMin, yMax, margenDerecho float64) ejes {
+	if xMax <= xMin {
+		xMax = xMin + 1
+	}
+	if yMax <= yMin {
+		yMax = yMin + 1
+	}
+	return ejes{x0: 64, y0: 36, w: Ancho - 64 - margenDerecho, h: Alto - 36 - 48, xMin: xMin, xMax: xMax, yMin: yMin, yMax: yMax}
+}
+
+func (e ejes) px(x float64) float64 { return e.x0 + (x-e.xMin)/(e.xMax-e.xMin)*e.w }
+func (e ejes) py(y float64) float64 { return e.y0 + e.h - (y-e.yMin)/(e.yMax-e.yMin)*e.h }
+
+// dibujar pone el titulo, el marco, las marcas de los ejes y sus nombres
+func (e ejes) dibujar(l Lienzo, titulo, nombreX, nombreY string) {
+	l.Texto(e.x0+e.w/2, 16, titulo, Centro, negro)
+	for _, v := range marcas(e.xMin, e.xMax) {
+		x := e.px(v)
+		l.Linea([]float64{x, x}, []float64{e.y0 + e.h, e.y0 + e.h + 4}, negro, 1)
+		l.Texto(x, e.y0+e.h+14, formatear(v), Centro, negro)
+	}
+	for _, v := range marcas(e.yMin, e.yMax) {
+		y := e.py(v)
+		l.Linea([]float64{e.x0 - 4, e.x0}, []float64{y, y}, negro, 1)
+		l.Texto(e.x0-6, y, formatear(v), Derecha, negro)
+	}
+	l.Borde(e.x0, e.y0, e.w, e.h, negro)
+	l.Texto(e.x0+e.w/2, e.y0+e.h+34, nombreX, Centro, negro)
+	l.Texto(8, e.y0-12, nombreY, Izquierda, negro)
+}
+
+// marcas elige entre 4 y 10 valores redondos dentro de [min, max]
+func marcas(min, max float64) []float64 {
+	paso := pasoRedondo((max - min) / 5)
+	out := []float64{}
+	for v := math.Ceil(min/paso) * paso; v <= max+paso*1e-9; v += paso {
+		out = append(out, v)
+	}
+	return out
+}
+
+func pasoRedondo(x float64) float64 {
+	e := math.Pow(10, math.Floor(math.Log10(x)))
+	switch f := x / e; {
+	case f < 1.5:
+		return e
+	case f < 3:
+		return 2 * e
+	case f < 7:
+		return 5 * e
+	default:
+		return 10 * e
+	}
+}
+
+func formatear(v float64) string {
+	if math.Abs(v) < 1e-12 {
+		v = 0
+	}
+	return strconv.FormatFloat(v, 'g', 4, 64)
+}
+
+// leyenda lista nombres con su color a la derecha del area
+func leyenda(l Lienzo, e ejes, nombres []string, colores []color.RGBA) {
+	x := e.x0 + e.w + 14
+	for i, n := range nombres {
+		y := e.y0 + 8 + float64(i)*18
+		l.Rect(x, y-5, 10, 10, colores[i])
+		l.Texto(x+16, y, n, Izquierda, negro)
+	}
+}
+
+// CurvaPerdida grafica la perdida por iteracion de cada serie (por
+// ejemplo entrenamiento y validacion)
+func CurvaPerdida(path string, series ...Serie) error {
+	n, yMin, yMax := 0, math.Inf(1), math.Inf(-1)
+	for _, s := range series {
+		n = max(n, len(s.Valores))
+		for _, v := range s.Valores {
+			if !math.IsNaN(v) && !math.IsInf(v, 0) {
+				yMin, yMax = math.Min(yMin, v), math.Max(yMax, v)
+			}
+		}
+	}
+	if n == 0 || math.IsInf(yMin, 1) {
+		return fmt.Errorf("CurvaPerdida: no hay valores para graficar")
+	}
+	yMin = math.Min(0, yMin)
+
+	return Guardar(path, Ancho, Alto, func(l Lienzo) {
+		e := nuevosEjes(0, float64(n-1), yMin, yMax*1.05, 140)
+		nombres, colores := []string{}, []color.RGBA{}
+		for i, s := range series {
+			// con mas de 1000 iteraciones se toma una de cada paso
+			paso := max(1, len(s.Valores)/1000)
+			xs, ys := []float64{}, []float64{}
+			for it := 0; it < len(s.Valores); it += paso {
+				if v := s.Valores[it]; !math.IsNaN(v) && !math.IsInf(v, 0) {
+					xs, ys = append(xs, e.px(float64(it))), append(ys, e.py(v))
+				}
+			}
+			l.Linea(xs, ys, colorClase(i), 2)
+			nombres, colores = append(nombres, s.Nombre), append(colores, colorClase(i))
+		}
+		e.dibujar(l, "Curva de perdida", "iteracion", "perdida")
+		leyenda(l, e, nombres, colores)
+	})
+}
+
+// rejilla es el rectangulo de X (2 features) con un margen del 5% y las
+// probabilidades del clasificador en el centro de cada celda
+type rejilla struct {
+	ejes
+	probs *mat.Dense // fila = celdas*fila + columna
+}
+
+func nuevaRejilla(clf Clasificador, X *mat.Dense, margenDerecho float64) (rejilla, error) {
+	rows, cols := X.Dims()
+	if cols != 2 || rows == 0 {
+		return rejilla{}, fmt.Errorf("los mapas de decision necesitan datos con 2 features, hay %d", cols)
+	}
+	x, y := mat.Col(nil, 0, X), mat.Col(nil, 1, X)
+	xMin, xMax := rango(x)
+	yMin, yMax := rango(y)
+	dx, dy := math.Max(xMax-xMin, 1e-9)*0.05, math.Max(yMax-yMin, 1e-9)*0.05
+	e := nuevosEjes(xMin-dx, xMax+dx, yMin-dy, yMax+dy, margenDerecho)
+
+	G := mat.NewDense(celdas*celdas, 2, nil)
+	for i := 0; i < celdas; i++ {
+		for j := 0; j < celdas; j++ {
+			G.Set(i*celdas+j, 0, e.xMin+(float64(j)+0.5)/celdas*(e.xMax-e.xMin))
+			G.Set(i*celdas+j, 1, e.yMax-(float64(i)+0.5)/celdas*(e.yMax-e.yMin))
+		}
+	}
+	return rejilla{ejes: e, probs: clf.PredictProba(G)}, nil
+}
+
+// pintar rellena cada celda con el color que devuelve colorCelda para
+// sus probabilidades. Las celdas vecinas de una fila con el mismo color
+// van en un solo rectangulo para que el SVG no pese tanto.
+func (r rejilla) pintar(l Lienzo, colorCelda func(p []float64) color.RGBA) {
+	cw, ch := r.w/celdas, r.h/celdas
+	for i := 0; i < celdas; i++ {
+		desde := 0
+		actual := colorCelda(r.probs.RawRowView(i * celdas))
+		for j := 1; j <= celdas; j++ {
+			var c color.RGBA
+			if j < celdas {
+				if c = colorCelda(r.probs.RawRowView(i*celdas + j)); c == actual {
+					continue
+				}
+			}
+			// +0.5 para que no queden lineas entre celdas en SVG
+			l.Rect(r.x0+float64(desde)*cw, r.y0+float64(i)*ch, float64(j-desde)*cw+0.5, ch+0.5, actual)
+			desde, actual = j, c
+		}
+	}
+}
+
+// puntos dibuja las muestras con el color de su clase real
+func (r rejilla) puntos(l Lienzo, X *mat.Dense, y []int) {
+	rows, _ := X.Dims()
+	for i := 0; i < rows; i++ {
+		relleno := blanco
+		if i < len(y) && y[i] >= 0 {
+			relleno = colorClase(y[i])
+		}
+		l.Circulo(r.px(X.At(i, 0)), r.py(X.At(i, 1)), 4, relleno, negro)
+	}
+}
+
+func rango(v []float64) (float64, float64) {
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for _, x := range v {
+		lo, hi = math.Min(lo, x), math.Max(hi, x)
+	}
+	return lo, hi
+}
+
+// RegionesDecision pinta la clase que predice el clasificador en cada
+// punto del plano (datos de 2 features) con las muestras encima, del
+// color de su clase real.
+func RegionesDecision(path string, clf Clasificador, X *mat.Dense, y []int, clases []string, nombresX [2]string) error {
+	r, err := nuevaRejilla(clf, X, 140)
+	if err != nil {
+		return err
+	}
+	_, nClases := r.probs.Dims()
+	return Guardar(path, Ancho, Alto, func(l Lienzo) {
+		r.pintar(l, func(p []float64) color.RGBA {
+			return mezclar(blanco, colorClase(argmax(p)), 0.35)
+		})
+		r.puntos(l, X, y)
+		r.dibujar(l, "Regiones de decision", nombresX[0], nombresX[1])
+		nombres, colores := make([]string, nClases), make([]color.RGBA, nClases)
+		for k := range nombres {
+			nombres[k], colores[k] = nombreClase(clases, k), colorClase(k)
+		}
+		leyenda(l, r.ejes, nombres, colores)
+	})
+}
+
+// MapaProbabilidad pinta la probabilidad de la clase k en el plano (de
+// blanco = 0 al color de la clase = 1) con las muestras encima.
+func MapaProbabilidad(path string, clf Clasificador, X *mat.Dense, y []int, k int, clases []string, nombresX [2]string) error {
+	r, err := nuevaRejilla(clf, X, 100)
+	if err != nil {
+		return err
+	}
+	if _, nClases := r.probs.Dims(); k < 0 || k >= nClases {
+		return fmt.Errorf("MapaProbabilidad: la clase %d no existe, hay %d", k, nClases)
+	}
+	c := colorClase(k)
+	return Guardar(path, Ancho, Alto, func(l Lienzo) {
+		// 32 tonos alcanzan a la vista y juntan celdas en pintar
+		r.pintar(l, func(p []float64) color.RGBA { return mezclar(blanco, c, math.Round(p[k]*32)/32) })
+		r.puntos(l, X, y)
+		r.dibujar(l, "Probabilidad de la clase "+nombreClase(clases, k), nombresX[0], nombresX[1])
+		barraColor(l, r.ejes, c)
+	})
+}
+
+// barraColor es la escala 0 a 1 de MapaProbabilidad
+func barraColor(l Lienzo, e ejes, c color.RGBA) {
+	x, pasos := e.x0+e.w+24, 50
+	for i := 0; i < pasos; i++ {
+		t := 1 - float64(i)/float64(pasos)
+		l.Rect(x, e.y0+float64(i)*e.h/float64(pasos), 16, e.h/float64(pasos)+0.5, mezclar(blanco, c, t))
+	}
+	l.Borde(x, e.y0, 16, e.h, negro)
+	for _, v := range []float64{0, 0.5, 1} {
+		l.Texto(x+22, e.y0+e.h*(1-v), formatear(v), Izquierda, negro)
+	}
+}
+
+// MapaConfusion pinta la matriz de confusion (fila = clase real, columna
+// = predicha) con la cantidad de muestras en cada celda.
+func MapaConfusion(path string, confusion [][]int, clases []string) error {
+	k := len(confusion)
+	if k == 0 {
+		return fmt.Errorf("MapaConfusion: la matriz esta vacia")
+	}
+	maximo := 1
+	for _, fila := range confusion {
+		for _, n := range fila {
+			maximo = max(maximo, n)
+		}
+	}
+	return Guardar(path, Ancho, Alto, func(l Lienzo) {
+		lado := math.Min(Ancho-200, Alto-120)
+		x0, y0, celda := (Ancho-lado)/2+30, 50.0, lado/float64(k)
+		l.Texto(Ancho/2, 16, "Matriz de confusion", Centro, negro)
+		for real, fila := range confusion {
+			for pred, n := range fila {
+				t := float64(n) / float64(maximo)
+				x, y := x0+float64(pred)*celda, y0+float64(real)*celda
+				l.Rect(x, y, celda, celda, mezclar(blanco, azul, t))
+				texto := negro
+				if t > 0.5 {
+					texto = blanco
+				}
+				l.Texto(x+celda/2, y+celda/2, strconv.Itoa(n), Centro, texto)
+			}
+		}
+		for i := 0; i < k; i++ {
+			l.Texto(x0-8, y0+(float64(i)+0.5)*celda, nombreClase(clases, i), Derecha, negro)
+			l.Texto(x0+(float64(i)+0.5)*celda, y0+lado+14, nombreClase(clases, i), Centro, negro)
+		}
+		for i := 0; i <= k; i++ {
+			p := float64(i) * celda
+			l.Linea([]float64{x0, x0 + lado}, []float64{y0 + p, y0 + p}, gris, 1)
+			l.Linea([]float64{x0 + p, x0 + p}, []float64{y0, y0 + lado}, gris, 1)
+		}
+		l.Texto(x0+lado/2, y0+lado+34, "predicha", Centro, negro)
+		l.Texto(x0-8, y0-14, "real", Derecha, negro)
+	})
+}
+
+// argmax devuelve la clase de mayor probabilidad de una fila
+func argmax(row []float64) int {
+	best := 0
+	for k := 1; k < len(row); k++ {
+		if row[k] > row[best] {
+			best = k
+		}
+	}
+	return best
+}
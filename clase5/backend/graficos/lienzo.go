@@ -0,0 +1,180 @@
+// Package graficos dibuja en SVG o PNG los graficos del entrenamiento
+// softmax: curva de perdida, regiones de decision y probabilidades de
+// datasets 2D y matriz de confusion. Se guardan al lado de los CSV que
+// escribe el paquete entrenamiento.
+package graficos
+
+import (
+	"bufio"
+	"fmt"
+	"html"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+)
+
+// Formatos que se pueden guardar (por la extension del archivo)
+const (
+	FormatoSVG = "svg"
+	FormatoPNG = "png"
+)
+
+// Formatos son los formatos validos
+var Formatos = []string{FormatoSVG, FormatoPNG}
+
+// Ancla es la alineacion horizontal de un texto respecto de su x
+type Ancla int
+
+const (
+	Izquierda Ancla = iota
+	Centro
+	Derecha
+)
+
+// Lienzo es donde se dibuja un grafico; hay uno para SVG y otro para
+// PNG. Las coordenadas son pixeles con el origen arriba a la izquierda.
+type Lienzo interface {
+	Rect(x, y, w, h float64, relleno color.Color)
+	Borde(x, y, w, h float64, trazo color.Color)
+	Linea(xs, ys []float64, trazo color.Color, ancho float64)
+	Circulo(cx, cy, r float64, relleno, trazo color.Color)
+	Texto(x, y float64, s string, ancla Ancla, c color.Color)
+}
+
+// Guardar dibuja un grafico de ancho x alto y lo guarda en path; el
+// formato sale de la extension (.svg o .png).
+func Guardar(path string, ancho, alto int, dibujar func(Lienzo)) error {
+	formato := strings.TrimPrefix(strings.ToLower(filepath.Ext(path)), ".")
+	if formato != FormatoSVG && formato != FormatoPNG {
+		return fmt.Errorf("formato de grafico '%s' no existe, use %s", formato, strings.Join(Formatos, " o "))
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if formato == FormatoPNG {
+		l := &lienzoPNG{img: image.NewRGBA(image.Rect(0, 0, ancho, alto))}
+		draw.Draw(l.img, l.img.Bounds(), image.White, image.Point{}, draw.Src)
+		dibujar(l)
+		return png.Encode(f, l.img)
+	}
+
+	w := bufio.NewWriter(f)
+	fmt.Fprintf(w, "<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%d\" height=\"%d\" viewBox=\"0 0 %d %d\" font-family=\"sans-serif\" font-size=\"12\">\n", ancho, alto, ancho, alto)
+	fmt.Fprintf(w, "<rect width=\"%d\" height=\"%d\" fill=\"#ffffff\"/>\n", ancho, alto)
+	dibujar(&lienzoSVG{w: w})
+	fmt.Fprintln(w, "</svg>")
+	return w.Flush()
+}
+
+// ===== SVG =====
+
+type lienzoSVG struct {
+	w *bufio.Writer
+}
+
+func (l *lienzoSVG) Rect(x, y, w, h float64, relleno color.Color) {
+	fmt.Fprintf(l.w, "<rect x=\"%.2f\" y=\"%.2f\" width=\"%.2f\" height=\"%.2f\" fill=\"%s\"/>\n", x, y, w, h, hex(relleno))
+}
+
+func (l *lienzoSVG) Borde(x, y, w, h float64, trazo color.Color) {
+	fmt.Fprintf(l.w, "<rect x=\"%.2f\" y=\"%.2f\" width=\"%.2f\" height=\"%.2f\" fill=\"none\" stroke=\"%s\"/>\n", x, y, w, h, hex(trazo))
+}
+
+func (l *lienzoSVG) Linea(xs, ys []float64, trazo color.Color, ancho float64) {
+	puntos := make([]string, len(xs))
+	for i := range xs {
+		puntos[i] = fmt.Sprintf("%.2f,%.2f", xs[i], ys[i])
+	}
+	fmt.Fprintf(l.w, "<polyline points=\"%s\" fill=\"none\" stroke=\"%s\" stroke-width=\"%g\"/>\n", strings.Join(puntos, " "), hex(trazo), ancho)
+}
+
+func (l *lienzoSVG) Circulo(cx, cy, r float64, relleno, trazo color.Color) {
+	fmt.Fprintf(l.w, "<circle cx=\"%.2f\" cy=\"%.2f\" r=\"%g\" fill=\"%s\" stroke=\"%s\"/>\n", cx, cy, r, hex(relleno), hex(trazo))
+}
+
+func (l *lienzoSVG) Texto(x, y float64, s string, ancla Ancla, c color.Color) {
+	anchor := [...]string{"start", "middle", "end"}[ancla]
+	fmt.Fprintf(l.w, "<text x=\"%.2f\" y=\"%.2f\" text-anchor=\"%s\" dominant-baseline=\"middle\" fill=\"%s\">%s</text>\n", x, y, anchor, hex(c), html.EscapeString(s))
+}
+
+func hex(c color.Color) string {
+	r, g, b, _ := c.RGBA()
+	return fmt.Sprintf("#%02x%02x%02x", r>>8, g>>8, b>>8)
+}
+
+// ===== PNG =====
+
+type lienzoPNG struct {
+	img *image.RGBA
+}
+
+func (l *lienzoPNG) Rect(x, y, w, h float64, relleno color.Color) {
+	r := image.Rect(int(math.Round(x)), int(math.Round(y)), int(math.Round(x+w)), int(math.Round(y+h)))
+	draw.Draw(l.img, r, image.NewUniform(relleno), image.Point{}, draw.Over)
+}
+
+func (l *lienzoPNG) Borde(x, y, w, h float64, trazo color.Color) {
+	l.Linea([]float64{x, x + w, x + w, x, x}, []float64{y, y, y + h, y + h, y}, trazo, 1)
+}
+
+// Linea traza cada segmento con puntos cuadrados del ancho pedido
+func (l *lienzoPNG) Linea(xs, ys []float64, trazo color.Color, ancho float64) {
+	medio := int(math.Max(ancho, 1)) / 2
+	for i := 1; i < len(xs); i++ {
+		dx, dy := xs[i]-xs[i-1], ys[i]-ys[i-1]
+		pasos := int(math.Max(math.Abs(dx), math.Abs(dy))) + 1
+		for p := 0; p <= pasos; p++ {
+			t := float64(p) / float64(pasos)
+			px := int(math.Round(xs[i-1] + t*dx))
+			py := int(math.Round(ys[i-1] + t*dy))
+			for a := -medio; a <= medio; a++ {
+				for b := -medio; b <= medio; b++ {
+					l.img.Set(px+a, py+b, trazo)
+				}
+			}
+		}
+	}
+}
+
+func (l *lienzoPNG) Circulo(cx, cy, r float64, relleno, trazo color.Color) {
+	for y := int(cy - r - 1); y <= int(cy+r+1); y++ {
+		for x := int(cx - r - 1); x <= int(cx+r+1); x++ {
+			d := math.Hypot(float64(x)-cx, float64(y)-cy)
+			switch {
+			case d <= r-1:
+				l.img.Set(x, y, relleno)
+			case d <= r:
+				l.img.Set(x, y, trazo)
+			}
+		}
+	}
+}
+
+func (l *lienzoPNG) Texto(x, y float64, s string, ancla Ancla, c color.Color) {
+	d := &font.Drawer{Dst: l.img, Src: image.NewUniform(c), Face: basicfont.Face7x13}
+	ancho := float64(d.MeasureString(s).Round())
+	switch ancla {
+	case Centro:
+		x -= ancho / 2
+	case Derecha:
+		x -= ancho
+	}
+	// basicfont mide 13px con 11 sobre la linea base
+	d.Dot = fixed.P(int(math.Round(x)), int(math.Round(y+4)))
+	d.DrawString(s)
+}
@@ -0,0 +1,122 @@
+package inferencia
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/mndrix/golog"
+	"github.com/mndrix/golog/read"
+	"github.com/mndrix/golog/term"
+)
+
+// Limites acota cada consulta a Prolog para que una regla recursiva
+// no pueda colgar el servidor. Un valor en cero desactiva ese limite.
+type Limites struct {
+	Timeout       time.Duration // tiempo maximo por consulta
+	MaxSoluciones int           // numero maximo de soluciones
+	MaxPasos      int           // numero maximo de pasos de inferencia
+}
+
+// LimitesPorDefecto son los que usa la API
+var LimitesPorDefecto = Limites{
+	Timeout:       2 * time.Second,
+	MaxSoluciones: 1000,
+	MaxPasos:      200000,
+}
+
+// Errores de limite; se pueden comparar con errors.Is
+var (
+	ErrTiempoAgotado        = errors.New("la consulta excedio el tiempo maximo")
+	ErrDemasiadasSoluciones = errors.New("la consulta excedio el numero maximo de soluciones")
+	ErrDemasiadosPasos      = errors.New("la consulta excedio el numero maximo de pasos de inferencia")
+)
+
+// ErrorConsulta envuelve el error de una consulta junto con la consulta
+// que lo provoco y cuanto alcanzo a avanzar.
+type ErrorConsulta struct {
+	Consulta   string
+	Pasos      int
+	Soluciones int
+	Err        error
+}
+
+func (e *ErrorConsulta) Error() string {
+	return fmt.Sprintf("%s: %v (pasos: %d, soluciones: %d)", e.Consulta, e.Err, e.Pasos, e.Soluciones)
+}
+
+func (e *ErrorConsulta) Unwrap() error {
+	return e.Err
+}
+
+// ProveAll es como golog.Machine.ProveAll pero avanza la maquina paso a
+// paso respetando el contexto y los limites. Si golog entra en panico
+// (p. ej. predicado no definido) se devuelve como error.
+func ProveAll(ctx context.Context, m golog.Machine, consulta string, lim Limites) (soluciones []term.Bindings, err error) {
+	if lim.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, lim.Timeout)
+		defer cancel()
+	}
+
+	pasos := 0
+	fallar := func(causa error) ([]term.Bindings, error) {
+		e := &ErrorConsulta{Consulta: consulta, Pasos: pasos, Soluciones: len(soluciones), Err: causa}
+		log.Println("Consulta Prolog abortada:", e)
+		return nil, e
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			soluciones, err = fallar(fmt.Errorf("%v", r))
+		}
+	}()
+
+	t, err := read.Term(consulta)
+	if err != nil {
+		return fallar(err)
+	}
+	goal, ok := t.(term.Callable)
+	if !ok {
+		return fallar(fmt.Errorf("la consulta no es un objetivo valido"))
+	}
+
+	vars := term.Variables(goal) // conservar los nombres de las variables
+	soluciones = []term.Bindings{}
+	maquina := m.PushConj(goal)
+
+	for {
+		select {
+		case <-ctx.Done():
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				return fallar(ErrTiempoAgotado)
+			}
+			return fallar(ctx.Err())
+		default:
+		}
+
+		pasos++
+		if lim.MaxPasos > 0 && pasos > lim.MaxPasos {
+			return fallar(ErrDemasiadosPasos)
+		}
+
+		var answer term.Bindings
+		maquina, answer, err = maquina.Step()
+		if err == golog.MachineDone {
+			break
+		}
+		if err != nil {
+			return fallar(err)
+		}
+		if answer != nil {
+			soluciones = append(soluciones, answer.WithNames(vars))
+			if lim.MaxSoluciones > 0 && len(soluciones) > lim.MaxSoluciones {
+				return fallar(ErrDemasiadasSoluciones)
+			}
+		}
+	}
+
+	return soluciones, nil
+}
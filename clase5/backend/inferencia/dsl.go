@@ -0,0 +1,361 @@
+package inferencia
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/mndrix/golog/term"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultReglasDSLPath es donde la API y kbcompile dejan las reglas
+// compiladas desde el DSL; se consulta despues de conocimiento.pl.
+const DefaultReglasDSLPath = "./prolog/reglas_dsl.pl"
+
+// Valores de cada condicion cuando la regla no la restringe. La
+// severidad usa los mismos nombres que la urgencia (mediana, no media).
+var (
+	ValoresUrgencia = []string{"baja", "mediana", "alta"}
+	Severidades     = []string{"baja", "mediana", "alta"}
+)
+
+// Valores acepta en YAML/JSON un valor suelto o una lista
+type Valores []string
+
+func (v *Valores) UnmarshalYAML(n *yaml.Node) error {
+	if n.Kind == yaml.ScalarNode {
+		*v = Valores{n.Value}
+		return nil
+	}
+	var lista []string
+	if err := n.Decode(&lista); err != nil {
+		return err
+	}
+	*v = lista
+	return nil
+}
+
+// MarshalYAML escribe un solo valor como escalar
+func (v Valores) MarshalYAML() (interface{}, error) {
+	if len(v) == 1 {
+		return v[0], nil
+	}
+	return []string(v), nil
+}
+
+// CondicionesDSL son las condiciones de una regla. Una condicion vacia
+// significa "cualquier valor".
+type CondicionesDSL struct {
+	Urgencia    Valores `yaml:"urgencia,omitempty" json:"urgencia,omitempty"`
+	Enfermedad  Valores `yaml:"enfermedad" json:"enfermedad"`
+	Cronica     string  `yaml:"cronica,omitempty" json:"cronica,omitempty"`         // si | no
+	Pecho       string  `yaml:"dolor_pecho,omitempty" json:"dolor_pecho,omitempty"` // si | no
+	Respiracion string  `yaml:"respiracion,omitempty" json:"respiracion,omitempty"` // si | no
+}
+
+// ReglaDSL: si se cumplen las condiciones, el medicamento queda
+// contraindicado por el motivo indicado.
+type ReglaDSL struct {
+	ID            string         `yaml:"id,omitempty" json:"id,omitempty"`
+	Si            CondicionesDSL `yaml:"si" json:"si"`
+	Contraindicar string         `yaml:"contraindicar" json:"contraindicar"`
+	Motivo        string         `yaml:"motivo,omitempty" json:"motivo,omitempty"`
+	Severidad     string         `yaml:"severidad,omitempty" json:"severidad,omitempty"`
+}
+
+// ArchivoDSL es el documento que escriben los farmaceuticos
+type ArchivoDSL struct {
+	Reglas []ReglaDSL `yaml:"reglas" json:"reglas"`
+}
+
+// LeerDSL parsea un documento YAML (o JSON, que es YAML valido)
+func LeerDSL(data []byte) (ArchivoDSL, error) {
+	var a ArchivoDSL
+	if err := yaml.Unmarshal(data, &a); err != nil {
+		return a, fmt.Errorf("%w: %v", ErrDatoInvalido, err)
+	}
+	return a, nil
+}
+
+// severidadPorDefecto es la de las reglas que no la indican
+const severidadPorDefecto = "mediana"
+
+// CompilarDSL genera hechos medicamento_contraindicado/6 (uno por cada
+// combinacion de valores) y detalle_contraindicacion/9 con el id de la
+// regla, el motivo y la severidad, que inferencias.pl expone como
+// detalle_caso/8. Las reglas sin id se llaman regla_N (N es su posicion).
+func CompilarDSL(a ArchivoDSL, origen string) (string, error) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%% Generado por kbcompile desde %s. No editar a mano.\n", origen)
+
+	ids := map[string]bool{}
+	for i, r := range a.Reglas {
+		nombre := r.ID
+		if nombre == "" {
+			nombre = fmt.Sprintf("regla %d", i+1)
+		}
+		falla := func(format string, args ...interface{}) (string, error) {
+			return "", fmt.Errorf("%w: %s: %s", ErrDatoInvalido, nombre, fmt.Sprintf(format, args...))
+		}
+
+		id := r.ID
+		if id == "" {
+			id = fmt.Sprintf("regla_%d", i+1)
+		}
+		if ids[id] {
+			return falla("id %q repetido (las reglas sin id se llaman regla_N)", id)
+		}
+		ids[id] = true
+
+		if r.Contraindicar == "" {
+			return falla("falta 'contraindicar'")
+		}
+		med, err := NormalizarAtomo(r.Contraindicar)
+		if err != nil {
+			return falla("%v", err)
+		}
+		if len(r.Si.Enfermedad) == 0 {
+			return falla("falta la condicion 'enfermedad'")
+		}
+		severidad := r.Severidad
+		if severidad == "" {
+			severidad = severidadPorDefecto
+		}
+		if !contiene(Severidades, severidad) {
+			return falla("severidad %q, use %s%s", r.Severidad, strings.Join(Severidades, ", "), pistaMediana(severidad))
+		}
+		// golog solo entiende el escape \' dentro de un atomo entre comillas
+		if strings.ContainsAny(id+r.Motivo, "\\\n\r") {
+			return falla("el id y el motivo no pueden tener \\ ni saltos de linea")
+		}
+
+		urgencias, err := expandir(r.Si.Urgencia, ValoresUrgencia)
+		if err != nil {
+			return falla("urgencia: %v", err)
+		}
+		enfermedades, err := expandir(r.Si.Enfermedad, nil)
+		if err != nil {
+			return falla("enfermedad: %v", err)
+		}
+		cronicas, err := expandirSiNo(r.Si.Cronica, "cronica_")
+		if err != nil {
+			return falla("cronica: %v", err)
+		}
+		pechos, err := expandirSiNo(r.Si.Pecho, "pecho_")
+		if err != nil {
+			return falla("dolor_pecho: %v", err)
+		}
+		resps, err := expandirSiNo(r.Si.Respiracion, "resp_")
+		if err != nil {
+			return falla("respiracion: %v", err)
+		}
+
+		fmt.Fprintf(&b, "\n%% %s", id)
+		if r.Motivo != "" {
+			fmt.Fprintf(&b, ": %s", r.Motivo)
+		}
+		b.WriteString("\n")
+		for _, urg := range urgencias {
+			for _, enf := range enfermedades {
+				for _, cron := range cronicas {
+					for _, pecho := range pechos {
+						for _, resp := range resps {
+							fmt.Fprintf(&b, "medicamento_contraindicado(%s, %s, %s, %s, %s, %s).\n",
+								urg, enf, cron, pecho, resp, med)
+							fmt.Fprintf(&b, "detalle_contraindicacion(%s, %s, %s, %s, %s, %s, %s, %s, %s).\n",
+								term.QuoteFunctor(id), urg, enf, cron, pecho, resp, med, term.QuoteFunctor(r.Motivo), severidad)
+						}
+					}
+				}
+			}
+		}
+	}
+	return b.String(), nil
+}
+
+// expandir normaliza los valores; si no hay ninguno usa todos
+func expandir(valores Valores, todos []string) ([]string, error) {
+	if len(valores) == 0 {
+		if todos == nil {
+			return nil, fmt.Errorf("se requiere al menos un valor")
+		}
+		valores = todos
+	}
+	out := []string{}
+	for _, v := range valores {
+		a, err := NormalizarAtomo(v)
+		if err != nil {
+			return nil, err
+		}
+		if todos != nil && !contiene(todos, a) {
+			return nil, fmt.Errorf("valor %q, use %s%s", v, strings.Join(todos, ", "), pistaMediana(a))
+		}
+		out = append(out, a)
+	}
+	return out, nil
+}
+
+// pistaMediana aclara el error mas comun: media en vez de mediana
+func pistaMediana(valor string) string {
+	if valor == "media" {
+		return " (el valor del medio es mediana, no media)"
+	}
+	return ""
+}
+
+// expandirSiNo traduce si/no (o true/false) al atomo con prefijo
+func expandirSiNo(valor, prefijo string) ([]string, error) {
+	switch strings.ToLower(strings.TrimSpace(valor)) {
+	case "":
+		return []string{prefijo + "si", prefijo + "no"}, nil
+	case "si", "true":
+		return []string{prefijo + "si"}, nil
+	case "no", "false":
+		return []string{prefijo + "no"}, nil
+	}
+	return nil, fmt.Errorf("valor %q, use si o no", valor)
+}
+
+// DescompilarKB convierte los hechos medicamento_contraindicado/6 de
+// las clausulas en reglas DSL. Los que vienen del DSL (los que tienen
+// detalle_contraindicacion/9) se juntan por id en la regla original;
+// los demas salen como una regla por hecho.
+func DescompilarKB(clausulas []Clausula) ArchivoDSL {
+	grupos := map[string]*grupoDSL{}
+	deHecho := map[string]string{} // hecho -> id de su regla
+	for _, c := range clausulas {
+		if c.EsDirectiva() || c.EsRegla() || c.Indicador() != "detalle_contraindicacion/9" {
+			continue
+		}
+		args := nombresArgumentos(c.Cabeza())
+		id := args[0]
+		g, ok := grupos[id]
+		if !ok {
+			g = &grupoDSL{id: id, med: args[6], motivo: args[7], severidad: args[8]}
+			grupos[id] = g
+		}
+		g.combinaciones = append(g.combinaciones, args[1:6])
+		deHecho[strings.Join(args[1:7], ",")] = id
+	}
+
+	a := ArchivoDSL{Reglas: []ReglaDSL{}}
+	emitidos := map[string]bool{}
+	for _, c := range clausulas {
+		if c.EsDirectiva() || c.EsRegla() || c.Indicador() != "medicamento_contraindicado/6" {
+			continue
+		}
+		args := nombresArgumentos(c.Cabeza())
+		if id, ok := deHecho[strings.Join(args, ",")]; ok {
+			if !emitidos[id] {
+				emitidos[id] = true
+				a.Reglas = append(a.Reglas, grupos[id].reglas()...)
+			}
+			continue
+		}
+		a.Reglas = append(a.Reglas, ReglaDSL{
+			Si: CondicionesDSL{
+				Urgencia:    Valores{args[0]},
+				Enfermedad:  Valores{args[1]},
+				Cronica:     strings.TrimPrefix(args[2], "cronica_"),
+				Pecho:       strings.TrimPrefix(args[3], "pecho_"),
+				Respiracion: strings.TrimPrefix(args[4], "resp_"),
+			},
+			Contraindicar: args[5],
+		})
+	}
+	return a
+}
+
+// grupoDSL son los hechos que genero una regla del DSL
+type grupoDSL struct {
+	id, med, motivo, severidad string
+	combinaciones              [][]string // urgencia, enfermedad, cronica, pecho, respiracion
+}
+
+// reglas rearma la regla: los valores de cada condicion en el orden en
+// que aparecen y, si son todos los posibles, la condicion vacia. Si los
+// hechos no son todas las combinaciones de esos valores (se editaron a
+// mano) sale una regla por hecho.
+func (g *grupoDSL) reglas() []ReglaDSL {
+	valores := make([][]string, 5)
+	vistas := map[string]bool{}
+	for _, comb := range g.combinaciones {
+		vistas[strings.Join(comb, ",")] = true
+		for k, v := range comb {
+			if !contiene(valores[k], v) {
+				valores[k] = append(valores[k], v)
+			}
+		}
+	}
+	total := 1
+	for _, vs := range valores {
+		total *= len(vs)
+	}
+
+	nueva := func(id string, valores [][]string) ReglaDSL {
+		r := ReglaDSL{
+			ID: id,
+			Si: CondicionesDSL{
+				Enfermedad:  Valores(valores[1]),
+				Cronica:     siNo(valores[2], "cronica_"),
+				Pecho:       siNo(valores[3], "pecho_"),
+				Respiracion: siNo(valores[4], "resp_"),
+			},
+			Contraindicar: g.med,
+			Motivo:        g.motivo,
+		}
+		if len(valores[0]) != len(ValoresUrgencia) {
+			r.Si.Urgencia = Valores(valores[0])
+		}
+		if g.severidad != severidadPorDefecto {
+			r.Severidad = g.severidad
+		}
+		return r
+	}
+	if total == len(vistas) {
+		return []ReglaDSL{nueva(g.id, valores)}
+	}
+	out := []ReglaDSL{}
+	for i, comb := range g.combinaciones {
+		una := make([][]string, 5)
+		for k, v := range comb {
+			una[k] = []string{v}
+		}
+		out = append(out, nueva(fmt.Sprintf("%s_%d", g.id, i+1), una))
+	}
+	return out
+}
+
+// siNo es la inversa de expandirSiNo: "" si estan los dos valores
+func siNo(valores []string, prefijo string) string {
+	if len(valores) != 1 {
+		return ""
+	}
+	return strings.TrimPrefix(valores[0], prefijo)
+}
+
+// nombresArgumentos devuelve los argumentos atomicos como texto sin comillas
+func nombresArgumentos(c term.Callable) []string {
+	out := []string{}
+	for _, arg := range c.Arguments() {
+		out = append(out, nombreAtomo(arg))
+	}
+	return out
+}
+
+// EscribirDSL serializa las reglas en YAML, ordenadas por enfermedad
+// para que el archivo sea facil de revisar.
+func EscribirDSL(a ArchivoDSL) ([]byte, error) {
+	sort.SliceStable(a.Reglas, func(i, j int) bool {
+		return strings.Join(a.Reglas[i].Si.Enfermedad, ",") < strings.Join(a.Reglas[j].Si.Enfermedad, ",")
+	})
+	var b bytes.Buffer
+	enc := yaml.NewEncoder(&b)
+	enc.SetIndent(2)
+	if err := enc.Encode(a); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), enc.Close()
+}
@@ -0,0 +1,68 @@
+package inferencia
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestCompilarDescompilarDSL(t *testing.T) {
+	dsl := ArchivoDSL{Reglas: []ReglaDSL{
+		{
+			ID:            "asma-betabloqueantes",
+			Si:            CondicionesDSL{Urgencia: Valores{"mediana", "alta"}, Enfermedad: Valores{"asma"}, Respiracion: "si"},
+			Contraindicar: "propranolol",
+			Motivo:        "broncoespasmo's",
+			Severidad:     "alta",
+		},
+		{
+			ID:            "reflujo-aines",
+			Si:            CondicionesDSL{Enfermedad: Valores{"reflujo", "gastritis"}, Pecho: "no"},
+			Contraindicar: "ibuprofeno",
+			Motivo:        `dice "no" y 'nunca'`,
+		},
+		{
+			ID:            "apnea",
+			Si:            CondicionesDSL{Urgencia: Valores{"baja"}, Enfermedad: Valores{"apnea"}, Cronica: "si", Pecho: "si"},
+			Contraindicar: "diazepam",
+			Motivo:        "riesgo de caidas: 50%",
+			Severidad:     "baja",
+		},
+		{
+			ID:            "sin_motivo",
+			Si:            CondicionesDSL{Enfermedad: Valores{"migranas"}},
+			Contraindicar: "triptanes",
+		},
+	}}
+
+	programa, err := CompilarDSL(dsl, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	clausulas, err := LeerClausulas(programa)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := DescompilarKB(clausulas)
+	if !reflect.DeepEqual(got, dsl) {
+		t.Fatalf("la ida y vuelta cambio las reglas\n got: %+v\nwant: %+v", got.Reglas, dsl.Reglas)
+	}
+
+	// y compilar lo descompilado da el mismo programa
+	otra, err := CompilarDSL(got, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if otra != programa {
+		t.Errorf("el programa recompilado es distinto:\n%s\n---\n%s", otra, programa)
+	}
+}
+
+func TestCompilarDSLMotivoInvalido(t *testing.T) {
+	for _, motivo := range []string{`barra \ invertida`, "dos\nlineas"} {
+		dsl := ArchivoDSL{Reglas: []ReglaDSL{{Si: CondicionesDSL{Enfermedad: Valores{"asma"}}, Contraindicar: "propranolol", Motivo: motivo}}}
+		if _, err := CompilarDSL(dsl, "test"); !errors.Is(err, ErrDatoInvalido) {
+			t.Errorf("motivo %q: error %v, se esperaba ErrDatoInvalido", motivo, err)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package inferencia
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mndrix/golog/lex"
+	"github.com/mndrix/golog/read"
+	"github.com/mndrix/golog/term"
+)
+
+// Clausula es un hecho, regla o directiva de un archivo .pl junto con
+// la linea donde empieza, para poder reportar errores.
+type Clausula struct {
+	Linea int
+	Texto string
+	Term  term.Term
+}
+
+// Cabeza devuelve el hecho o la cabeza de la regla
+func (c Clausula) Cabeza() term.Callable {
+	if c.EsRegla() {
+		return term.Head(c.Term)
+	}
+	return c.Term.(term.Callable)
+}
+
+// Indicador devuelve nombre/aridad de la cabeza de la clausula
+func (c Clausula) Indicador() string {
+	return c.Cabeza().Indicator()
+}
+
+// EsRegla indica si la clausula tiene cuerpo (Cabeza :- Cuerpo)
+func (c Clausula) EsRegla() bool {
+	return term.IsClause(c.Term)
+}
+
+// EsDirectiva indica si es una directiva (:- Objetivo)
+func (c Clausula) EsDirectiva() bool {
+	return term.IsDirective(c.Term)
+}
+
+// LeerClausulas separa el programa en clausulas con el lexer de golog
+// (que entiende comillas, escapes y comentarios) y parsea cada una con su
+// lector de terminos. Una clausula que no se lee entera es un error.
+func LeerClausulas(programa string) ([]Clausula, error) {
+	clausulas := []Clausula{}
+	var errLex error
+	s := new(lex.Scanner).Init(strings.NewReader(programa))
+	s.Error = func(s *lex.Scanner, msg string) {
+		if errLex == nil {
+			errLex = fmt.Errorf("linea %d: %s", s.Pos().Line, msg)
+		}
+	}
+
+	inicio, lineaInicio := -1, 0
+	for tok := s.Scan(); tok != lex.EOF; tok = s.Scan() {
+		if errLex != nil {
+			return nil, errLex
+		}
+		if tok == lex.Comment {
+			continue
+		}
+		if inicio == -1 {
+			inicio, lineaInicio = s.Offset, s.Line
+		}
+		if tok != lex.FullStop {
+			continue
+		}
+		texto := programa[inicio : s.Offset+len(s.TokenText())]
+		t, err := leerTermino(texto)
+		if err != nil {
+			return nil, fmt.Errorf("linea %d: %w", lineaInicio, err)
+		}
+		clausulas = append(clausulas, Clausula{Linea: lineaInicio, Texto: texto, Term: t})
+		inicio = -1
+	}
+	if errLex != nil {
+		return nil, errLex
+	}
+
+	if inicio != -1 {
+		return nil, fmt.Errorf("linea %d: clausula sin punto final", lineaInicio)
+	}
+	return clausulas, nil
+}
+
+// leerTermino parsea una clausula y falla si no es exactamente un termino
+// (el lector de golog entra en panico con algunas entradas invalidas)
+func leerTermino(texto string) (t term.Term, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+	terminos, err := read.TermAll(texto)
+	if err != nil {
+		return nil, err
+	}
+	if len(terminos) != 1 {
+		return nil, fmt.Errorf("se esperaba una clausula y se leyeron %d", len(terminos))
+	}
+	return terminos[0], nil
+}
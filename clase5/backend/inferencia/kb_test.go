@@ -0,0 +1,81 @@
+package inferencia
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLeerClausulasComillas(t *testing.T) {
+	programa := `% comentario con punto. y 'comilla
+motivo(a, 'broncoespasmo\'s. grave').
+motivo(b, "texto. con punto").
+hecho(c) :- motivo(c, 'x'). % otro. comentario
+ultimo(d).`
+	clausulas, err := LeerClausulas(programa)
+	if err != nil {
+		t.Fatal(err)
+	}
+	esperadas := []struct {
+		linea     int
+		indicador string
+	}{
+		{2, "motivo/2"},
+		{3, "motivo/2"},
+		{4, "hecho/1"},
+		{5, "ultimo/1"},
+	}
+	if len(clausulas) != len(esperadas) {
+		t.Fatalf("se leyeron %d clausulas, se esperaban %d", len(clausulas), len(esperadas))
+	}
+	for i, e := range esperadas {
+		if c := clausulas[i]; c.Linea != e.linea || c.Indicador() != e.indicador {
+			t.Errorf("clausula %d: %s en la linea %d, se esperaba %s en la %d", i, c.Indicador(), c.Linea, e.indicador, e.linea)
+		}
+	}
+	if arg := nombreAtomo(clausulas[0].Cabeza().Arguments()[1]); arg != "broncoespasmo's. grave" {
+		t.Errorf("motivo leido como %q", arg)
+	}
+}
+
+func TestLeerClausulasErrores(t *testing.T) {
+	casos := map[string]string{
+		"sin punto final":        "a(b).\nc(d)",
+		"comilla doble escapada": "a('it''s').\nb(c).",
+		"termino incompleto":     "a(b, ).\nc(d).",
+	}
+	for nombre, programa := range casos {
+		if clausulas, err := LeerClausulas(programa); err == nil {
+			t.Errorf("%s: se leyeron %d clausulas sin error", nombre, len(clausulas))
+		}
+	}
+}
+
+func TestLeerClausulasMotivoDSL(t *testing.T) {
+	dsl := ArchivoDSL{Reglas: []ReglaDSL{{
+		ID:            "asma",
+		Si:            CondicionesDSL{Enfermedad: Valores{"asma"}},
+		Contraindicar: "propranolol",
+		Motivo:        "broncoespasmo's",
+	}}}
+	programa, err := CompilarDSL(dsl, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	clausulas, err := LeerClausulas(programa)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 3 urgencias x 2 cronica x 2 pecho x 2 respiracion, un hecho y un detalle cada una
+	if len(clausulas) != 48 {
+		t.Fatalf("se leyeron %d clausulas, se esperaban 48", len(clausulas))
+	}
+	pechoNo := 0
+	for _, c := range clausulas {
+		if strings.Contains(c.Texto, "pecho_no") {
+			pechoNo++
+		}
+	}
+	if pechoNo != 24 {
+		t.Errorf("%d clausulas con pecho_no, se esperaban 24", pechoNo)
+	}
+}
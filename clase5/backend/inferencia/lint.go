@@ -0,0 +1,266 @@
+package inferencia
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/mndrix/golog/prelude"
+	"github.com/mndrix/golog/read"
+	"github.com/mndrix/golog/term"
+	"gopkg.in/yaml.v3"
+)
+
+// Severidades de un hallazgo del linter
+const (
+	SeveridadError = "error"
+	SeveridadAviso = "aviso"
+)
+
+// DefaultLintConfigPath es la configuracion de kblint
+const DefaultLintConfigPath = "./prolog/kblint.yaml"
+
+// Hallazgo es un problema encontrado en la base de conocimiento
+type Hallazgo struct {
+	Archivo   string `json:"archivo"`
+	Linea     int    `json:"linea"`
+	Tipo      string `json:"tipo"`
+	Severidad string `json:"severidad"`
+	Predicado string `json:"predicado,omitempty"`
+	Mensaje   string `json:"mensaje"`
+}
+
+func (h Hallazgo) String() string {
+	return fmt.Sprintf("%s:%d: %s [%s] %s", h.Archivo, h.Linea, h.Severidad, h.Tipo, h.Mensaje)
+}
+
+// ReglaNunca es un patron Prolog que ningun hecho debe cumplir,
+// p. ej. medicamento_contraindicado(_, asma, _, _, _, broncodilatadores).
+type ReglaNunca struct {
+	Nombre string `yaml:"nombre"`
+	Patron string `yaml:"patron"`
+}
+
+// ConfigLint declara el vocabulario permitido por posicion de argumento
+// (una lista vacia deja la posicion libre) y las reglas "nunca".
+type ConfigLint struct {
+	Vocabulario map[string][][]string `yaml:"vocabulario"`
+	Nunca       []ReglaNunca          `yaml:"nunca"`
+}
+
+// CargarConfigLint lee la configuracion YAML del linter
+func CargarConfigLint(path string) (ConfigLint, error) {
+	var cfg ConfigLint
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return cfg, err
+	}
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return cfg, fmt.Errorf("%s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// ArchivoKB son las clausulas de un archivo .pl
+type ArchivoKB struct {
+	Path      string
+	Clausulas []Clausula
+}
+
+// predicados foraneos que golog registra en NewMachine
+var builtinsForaneos = []string{
+	"!/0", "$cut_to/1", ",/2", "->/2", ";/2", "=/2", "=:=/2", "==/2", "\\==/2",
+	"@</2", "@=</2", "@>/2", "@>=/2", "\\+/1", "atom_codes/2", "atom_number/2",
+	"call/1", "call/2", "call/3", "call/4", "call/5", "call/6", "downcase_atom/2",
+	"fail/0", "findall/3", "ground/1", "is/2", "listing/0", "msort/2",
+	"printf/1", "printf/2", "printf/3", "succ/2", "var/1", "true/0",
+}
+
+// predicadosConocidos son los que golog entiende sin que la base los defina
+func predicadosConocidos() map[string]bool {
+	conocidos := map[string]bool{}
+	for _, b := range builtinsForaneos {
+		conocidos[b] = true
+	}
+	if clausulas, err := LeerClausulas(prelude.Prelude); err == nil {
+		for _, c := range clausulas {
+			if !c.EsDirectiva() {
+				conocidos[c.Indicador()] = true
+			}
+		}
+	}
+	return conocidos
+}
+
+// Lint revisa los archivos de la base de conocimiento y devuelve los
+// hallazgos ordenados por archivo y linea.
+func Lint(archivos []ArchivoKB, cfg ConfigLint) ([]Hallazgo, error) {
+	hallazgos := []Hallazgo{}
+
+	nunca := make([]term.Term, 0, len(cfg.Nunca))
+	for _, r := range cfg.Nunca {
+		patron := strings.TrimSuffix(strings.TrimSpace(r.Patron), ".") + "."
+		t, err := read.Term(patron)
+		if err != nil {
+			return nil, fmt.Errorf("regla nunca %q: %w", r.Nombre, err)
+		}
+		nunca = append(nunca, t)
+	}
+
+	definidos := map[string]bool{}
+	aridades := map[string]map[int]bool{}
+	vistos := map[string]string{} // texto normalizado -> archivo:linea
+
+	for _, a := range archivos {
+		for _, c := range a.Clausulas {
+			if c.EsDirectiva() {
+				continue
+			}
+			cabeza := c.Cabeza()
+			ind := c.Indicador()
+			definidos[ind] = true
+			if aridades[cabeza.Name()] == nil {
+				aridades[cabeza.Name()] = map[int]bool{}
+			}
+			aridades[cabeza.Name()][cabeza.Arity()] = true
+
+			nuevo := func(tipo, severidad, msg string) {
+				hallazgos = append(hallazgos, Hallazgo{
+					Archivo: a.Path, Linea: c.Linea, Tipo: tipo,
+					Severidad: severidad, Predicado: ind, Mensaje: msg,
+				})
+			}
+
+			// duplicados (mismo termino, sin importar espacios)
+			clave := c.Term.String()
+			if previo, ok := vistos[clave]; ok {
+				nuevo("duplicado", SeveridadError, "clausula duplicada de "+previo)
+			} else {
+				vistos[clave] = fmt.Sprintf("%s:%d", a.Path, c.Linea)
+			}
+
+			if c.EsRegla() {
+				continue
+			}
+
+			// vocabulario por posicion de argumento
+			for i, arg := range cabeza.Arguments() {
+				if !term.IsAtom(arg) {
+					continue
+				}
+				atomo := arg.(term.Callable).Name()
+				if !esASCII(atomo) {
+					nuevo("no_ascii", SeveridadAviso, fmt.Sprintf("el atomo '%s' (argumento %d) tiene caracteres no ASCII", atomo, i+1))
+				}
+				permitidos := cfg.Vocabulario[ind]
+				if i >= len(permitidos) || len(permitidos[i]) == 0 {
+					continue
+				}
+				if !contiene(permitidos[i], atomo) {
+					nuevo("vocabulario", SeveridadError, fmt.Sprintf("'%s' no esta en el vocabulario del argumento %d: %s",
+						atomo, i+1, strings.Join(permitidos[i], ", ")))
+				}
+			}
+
+			// hechos que violan una regla "nunca"
+			for j, patron := range nunca {
+				if _, err := patron.Unify(term.NewBindings(), cabeza); err == nil {
+					nuevo("conflicto", SeveridadError, fmt.Sprintf("el hecho viola la regla nunca %q", cfg.Nunca[j].Nombre))
+				}
+			}
+		}
+	}
+
+	// aridad inconsistente: mismo nombre con distinta aridad
+	for nombre, ars := range aridades {
+		if len(ars) < 2 {
+			continue
+		}
+		lista := []string{}
+		for n := range ars {
+			lista = append(lista, fmt.Sprint(n))
+		}
+		sort.Strings(lista)
+		for _, a := range archivos {
+			for _, c := range a.Clausulas {
+				if !c.EsDirectiva() && c.Cabeza().Name() == nombre {
+					hallazgos = append(hallazgos, Hallazgo{
+						Archivo: a.Path, Linea: c.Linea, Tipo: "aridad", Severidad: SeveridadAviso,
+						Predicado: c.Indicador(),
+						Mensaje:   fmt.Sprintf("%s se define con aridades distintas (%s)", nombre, strings.Join(lista, ", ")),
+					})
+					break
+				}
+			}
+		}
+	}
+
+	// reglas que llaman a un predicado que nadie define (ni golog)
+	conocidos := predicadosConocidos()
+	for _, a := range archivos {
+		for _, c := range a.Clausulas {
+			if !c.EsRegla() {
+				continue
+			}
+			for _, objetivo := range objetivosCuerpo(term.Body(c.Term)) {
+				ind := objetivo.Indicator()
+				if definidos[ind] || conocidos[ind] {
+					continue
+				}
+				hallazgos = append(hallazgos, Hallazgo{
+					Archivo: a.Path, Linea: c.Linea, Tipo: "indefinido", Severidad: SeveridadAviso,
+					Predicado: c.Indicador(),
+					Mensaje:   fmt.Sprintf("la regla llama a %s, que no esta definido", ind),
+				})
+			}
+		}
+	}
+
+	sort.SliceStable(hallazgos, func(i, j int) bool {
+		if hallazgos[i].Archivo != hallazgos[j].Archivo {
+			return hallazgos[i].Archivo < hallazgos[j].Archivo
+		}
+		return hallazgos[i].Linea < hallazgos[j].Linea
+	})
+	return hallazgos, nil
+}
+
+// objetivosCuerpo aplana conjunciones, disyunciones y negaciones
+func objetivosCuerpo(cuerpo term.Callable) []term.Callable {
+	switch cuerpo.Indicator() {
+	case ",/2", ";/2", "->/2":
+		out := []term.Callable{}
+		for _, arg := range cuerpo.Arguments() {
+			if c, ok := arg.(term.Callable); ok {
+				out = append(out, objetivosCuerpo(c)...)
+			}
+		}
+		return out
+	case "\\+/1":
+		if c, ok := cuerpo.Arguments()[0].(term.Callable); ok {
+			return objetivosCuerpo(c)
+		}
+		return nil
+	}
+	return []term.Callable{cuerpo}
+}
+
+func esASCII(s string) bool {
+	for _, r := range s {
+		if r > unicode.MaxASCII {
+			return false
+		}
+	}
+	return true
+}
+
+func contiene(lista []string, s string) bool {
+	for _, x := range lista {
+		if x == s {
+			return true
+		}
+	}
+	return false
+}
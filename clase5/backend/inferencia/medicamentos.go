@@ -0,0 +1,167 @@
+package inferencia
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/mndrix/golog"
+)
+
+// Rutas por defecto de la base de conocimiento (relativas a backend/)
+const (
+	DefaultConocimientoPath = "./prolog/conocimiento.pl"
+	DefaultInferenciasPath  = "./prolog/inferencias.pl"
+)
+
+// estructura que abstrae el MedicamentoRecomendado
+type MedicamentoRecomendado struct {
+	Urgencia    string
+	Enfermedad  string
+	Cronica     string
+	Pecho       string
+	Respiracion string
+	Medicamento string
+	Match       float64
+}
+
+// CargarProlog lee un archivo .pl completo
+func CargarProlog(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// CargarMaquina levanta una maquina de inferencia con los archivos
+// indicados (hechos + reglas), en el orden en que se reciben.
+func CargarMaquina(paths ...string) (golog.Machine, error) {
+	m := golog.NewMachine()
+	for _, p := range paths {
+		programa, err := CargarProlog(p)
+		if err != nil {
+			return nil, fmt.Errorf("no se pudo leer %s: %w", p, err)
+		}
+		m = m.Consult(programa)
+	}
+	return m, nil
+}
+
+// CargarBase levanta la maquina que usa la API: hechos, reglas de
+// inferencia y, si existe, el archivo compilado desde el DSL.
+func CargarBase() (golog.Machine, error) {
+	paths := []string{DefaultConocimientoPath, DefaultInferenciasPath}
+	if _, err := os.Stat(DefaultReglasDSLPath); err == nil {
+		paths = append(paths, DefaultReglasDSLPath)
+	}
+	return CargarMaquina(paths...)
+}
+
+// MaxMatchMedicamento es el numero de condiciones que puntua
+// RecomendarMedicamentos: 5 condiciones base + 2 extras posibles.
+const MaxMatchMedicamento = 7.0
+
+// RecomendarMedicamentos puntua cada medicamento contraindicado contra
+// el caso del paciente. La consulta respeta ctx y LimitesPorDefecto.
+func RecomendarMedicamentos(
+	ctx context.Context,
+	m golog.Machine,
+	urgencia, enfermedad, cronica, pecho, respiracion string,
+) ([]MedicamentoRecomendado, error) {
+
+	// Usamos la regla de inferencia, no directamente la base de hechos.
+	query := "recomendar_medicamento(Urg, Enf, Cron, Pecho, Resp, Med)."
+	solutions, err := ProveAll(ctx, m, query, LimitesPorDefecto)
+	if err != nil {
+		return nil, err
+	}
+
+	results := []MedicamentoRecomendado{}
+
+	for _, sol := range solutions {
+		urg := sol.ByName_("Urg").String()
+		enf := sol.ByName_("Enf").String()
+		cron := sol.ByName_("Cron").String()
+		pech := sol.ByName_("Pecho").String()
+		resp := sol.ByName_("Resp").String()
+		med := sol.ByName_("Med").String()
+
+		matchCount := 0
+
+		// 1: urgencia
+		if urg == urgencia {
+			matchCount++
+		}
+		// 2: enfermedad
+		if enf == enfermedad {
+			matchCount++
+		}
+		// 3: cronicidad
+		if cron == cronica {
+			matchCount++
+		}
+		// 4: pecho
+		if pech == pecho {
+			matchCount++
+		}
+		// 5: respiración
+		if resp == respiracion {
+			matchCount++
+		}
+
+		// 6: “peso extra” por combinación peligrosa (ejemplos)
+		// alta urgencia + problema respiratorio
+		if urgencia == "alta" && respiracion == "resp_si" {
+			matchCount++
+		}
+		// enfermedad pulmonar crónica + respiración comprometida
+		if (enfermedad == "asma" || enfermedad == "bronquitis" || enfermedad == "enfisema") &&
+			cronica == "cronica_si" && respiracion == "resp_si" {
+			matchCount++
+		}
+
+		// Tenemos 5 condiciones base + 2 extras posibles = divisor 7.0
+		matchPercent := float64(matchCount) / MaxMatchMedicamento * 100.0
+
+		results = append(results, MedicamentoRecomendado{
+			Urgencia:    urg,
+			Enfermedad:  enf,
+			Cronica:     cron,
+			Pecho:       pech,
+			Respiracion: resp,
+			Medicamento: med,
+			Match:       matchPercent,
+		})
+	}
+
+	return results, nil
+}
+
+// Clave identifica el hecho medicamento_contraindicado/6 que produjo
+// el resultado, util para saber que hechos se han ejercitado.
+func (r MedicamentoRecomendado) Clave() string {
+	return fmt.Sprintf("medicamento_contraindicado(%s, %s, %s, %s, %s, %s)",
+		r.Urgencia, r.Enfermedad, r.Cronica, r.Pecho, r.Respiracion, r.Medicamento)
+}
+
+// HechosMedicamentos lista todos los hechos medicamento_contraindicado/6
+// de la base, con la misma Clave que los resultados.
+func HechosMedicamentos(ctx context.Context, m golog.Machine) ([]string, error) {
+	solutions, err := ProveAll(ctx, m, "medicamento_contraindicado(Urg, Enf, Cron, Pecho, Resp, Med).", LimitesPorDefecto)
+	if err != nil {
+		return nil, err
+	}
+	hechos := make([]string, 0, len(solutions))
+	for _, sol := range solutions {
+		hechos = append(hechos, MedicamentoRecomendado{
+			Urgencia:    sol.ByName_("Urg").String(),
+			Enfermedad:  sol.ByName_("Enf").String(),
+			Cronica:     sol.ByName_("Cron").String(),
+			Pecho:       sol.ByName_("Pecho").String(),
+			Respiracion: sol.ByName_("Resp").String(),
+			Medicamento: sol.ByName_("Med").String(),
+		}.Clave())
+	}
+	return hechos, nil
+}
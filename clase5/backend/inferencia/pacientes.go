@@ -0,0 +1,289 @@
+package inferencia
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/mndrix/golog"
+	"github.com/mndrix/golog/term"
+)
+
+// Categorias del motivo de una contraindicacion
+const (
+	CategoriaInteraccion   = "interaccion"
+	CategoriaAlergia       = "alergia"
+	CategoriaEstadoClinico = "estado_clinico"
+)
+
+// Rangos de edad que entiende contraindicado_en_estado/3
+var RangosEdad = []string{"nino", "adolescente", "adulto", "adulto_mayor"}
+
+// DatosPaciente es la informacion opcional del paciente que se cruza
+// con las clases de medicamentos de la base.
+type DatosPaciente struct {
+	Alergias             []string
+	MedicamentosActuales []string
+	RangoEdad            string
+	Embarazo             bool
+}
+
+// Contraindicacion es un medicamento que no se debe dar y por que
+type Contraindicacion struct {
+	Medicamento string `json:"medicamento"`
+	Categoria   string `json:"categoria"`
+	Motivo      string `json:"motivo"`
+	Severidad   string `json:"severidad,omitempty"` // de las reglas del DSL
+}
+
+// ErrDatoInvalido indica que un dato del paciente no es un atomo valido
+var ErrDatoInvalido = errors.New("dato del paciente invalido")
+
+var atomoValido = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// NormalizarAtomo pasa un texto libre ("Adulto Mayor") a atomo Prolog
+// ("adulto_mayor") y falla si no se puede usar de forma segura en una
+// consulta.
+func NormalizarAtomo(s string) (string, error) {
+	a := strings.ToLower(strings.TrimSpace(s))
+	a = strings.Join(strings.Fields(a), "_")
+	if !atomoValido.MatchString(a) {
+		return "", fmt.Errorf("%w: %q, use solo letras minusculas, numeros y _", ErrDatoInvalido, s)
+	}
+	return a, nil
+}
+
+// ContraindicacionesPaciente combina los medicamentos del caso clinico
+// (los de mejor puntaje en resultados) con los que descartan las
+// alergias, la medicacion actual, el rango de edad y el embarazo.
+func ContraindicacionesPaciente(
+	ctx context.Context,
+	m golog.Machine,
+	resultados []MedicamentoRecomendado,
+	p DatosPaciente,
+) ([]Contraindicacion, error) {
+	out := []Contraindicacion{}
+	vistas := map[Contraindicacion]bool{}
+	agregar := func(c Contraindicacion) {
+		if !vistas[c] {
+			vistas[c] = true
+			out = append(out, c)
+		}
+	}
+
+	// 1: caso clinico (urgencia, enfermedad, red flags)
+	mejor := 0.0
+	for _, r := range resultados {
+		if r.Match > mejor {
+			mejor = r.Match
+		}
+	}
+	for _, r := range resultados {
+		if mejor > 0 && r.Match == mejor {
+			c := Contraindicacion{
+				Medicamento: r.Medicamento,
+				Categoria:   CategoriaEstadoClinico,
+				Motivo:      fmt.Sprintf("caso %s/%s (match %.1f%%)", r.Urgencia, r.Enfermedad, r.Match),
+			}
+			// si el hecho viene del DSL, su motivo y severidad
+			sols, err := ProveAll(ctx, m, fmt.Sprintf("detalle_caso(%s, %s, %s, %s, %s, %s, Motivo, Sev).",
+				r.Urgencia, r.Enfermedad, r.Cronica, r.Pecho, r.Respiracion, r.Medicamento), LimitesPorDefecto)
+			if err != nil {
+				return nil, err
+			}
+			for _, sol := range sols {
+				sev := nombreAtomo(sol.ByName_("Sev"))
+				if c.Severidad != "" && indiceSeveridad(sev) <= indiceSeveridad(c.Severidad) {
+					continue
+				}
+				c.Severidad = sev
+				if motivo := nombreAtomo(sol.ByName_("Motivo")); motivo != "" {
+					c.Motivo = motivo
+				}
+			}
+			agregar(c)
+		}
+	}
+
+	// 2: alergias
+	for _, a := range p.Alergias {
+		alergia, err := NormalizarAtomo(a)
+		if err != nil {
+			return nil, err
+		}
+		sols, err := ProveAll(ctx, m, fmt.Sprintf("contraindicado_por_alergia(%s, Med, Clase).", alergia), LimitesPorDefecto)
+		if err != nil {
+			return nil, err
+		}
+		for _, sol := range sols {
+			agregar(Contraindicacion{
+				Medicamento: sol.ByName_("Med").String(),
+				Categoria:   CategoriaAlergia,
+				Motivo:      fmt.Sprintf("alergia a %s (%s)", alergia, sol.ByName_("Clase")),
+			})
+		}
+	}
+
+	// 3: interacciones con la medicacion actual
+	for _, a := range p.MedicamentosActuales {
+		actual, err := NormalizarAtomo(a)
+		if err != nil {
+			return nil, err
+		}
+		sols, err := ProveAll(ctx, m, fmt.Sprintf("contraindicado_por_interaccion(%s, Med, Motivo).", actual), LimitesPorDefecto)
+		if err != nil {
+			return nil, err
+		}
+		for _, sol := range sols {
+			agregar(Contraindicacion{
+				Medicamento: sol.ByName_("Med").String(),
+				Categoria:   CategoriaInteraccion,
+				Motivo:      fmt.Sprintf("%s con %s", sol.ByName_("Motivo"), actual),
+			})
+		}
+	}
+
+	// 4: estado clinico del paciente (rango de edad y embarazo)
+	estados, err := estadosPaciente(p)
+	if err != nil {
+		return nil, err
+	}
+	for _, estado := range estados {
+		sols, err := ProveAll(ctx, m, fmt.Sprintf("contraindicado_por_estado(%s, Med, Motivo).", estado), LimitesPorDefecto)
+		if err != nil {
+			return nil, err
+		}
+		for _, sol := range sols {
+			agregar(Contraindicacion{
+				Medicamento: sol.ByName_("Med").String(),
+				Categoria:   CategoriaEstadoClinico,
+				Motivo:      fmt.Sprintf("%s (%s)", sol.ByName_("Motivo"), estado),
+			})
+		}
+	}
+
+	return out, nil
+}
+
+// estadosPaciente devuelve los estados de contraindicado_en_estado/3 que
+// tiene el paciente: su rango de edad y embarazo.
+func estadosPaciente(p DatosPaciente) ([]string, error) {
+	estados := []string{}
+	if p.RangoEdad != "" {
+		rango, err := NormalizarAtomo(p.RangoEdad)
+		if err != nil {
+			return nil, err
+		}
+		if !contiene(RangosEdad, rango) {
+			return nil, fmt.Errorf("%w: rango_edad %q, use %s", ErrDatoInvalido, p.RangoEdad, strings.Join(RangosEdad, ", "))
+		}
+		estados = append(estados, rango)
+	}
+	if p.Embarazo {
+		estados = append(estados, "embarazo")
+	}
+	return estados, nil
+}
+
+// PredicadosPaciente son los hechos que cruzan los datos del paciente
+var PredicadosPaciente = []string{"alergia_clase", "interaccion", "contraindicado_en_estado"}
+
+// variablesHecho son las variables de una consulta por los argumentos de
+// un predicado de PredicadosPaciente
+func variablesHecho(nombre string) []string {
+	if nombre == "alergia_clase" {
+		return []string{"A", "B"}
+	}
+	return []string{"A", "B", "C"}
+}
+
+// HechosPaciente lista los hechos alergia_clase/2, interaccion/3 y
+// contraindicado_en_estado/3 de la base, con el formato de HechosUsados.
+func HechosPaciente(ctx context.Context, m golog.Machine) ([]string, error) {
+	hechos := []string{}
+	for _, nombre := range PredicadosPaciente {
+		args := variablesHecho(nombre)
+		nuevos, err := listarHechos(ctx, m, nombre, fmt.Sprintf("%s(%s).", nombre, strings.Join(args, ", ")))
+		if err != nil {
+			return nil, err
+		}
+		hechos = append(hechos, nuevos...)
+	}
+	return hechos, nil
+}
+
+// HechosUsados devuelve los hechos de HechosPaciente que cruzan los datos
+// de p en ContraindicacionesPaciente.
+func HechosUsados(ctx context.Context, m golog.Machine, p DatosPaciente) ([]string, error) {
+	consultas := [][]string{}
+	for _, a := range p.Alergias {
+		alergia, err := NormalizarAtomo(a)
+		if err != nil {
+			return nil, err
+		}
+		consultas = append(consultas, []string{"alergia_clase", fmt.Sprintf("A = %s, alergia_clase(A, B).", alergia)})
+	}
+	for _, a := range p.MedicamentosActuales {
+		actual, err := NormalizarAtomo(a)
+		if err != nil {
+			return nil, err
+		}
+		consultas = append(consultas, []string{"interaccion", fmt.Sprintf("clase_medicamento(%s, A), interaccion(A, B, C).", actual)})
+	}
+	estados, err := estadosPaciente(p)
+	if err != nil {
+		return nil, err
+	}
+	for _, estado := range estados {
+		consultas = append(consultas, []string{"contraindicado_en_estado", fmt.Sprintf("A = %s, contraindicado_en_estado(A, B, C).", estado)})
+	}
+
+	hechos := []string{}
+	for _, c := range consultas {
+		nuevos, err := listarHechos(ctx, m, c[0], c[1])
+		if err != nil {
+			return nil, err
+		}
+		hechos = append(hechos, nuevos...)
+	}
+	return hechos, nil
+}
+
+// listarHechos corre consulta y arma un hecho de nombre con las variables
+// de variablesHecho de cada solucion
+func listarHechos(ctx context.Context, m golog.Machine, nombre, consulta string) ([]string, error) {
+	args := variablesHecho(nombre)
+	sols, err := ProveAll(ctx, m, consulta, LimitesPorDefecto)
+	if err != nil {
+		return nil, err
+	}
+	hechos := make([]string, 0, len(sols))
+	for _, sol := range sols {
+		valores := make([]string, len(args))
+		for i, v := range args {
+			valores[i] = sol.ByName_(v).String()
+		}
+		hechos = append(hechos, fmt.Sprintf("%s(%s)", nombre, strings.Join(valores, ", ")))
+	}
+	return hechos, nil
+}
+
+// nombreAtomo devuelve el texto de un atomo sin comillas
+func nombreAtomo(t term.Term) string {
+	if at, ok := t.(term.Callable); ok && at.Arity() == 0 {
+		return at.Name()
+	}
+	return t.String()
+}
+
+// indiceSeveridad ordena las severidades de menor a mayor
+func indiceSeveridad(s string) int {
+	for i, v := range Severidades {
+		if v == s {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,220 @@
+// Package parquet escribe tablas en archivos Parquet sin dependencias
+// externas: un solo row group, una pagina por columna, codificacion PLAIN,
+// sin compresion y todas las columnas obligatorias (sin nulos). Alcanza
+// para exportar predicciones a herramientas que leen Parquet.
+package parquet
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+)
+
+// Tipo de los valores de una columna
+type Tipo int
+
+const (
+	Booleano Tipo = iota // BOOLEAN
+	Entero               // INT64
+	Real                 // DOUBLE
+	Texto                // BYTE_ARRAY (UTF8)
+)
+
+// Columna es una columna con sus valores; se usa la lista que
+// corresponde a Tipo
+type Columna struct {
+	Nombre    string
+	Tipo      Tipo
+	Booleanos []bool
+	Enteros   []int64
+	Reales    []float64
+	Textos    []string
+}
+
+// Valores del formato (parquet.thrift)
+const (
+	tipoBoolean   = 0
+	tipoInt64     = 2
+	tipoDouble    = 5
+	tipoByteArray = 6
+
+	convertidoUTF8 = 0
+	requerida      = 0
+	codificPlain   = 0
+	codificRLE     = 3
+	sinCompresion  = 0
+	paginaDatos    = 0
+)
+
+var magia = []byte("PAR1")
+
+// Filas devuelve la cantidad de valores de la columna
+func (c Columna) Filas() int {
+	switch c.Tipo {
+	case Booleano:
+		return len(c.Booleanos)
+	case Entero:
+		return len(c.Enteros)
+	case Real:
+		return len(c.Reales)
+	}
+	return len(c.Textos)
+}
+
+func (c Columna) tipoFisico() int32 {
+	switch c.Tipo {
+	case Booleano:
+		return tipoBoolean
+	case Entero:
+		return tipoInt64
+	case Real:
+		return tipoDouble
+	}
+	return tipoByteArray
+}
+
+// plain codifica los valores con PLAIN: booleanos de a un bit, numeros
+// en little endian y textos con su largo adelante
+func (c Columna) plain() []byte {
+	var buf bytes.Buffer
+	switch c.Tipo {
+	case Booleano:
+		bits := make([]byte, (len(c.Booleanos)+7)/8)
+		for i, b := range c.Booleanos {
+			if b {
+				bits[i/8] |= 1 << (i % 8)
+			}
+		}
+		buf.Write(bits)
+	case Entero:
+		for _, v := range c.Enteros {
+			buf.Write(binary.LittleEndian.AppendUint64(nil, uint64(v)))
+		}
+	case Real:
+		for _, v := range c.Reales {
+			buf.Write(binary.LittleEndian.AppendUint64(nil, math.Float64bits(v)))
+		}
+	case Texto:
+		for _, s := range c.Textos {
+			buf.Write(binary.LittleEndian.AppendUint32(nil, uint32(len(s))))
+			buf.WriteString(s)
+		}
+	}
+	return buf.Bytes()
+}
+
+// Escribir escribe las columnas como un archivo Parquet. Todas deben
+// tener la misma cantidad de filas y nombres distintos.
+func Escribir(w io.Writer, columnas []Columna) error {
+	if len(columnas) == 0 {
+		return fmt.Errorf("parquet: no hay columnas")
+	}
+	filas := columnas[0].Filas()
+	vistos := map[string]bool{}
+	for _, c := range columnas {
+		if c.Nombre == "" || vistos[c.Nombre] {
+			return fmt.Errorf("parquet: nombre de columna vacio o repetido: '%s'", c.Nombre)
+		}
+		vistos[c.Nombre] = true
+		if c.Tipo < Booleano || c.Tipo > Texto {
+			return fmt.Errorf("parquet: la columna '%s' tiene un tipo desconocido", c.Nombre)
+		}
+		if c.Filas() != filas {
+			return fmt.Errorf("parquet: la columna '%s' tiene %d filas y '%s' %d", c.Nombre, c.Filas(), columnas[0].Nombre, filas)
+		}
+	}
+
+	var archivo bytes.Buffer
+	archivo.Write(magia)
+	offsets := make([]int64, len(columnas))
+	tamanos := make([]int64, len(columnas))
+	for i, c := range columnas {
+		datos := c.plain()
+		encabezado := encabezadoPagina(len(datos), filas)
+		offsets[i] = int64(archivo.Len())
+		tamanos[i] = int64(len(encabezado) + len(datos))
+		archivo.Write(encabezado)
+		archivo.Write(datos)
+	}
+	pie := metadatos(columnas, filas, offsets, tamanos)
+	archivo.Write(pie)
+	archivo.Write(binary.LittleEndian.AppendUint32(nil, uint32(len(pie))))
+	archivo.Write(magia)
+
+	_, err := archivo.WriteTo(w)
+	return err
+}
+
+// encabezadoPagina es el PageHeader de una pagina de datos v1
+func encabezadoPagina(tamano, filas int) []byte {
+	c := &compacto{}
+	c.abrir()
+	c.i32(1, paginaDatos)
+	c.i32(2, int32(tamano))
+	c.i32(3, int32(tamano))
+	c.estructura(5, func() {
+		c.i32(1, int32(filas))
+		c.i32(2, codificPlain)
+		c.i32(3, codificRLE)
+		c.i32(4, codificRLE)
+	})
+	c.cerrar()
+	return c.buf.Bytes()
+}
+
+// metadatos es el FileMetaData del pie del archivo
+func metadatos(columnas []Columna, filas int, offsets, tamanos []int64) []byte {
+	var total int64
+	for _, t := range tamanos {
+		total += t
+	}
+
+	c := &compacto{}
+	c.abrir()
+	c.i32(1, 1)
+	c.lista(2, thriftStruct, len(columnas)+1)
+	c.elemento(func() {
+		c.texto(4, "schema")
+		c.i32(5, int32(len(columnas)))
+	})
+	for _, col := range columnas {
+		c.elemento(func() {
+			c.i32(1, col.tipoFisico())
+			c.i32(3, requerida)
+			c.texto(4, col.Nombre)
+			if col.Tipo == Texto {
+				c.i32(6, convertidoUTF8)
+			}
+		})
+	}
+	c.i64(3, int64(filas))
+	c.lista(4, thriftStruct, 1)
+	c.elemento(func() {
+		c.lista(1, thriftStruct, len(columnas))
+		for i, col := range columnas {
+			c.elemento(func() {
+				c.i64(2, offsets[i])
+				c.estructura(3, func() {
+					c.i32(1, col.tipoFisico())
+					c.lista(2, thriftI32, 1)
+					c.zigzag(codificPlain)
+					c.lista(3, thriftBinary, 1)
+					c.varint(uint64(len(col.Nombre)))
+					c.buf.WriteString(col.Nombre)
+					c.i32(4, sinCompresion)
+					c.i64(5, int64(filas))
+					c.i64(6, tamanos[i])
+					c.i64(7, tamanos[i])
+					c.i64(9, offsets[i])
+				})
+			})
+		}
+		c.i64(2, total)
+		c.i64(3, int64(filas))
+	})
+	c.texto(6, "unmatch/backend")
+	c.cerrar()
+	return c.buf.Bytes()
+}
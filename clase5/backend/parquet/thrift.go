@@ -0,0 +1,93 @@
+package parquet
+
+import (
+	"bytes"
+	"encoding/binary"
+)
+
+// Tipos del protocolo compacto de Thrift (el de los metadatos de Parquet)
+const (
+	thriftI32    = 5
+	thriftI64    = 6
+	thriftBinary = 8
+	thriftList   = 9
+	thriftStruct = 12
+)
+
+// compacto escribe structs de Thrift con el protocolo compacto. Solo
+// tiene lo que usan los metadatos de un archivo Parquet.
+type compacto struct {
+	buf     bytes.Buffer
+	ultimos []int16 // id del ultimo campo de cada struct abierto
+}
+
+func (c *compacto) varint(v uint64) {
+	c.buf.Write(binary.AppendUvarint(nil, v))
+}
+
+func (c *compacto) zigzag(v int64) {
+	c.varint(uint64((v << 1) ^ (v >> 63)))
+}
+
+// campo escribe el encabezado de un campo del struct abierto
+func (c *compacto) campo(id int16, tipo byte) {
+	ultimo := &c.ultimos[len(c.ultimos)-1]
+	if delta := id - *ultimo; delta > 0 && delta <= 15 {
+		c.buf.WriteByte(byte(delta)<<4 | tipo)
+	} else {
+		c.buf.WriteByte(tipo)
+		c.zigzag(int64(id))
+	}
+	*ultimo = id
+}
+
+func (c *compacto) abrir() {
+	c.ultimos = append(c.ultimos, 0)
+}
+
+func (c *compacto) cerrar() {
+	c.buf.WriteByte(0)
+	c.ultimos = c.ultimos[:len(c.ultimos)-1]
+}
+
+func (c *compacto) i32(id int16, v int32) {
+	c.campo(id, thriftI32)
+	c.zigzag(int64(v))
+}
+
+func (c *compacto) i64(id int16, v int64) {
+	c.campo(id, thriftI64)
+	c.zigzag(v)
+}
+
+func (c *compacto) texto(id int16, s string) {
+	c.campo(id, thriftBinary)
+	c.varint(uint64(len(s)))
+	c.buf.WriteString(s)
+}
+
+// estructura escribe un campo struct; escribir completa sus campos
+func (c *compacto) estructura(id int16, escribir func()) {
+	c.campo(id, thriftStruct)
+	c.abrir()
+	escribir()
+	c.cerrar()
+}
+
+// lista escribe el encabezado de un campo lista de n elementos
+func (c *compacto) lista(id int16, tipo byte, n int) {
+	c.campo(id, thriftList)
+	if n < 15 {
+		c.buf.WriteByte(byte(n)<<4 | tipo)
+	} else {
+		c.buf.WriteByte(0xf0 | tipo)
+		c.varint(uint64(n))
+	}
+}
+
+// elemento escribe un struct dentro de una lista
+func (c *compacto) elemento(escribir func()) {
+	c.abrir()
+	escribir()
+	c.cerrar()
+}
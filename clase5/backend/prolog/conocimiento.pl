@@ -34,7 +34,7 @@ medicamento_contraindicado(mediana, apnea, cronica_si, pecho_no, resp_si, opioid
 % Ejemplo de medicamentos que se decide NO usar en este flujo
 
 medicamento_contraindicado(baja, fibromialgia, cronica_si, pecho_no, resp_no, corticoides_sistemicos_prolongados).
-medicamento_contraindicado(baja, migrañas,     cronica_no, pecho_no, resp_no, triptanes_en_antecedente_pulmonar).
+medicamento_contraindicado(baja, migranas,     cronica_no, pecho_no, resp_no, triptanes_en_antecedente_pulmonar).
 
 % Caso: reflujo con dolor de pecho
 % No dar AINEs que puedan agravar síntomas digestivos
@@ -47,3 +47,55 @@ medicamento_contraindicado(baja,    reflujo, cronica_no, pecho_si, resp_no, aine
 medicamento_contraindicado(alta, asma,      cronica_no, pecho_si, resp_si, cualquier_sedante).
 medicamento_contraindicado(alta, bronquitis,cronica_no, pecho_no, resp_si, cualquier_sedante).
 medicamento_contraindicado(alta, enfisema,  cronica_no, pecho_no, resp_si, cualquier_sedante).
+
+% ============================================================
+% CLASES DE MEDICAMENTOS, ALERGIAS E INTERACCIONES
+% ============================================================
+
+% clase_medicamento(Medicamento, Clase).
+% Incluye los medicamentos de arriba y algunos de uso habitual
+% que el paciente puede reportar como medicación actual.
+
+clase_medicamento(sedantes_fuertes,                   sedantes).
+clase_medicamento(cualquier_sedante,                  sedantes).
+clase_medicamento(benzodiacepinas,                    sedantes).
+clase_medicamento(hipnoticos_fuertes,                 sedantes).
+clase_medicamento(diazepam,                           sedantes).
+clase_medicamento(opioides_fuertes,                   opioides).
+clase_medicamento(antitusivos_opioides,               opioides).
+clase_medicamento(codeina,                            opioides).
+clase_medicamento(morfina,                            opioides).
+clase_medicamento(aines_altas_dosis,                  aines).
+clase_medicamento(aines_gastroerosivos,               aines).
+clase_medicamento(ibuprofeno,                         aines).
+clase_medicamento(betabloqueantes_no_selectivos,      betabloqueantes).
+clase_medicamento(propranolol,                        betabloqueantes).
+clase_medicamento(corticoides_sistemicos_prolongados, corticoides).
+clase_medicamento(prednisona,                         corticoides).
+clase_medicamento(triptanes_en_antecedente_pulmonar,  triptanes).
+clase_medicamento(amoxicilina,                        betalactamicos).
+clase_medicamento(warfarina,                          anticoagulantes).
+
+% alergia_clase(Alergia, Clase): la alergia descarta toda la clase
+
+alergia_clase(penicilina, betalactamicos).
+alergia_clase(aspirina,   aines).
+alergia_clase(aines,      aines).
+alergia_clase(opioides,   opioides).
+
+% interaccion(ClaseActual, ClaseNueva, Motivo)
+
+interaccion(opioides,        sedantes, depresion_respiratoria).
+interaccion(sedantes,        opioides, depresion_respiratoria).
+interaccion(sedantes,        sedantes, sedacion_aditiva).
+interaccion(anticoagulantes, aines,    riesgo_de_sangrado).
+interaccion(corticoides,     aines,    ulcera_gastrica).
+
+% contraindicado_en_estado(Estado, Clase, Motivo)
+% Estado: embarazo o rango de edad (nino, adolescente, adulto, adulto_mayor)
+
+contraindicado_en_estado(embarazo,     aines,     riesgo_fetal).
+contraindicado_en_estado(embarazo,     sedantes,  riesgo_fetal).
+contraindicado_en_estado(adulto_mayor, sedantes,  riesgo_de_caidas).
+contraindicado_en_estado(nino,         opioides,  depresion_respiratoria_pediatrica).
+contraindicado_en_estado(adolescente,  opioides,  riesgo_de_dependencia).
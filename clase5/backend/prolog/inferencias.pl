@@ -2,10 +2,28 @@
 recomendar_medicamento(Urg, Enf, Cron, Pecho, Resp, Med) :-
     medicamento_contraindicado(Urg, Enf, Cron, Pecho, Resp, Med).
 
-% Instrucción automática
-:- initialization(main).
+% Alergia a una clase (penicilina) o a un medicamento concreto (amoxicilina)
+contraindicado_por_alergia(Alergia, Med, Clase) :-
+    alergia_clase(Alergia, Clase),
+    clase_medicamento(Med, Clase).
+contraindicado_por_alergia(Alergia, Med, Clase) :-
+    clase_medicamento(Alergia, Clase),
+    clase_medicamento(Med, Clase).
 
-main :-
-    recomendar_medicamento(alta, asma, cronica_si, pecho_si, resp_si, M),
-    format("Medicamento contraindicado: ~w~n", [M]),
-    halt.
+% Interacción entre un medicamento actual y uno nuevo
+contraindicado_por_interaccion(Actual, Med, Motivo) :-
+    clase_medicamento(Actual, ClaseActual),
+    interaccion(ClaseActual, Clase, Motivo),
+    clase_medicamento(Med, Clase).
+
+% Estado clínico del paciente: embarazo o rango de edad
+contraindicado_por_estado(Estado, Med, Motivo) :-
+    contraindicado_en_estado(Estado, Clase, Motivo),
+    clase_medicamento(Med, Clase).
+
+% Motivo y severidad de un caso, de las reglas del DSL (reglas_dsl.pl).
+% La primera clausula solo declara el predicado para cuando no se subio
+% ninguna regla.
+detalle_contraindicacion(_, _, _, _, _, _, _, _, _) :- fail.
+detalle_caso(Urg, Enf, Cron, Pecho, Resp, Med, Motivo, Sev) :-
+    detalle_contraindicacion(_, Urg, Enf, Cron, Pecho, Resp, Med, Motivo, Sev).
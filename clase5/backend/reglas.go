@@ -0,0 +1,130 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/mndrix/golog"
+
+	"unmatch/backend/inferencia"
+)
+
+// La maquina de inferencia se reemplaza completa cuando se suben
+// reglas nuevas; los handlers toman la actual al empezar.
+var (
+	maquinaMu sync.RWMutex
+	maquinaKB golog.Machine
+)
+
+func maquinaActual() golog.Machine {
+	maquinaMu.RLock()
+	defer maquinaMu.RUnlock()
+	return maquinaKB
+}
+
+func setMaquina(m golog.Machine) {
+	maquinaMu.Lock()
+	maquinaKB = m
+	maquinaMu.Unlock()
+}
+
+// obtenerReglasDSL devuelve en YAML las reglas compiladas actualmente
+// (o las de conocimiento.pl si todavia no se subio ninguna).
+func obtenerReglasDSL(c *fiber.Ctx) error {
+	path := inferencia.DefaultReglasDSLPath
+	if _, err := os.Stat(path); err != nil {
+		path = inferencia.DefaultConocimientoPath
+	}
+	programa, err := inferencia.CargarProlog(path)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	clausulas, err := inferencia.LeerClausulas(programa)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	out, err := inferencia.EscribirDSL(inferencia.DescompilarKB(clausulas))
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	c.Set(fiber.HeaderContentType, "application/yaml")
+	return c.Send(out)
+}
+
+// subirReglasDSL compila el DSL recibido, lo valida con kblint junto a
+// conocimiento.pl y, si no hay errores, lo guarda y recarga la maquina.
+func subirReglasDSL(c *fiber.Ctx) error {
+	dsl, err := inferencia.LeerDSL(c.Body())
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	if len(dsl.Reglas) == 0 {
+		return c.Status(400).JSON(fiber.Map{"error": "El documento no tiene reglas."})
+	}
+	programa, err := inferencia.CompilarDSL(dsl, "POST /kb/reglas")
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	// validar antes de tocar la base que esta sirviendo
+	archivos := []inferencia.ArchivoKB{}
+	for _, p := range []string{inferencia.DefaultConocimientoPath, inferencia.DefaultInferenciasPath} {
+		texto, err := inferencia.CargarProlog(p)
+		if err != nil {
+			return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+		}
+		clausulas, err := inferencia.LeerClausulas(texto)
+		if err != nil {
+			return c.Status(500).JSON(fiber.Map{"error": fmt.Sprintf("%s: %v", p, err)})
+		}
+		archivos = append(archivos, inferencia.ArchivoKB{Path: p, Clausulas: clausulas})
+	}
+	nuevas, err := inferencia.LeerClausulas(programa)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	archivos = append(archivos, inferencia.ArchivoKB{Path: inferencia.DefaultReglasDSLPath, Clausulas: nuevas})
+
+	cfg, err := inferencia.CargarConfigLint(inferencia.DefaultLintConfigPath)
+	if err != nil && !os.IsNotExist(err) {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	hallazgos, err := inferencia.Lint(archivos, cfg)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	for _, h := range hallazgos {
+		if h.Severidad == inferencia.SeveridadError {
+			return c.Status(422).JSON(fiber.Map{"error": "Las reglas no pasan kblint.", "hallazgos": hallazgos})
+		}
+	}
+
+	if err := guardarYRecargar(programa); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	fmt.Println("Reglas DSL actualizadas:", len(dsl.Reglas), "reglas")
+
+	return c.JSON(fiber.Map{
+		"mensaje":   "Reglas compiladas y cargadas",
+		"reglas":    len(dsl.Reglas),
+		"hallazgos": hallazgos,
+	})
+}
+
+// guardarYRecargar escribe reglas_dsl.pl y reemplaza la maquina sin
+// soltar maquinaMu en el medio
+func guardarYRecargar(programa string) error {
+	maquinaMu.Lock()
+	defer maquinaMu.Unlock()
+	if err := os.WriteFile(inferencia.DefaultReglasDSLPath, []byte(programa), 0o644); err != nil {
+		return err
+	}
+	m, err := inferencia.CargarBase()
+	if err != nil {
+		return err
+	}
+	maquinaKB = m
+	return nil
+}
@@ -0,0 +1,86 @@
+package sintetico
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// comparacion es "feature op numero"
+type comparacion struct {
+	columna int
+	op      string
+	valor   float64
+}
+
+// condicion es un "o" de "y" de comparaciones:
+// "redflag_pecho == 1 || n_sintomas >= 6 && n_cronicas >= 2"
+type condicion [][]comparacion
+
+var reComparacion = regexp.MustCompile(`^\s*([A-Za-z_][A-Za-z0-9_]*)\s*(<=|>=|==|!=|<|>)\s*(-?[0-9]*\.?[0-9]+)\s*$`)
+
+// compilar interpreta una condicion; las features se buscan en columnas
+// (nombre -> columna de la fila)
+func compilar(texto string, columnas map[string]int) (condicion, error) {
+	if strings.TrimSpace(texto) == "" {
+		return nil, fmt.Errorf("falta la condicion (si)")
+	}
+	out := condicion{}
+	for _, alternativa := range strings.Split(texto, "||") {
+		todas := []comparacion{}
+		for _, parte := range strings.Split(alternativa, "&&") {
+			m := reComparacion.FindStringSubmatch(parte)
+			if m == nil {
+				return nil, fmt.Errorf("'%s' no es una comparacion 'feature op numero'", strings.TrimSpace(parte))
+			}
+			col, ok := columnas[m[1]]
+			if !ok {
+				return nil, fmt.Errorf("la feature '%s' no existe o no esta antes", m[1])
+			}
+			v, _ := strconv.ParseFloat(m[3], 64)
+			todas = append(todas, comparacion{columna: col, op: m[2], valor: v})
+		}
+		out = append(out, todas)
+	}
+	return out, nil
+}
+
+// cumple evalua la condicion sobre una fila; una comparacion con un
+// valor faltante (NaN) no se cumple
+func (c condicion) cumple(fila []float64) bool {
+	for _, todas := range c {
+		ok := true
+		for _, cmp := range todas {
+			if !cmp.cumple(fila[cmp.columna]) {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (c comparacion) cumple(v float64) bool {
+	if math.IsNaN(v) {
+		return false
+	}
+	switch c.op {
+	case "<":
+		return v < c.valor
+	case "<=":
+		return v <= c.valor
+	case ">":
+		return v > c.valor
+	case ">=":
+		return v >= c.valor
+	case "==":
+		return v == c.valor
+	default: // !=
+		return v != c.valor
+	}
+}
@@ -0,0 +1,152 @@
+package sintetico
+
+import (
+	"encoding/csv"
+	"io"
+	"math"
+	"math/rand/v2"
+	"strconv"
+
+	"gonum.org/v1/gonum/mat"
+	"gonum.org/v1/gonum/stat/distuv"
+
+	"unmatch/backend/dataset"
+)
+
+// streamBronco es el segundo valor del generador PCG ("bronco" en ASCII)
+const streamBronco = 0x62726f6e636f
+
+// Generar sortea s.Filas filas con la seed s.Seed (0 tambien es una seed;
+// broncogen elige una al azar si la especificacion no trae). Las celdas
+// faltantes quedan en NaN, como las lee el paquete dataset.
+func Generar(s Spec) (*dataset.Dataset, error) {
+	if err := s.Validar(); err != nil {
+		return nil, err
+	}
+	// el stream no depende de Filas: con mas filas y la misma seed, las
+	// primeras son las mismas
+	rng := rand.New(rand.NewPCG(uint64(s.Seed), streamBronco))
+	X := mat.NewDense(s.Filas, len(s.Features), nil)
+	y := make([]int, s.Filas)
+
+	for i := 0; i < s.Filas; i++ {
+		fila := X.RawRowView(i)
+		for j, f := range s.Features {
+			fila[j] = f.sortear(rng, fila)
+		}
+		y[i] = s.etiquetar(fila)
+		if s.Ruido > 0 && rng.Float64() < s.Ruido {
+			// otra clase cualquiera
+			otra := rng.IntN(len(s.Clases) - 1)
+			if otra >= y[i] {
+				otra++
+			}
+			y[i] = otra
+		}
+		// los faltantes se marcan despues de etiquetar: la etiqueta sale
+		// del valor real, como en un paciente al que no se le midio algo
+		for j, f := range s.Features {
+			if f.Faltantes > 0 && rng.Float64() < f.Faltantes {
+				fila[j] = math.NaN()
+			}
+		}
+	}
+
+	return &dataset.Dataset{
+		Features:   s.Nombres(),
+		Label:      s.Etiqueta,
+		Clases:     append([]string(nil), s.Clases...),
+		X:          X,
+		Y:          y,
+		Categorias: map[string][]string{},
+		Textos:     map[string][]string{},
+		Esquema:    s.Esquema(),
+	}, nil
+}
+
+// sortear da el valor de la feature; fila tiene las anteriores ya
+// sorteadas (para derivada)
+func (f Feature) sortear(rng *rand.Rand, fila []float64) float64 {
+	var v float64
+	switch f.Distribucion {
+	case DistUniforme:
+		v = f.Min + rng.Float64()*(f.Max-f.Min)
+	case DistNormal:
+		v = f.Media + rng.NormFloat64()*f.Desvio
+	case DistBeta:
+		v = distuv.Beta{Alpha: f.A, Beta: f.B, Src: rng}.Rand()
+	case DistPoisson:
+		v = distuv.Poisson{Lambda: f.Media, Src: rng}.Rand()
+	case DistBernoulli:
+		if rng.Float64() < f.P {
+			v = 1
+		}
+	case DistConstante:
+		v = f.Valor
+	case DistDerivada:
+		if f.condicion.cumple(fila) {
+			v = 1
+		}
+	}
+	if (f.Distribucion == DistNormal || f.Distribucion == DistPoisson) && f.Max != 0 {
+		v = math.Min(v, f.Max)
+	}
+	if f.Distribucion == DistNormal && f.Min != 0 {
+		v = math.Max(v, f.Min)
+	}
+	if f.Decimales > 0 {
+		p := math.Pow(10, float64(f.Decimales))
+		v = math.Round(v*p) / p
+	}
+	return v
+}
+
+// etiquetar aplica la primera regla que se cumple
+func (s *Spec) etiquetar(fila []float64) int {
+	for _, r := range s.Reglas {
+		if r.condicion.cumple(fila) {
+			return r.clase
+		}
+	}
+	return s.porDefecto
+}
+
+// Esquema es el esquema del dataset generado: bernoulli y derivada son
+// booleanas, el resto numericas
+func (s *Spec) Esquema() dataset.Esquema {
+	esq := dataset.EsquemaPorDefecto(s.Etiqueta)
+	esq.Delimitador = ","
+	esq.Resto = dataset.TipoNumerico
+	esq.Etiqueta.Clases = append([]string(nil), s.Clases...)
+	for _, f := range s.Features {
+		if f.Distribucion == DistBernoulli || f.Distribucion == DistDerivada {
+			esq.Columnas = append(esq.Columnas, dataset.Columna{Nombre: f.Nombre, Tipo: dataset.TipoBooleano})
+		}
+	}
+	return esq
+}
+
+// EscribirCSV escribe el dataset con encabezado, la etiqueta (indice de
+// la clase) en la ultima columna y los faltantes vacios.
+// Formato columnas: features..., etiqueta
+func EscribirCSV(out io.Writer, d *dataset.Dataset) error {
+	w := csv.NewWriter(out)
+	if err := w.Write(append(append([]string(nil), d.Features...), d.Label)); err != nil {
+		return err
+	}
+	record := make([]string, len(d.Features)+1)
+	for i, yi := range d.Y {
+		for j, v := range d.X.RawRowView(i) {
+			record[j] = ""
+			if !math.IsNaN(v) {
+				record[j] = strconv.FormatFloat(v, 'g', -1, 64)
+			}
+		}
+		record[len(d.Features)] = strconv.Itoa(yi)
+		if err := w.Write(record); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
@@ -0,0 +1,208 @@
+// Package sintetico genera datasets de bronco tan grandes como haga
+// falta a partir de una especificacion declarativa: la distribucion de
+// cada feature y las reglas que dan la urgencia, con ruido en la
+// etiqueta. Con la misma especificacion y seed sale el mismo CSV.
+package sintetico
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultSpecPath es la especificacion de bronco que acompaña al repo
+const DefaultSpecPath = "./algorithms/bronco_sintetico.yaml"
+
+// ErrSpecInvalida: la especificacion no se puede usar para generar
+var ErrSpecInvalida = errors.New("especificacion invalida")
+
+// Distribuciones de las features
+const (
+	DistUniforme  = "uniforme"  // min, max
+	DistNormal    = "normal"    // media, desvio
+	DistBeta      = "beta"      // a, b (valores en [0, 1], para las a_*)
+	DistPoisson   = "poisson"   // media (conteos)
+	DistBernoulli = "bernoulli" // p (0/1)
+	DistConstante = "constante" // valor
+	DistDerivada  = "derivada"  // 1 si se cumple si, 0 si no
+)
+
+// Distribuciones son las distribuciones validas
+var Distribuciones = []string{DistUniforme, DistNormal, DistBeta, DistPoisson, DistBernoulli, DistConstante, DistDerivada}
+
+// Feature es una columna del dataset y como se sortea
+type Feature struct {
+	Nombre       string  `yaml:"nombre" json:"nombre"`
+	Distribucion string  `yaml:"distribucion" json:"distribucion"`
+	Min          float64 `yaml:"min,omitempty" json:"min,omitempty"`
+	Max          float64 `yaml:"max,omitempty" json:"max,omitempty"` // ademas recorta normal y poisson si no es 0
+	Media        float64 `yaml:"media,omitempty" json:"media,omitempty"`
+	Desvio       float64 `yaml:"desvio,omitempty" json:"desvio,omitempty"`
+	A            float64 `yaml:"a,omitempty" json:"a,omitempty"`
+	B            float64 `yaml:"b,omitempty" json:"b,omitempty"`
+	P            float64 `yaml:"p,omitempty" json:"p,omitempty"`
+	Valor        float64 `yaml:"valor,omitempty" json:"valor,omitempty"`
+	// Si es la condicion de derivada, sobre features anteriores
+	Si string `yaml:"si,omitempty" json:"si,omitempty"`
+	// Decimales redondea el valor (0 = sin redondear)
+	Decimales int `yaml:"decimales,omitempty" json:"decimales,omitempty"`
+	// Faltantes es la probabilidad de dejar la celda vacia
+	Faltantes float64 `yaml:"faltantes,omitempty" json:"faltantes,omitempty"`
+
+	condicion condicion
+}
+
+// Regla da la clase cuando se cumple Si
+type Regla struct {
+	Si    string `yaml:"si" json:"si"`
+	Clase string `yaml:"clase" json:"clase"` // nombre o indice
+
+	condicion condicion
+	clase     int
+}
+
+// Spec es la especificacion completa
+type Spec struct {
+	Filas    int       `yaml:"filas" json:"filas"`
+	Seed     int64     `yaml:"seed,omitempty" json:"seed,omitempty"` // 0 = al azar
+	Etiqueta string    `yaml:"etiqueta" json:"etiqueta"`
+	Clases   []string  `yaml:"clases" json:"clases"`
+	Features []Feature `yaml:"features" json:"features"`
+	// Reglas se revisan en orden; la primera que se cumple da la clase
+	Reglas     []Regla `yaml:"reglas" json:"reglas"`
+	PorDefecto string  `yaml:"por_defecto" json:"por_defecto"`
+	// Ruido es la probabilidad de cambiar la etiqueta por otra clase al azar
+	Ruido float64 `yaml:"ruido,omitempty" json:"ruido,omitempty"`
+
+	porDefecto int
+}
+
+// LeerSpec interpreta una especificacion YAML (o JSON) y la valida
+func LeerSpec(data []byte) (Spec, error) {
+	var s Spec
+	if err := yaml.Unmarshal(data, &s); err != nil {
+		return s, fmt.Errorf("%w: %v", ErrSpecInvalida, err)
+	}
+	return s, s.Validar()
+}
+
+// CargarSpec lee la especificacion de path
+func CargarSpec(path string) (Spec, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Spec{}, err
+	}
+	s, err := LeerSpec(data)
+	if err != nil {
+		return s, fmt.Errorf("%s: %w", path, err)
+	}
+	return s, nil
+}
+
+// Validar controla parametros, nombres y reglas, y deja compiladas las
+// condiciones
+func (s *Spec) Validar() error {
+	invalida := func(format string, args ...interface{}) error {
+		return fmt.Errorf("%w: %s", ErrSpecInvalida, fmt.Sprintf(format, args...))
+	}
+	if s.Filas <= 0 {
+		return invalida("filas debe ser mayor a 0")
+	}
+	if s.Etiqueta == "" {
+		return invalida("falta la etiqueta")
+	}
+	if len(s.Clases) < 2 {
+		return invalida("se necesitan al menos 2 clases")
+	}
+	if s.Ruido < 0 || s.Ruido > 1 {
+		return invalida("ruido debe estar entre 0 y 1")
+	}
+	if len(s.Features) == 0 {
+		return invalida("no hay features")
+	}
+
+	vistas := map[string]int{} // nombre -> columna
+	for i := range s.Features {
+		f := &s.Features[i]
+		if _, repetida := vistas[f.Nombre]; f.Nombre == "" || f.Nombre == s.Etiqueta || repetida {
+			return invalida("el nombre de la feature %d falta o se repite: '%s'", i+1, f.Nombre)
+		}
+		if f.Faltantes < 0 || f.Faltantes > 1 {
+			return invalida("%s: faltantes debe estar entre 0 y 1", f.Nombre)
+		}
+		var err error
+		switch f.Distribucion {
+		case DistUniforme:
+			if f.Max <= f.Min {
+				err = fmt.Errorf("max debe ser mayor que min")
+			}
+		case DistNormal:
+			if f.Desvio <= 0 {
+				err = fmt.Errorf("desvio debe ser mayor a 0")
+			}
+		case DistBeta:
+			if f.A <= 0 || f.B <= 0 {
+				err = fmt.Errorf("a y b deben ser mayores a 0")
+			}
+		case DistPoisson:
+			if f.Media <= 0 {
+				err = fmt.Errorf("media debe ser mayor a 0")
+			}
+		case DistBernoulli:
+			if f.P < 0 || f.P > 1 {
+				err = fmt.Errorf("p debe estar entre 0 y 1")
+			}
+		case DistConstante:
+		case DistDerivada:
+			// solo puede mirar features anteriores
+			f.condicion, err = compilar(f.Si, vistas)
+		default:
+			err = fmt.Errorf("distribucion '%s' no existe (use %s)", f.Distribucion, strings.Join(Distribuciones, ", "))
+		}
+		if err != nil {
+			return invalida("%s: %v", f.Nombre, err)
+		}
+		vistas[f.Nombre] = i
+	}
+
+	var err error
+	if s.porDefecto, err = s.clase(s.PorDefecto); err != nil {
+		return invalida("por_defecto: %v", err)
+	}
+	for i := range s.Reglas {
+		r := &s.Reglas[i]
+		if r.condicion, err = compilar(r.Si, vistas); err != nil {
+			return invalida("regla %d: %v", i+1, err)
+		}
+		if r.clase, err = s.clase(r.Clase); err != nil {
+			return invalida("regla %d: %v", i+1, err)
+		}
+	}
+	return nil
+}
+
+// clase acepta el nombre o el indice de una clase
+func (s *Spec) clase(v string) (int, error) {
+	for k, c := range s.Clases {
+		if c == v {
+			return k, nil
+		}
+	}
+	if k, err := strconv.Atoi(v); err == nil && k >= 0 && k < len(s.Clases) {
+		return k, nil
+	}
+	return 0, fmt.Errorf("la clase '%s' no existe (%s)", v, strings.Join(s.Clases, ", "))
+}
+
+// Nombres devuelve los nombres de las features en orden
+func (s *Spec) Nombres() []string {
+	out := make([]string, len(s.Features))
+	for i, f := range s.Features {
+		out[i] = f.Nombre
+	}
+	return out
+}
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"gonum.org/v1/gonum/mat"
+
+	"unmatch/backend/algorithms"
+	"unmatch/backend/dataset"
+	"unmatch/backend/entrenamiento"
+)
+
+// featuresBronco es el orden de las columnas de bronco_dataset.csv; se
+// usa si el modelo cargado no guarda los nombres de sus features.
+var featuresBronco = []string{
+	"a_asma", "a_bronquitis", "a_enfisema", "a_apnea", "a_fibromialgia", "a_migranas", "a_reflujo",
+	"n_sintomas", "n_cronicas", "redflag_pecho", "redflag_respiracion", "tiene_cronicas",
+}
+
+// featuresModelo devuelve los nombres de las features que el modelo lee
+// (sin las indicadoras de faltantes)
+func featuresModelo(model *algorithms.SoftmaxRegression) []string {
+	if len(model.FeatureNames) > 0 {
+		return entrenamiento.EntradaModelo(model)
+	}
+	if nFeatures, _ := model.W.Dims(); nFeatures == len(featuresBronco) {
+		return featuresBronco
+	}
+	return nil
+}
+
+// POST /softmax/train/csv (multipart)
+//
+//	archivo: CSV con encabezado
+//	esquema: YAML/JSON de dataset.Esquema (opcional; sin esquema la
+//	         etiqueta es "label" o urgencia y lo demas es numerico)
+//	imputar: estrategia para los faltantes de todas las features (opcional)
+//	lr, n_iter, reg_lambda, seed: opcionales
+//	validacion, prueba: proporcion de filas apartadas (opcionales, 0 por
+//	         defecto: entrena con todas)
+//
+// Entrena con las mismas reglas de lectura que softmaxctl, registra el
+// experimento y lo promueve al modelo de la API. accuracy es la de la
+// parte evaluada (prueba si hay, si no validacion o entrenamiento).
+func entrenarSoftmaxCSV(c *fiber.Ctx) error {
+	archivo, err := c.FormFile("archivo")
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Suba el CSV en el campo 'archivo'."})
+	}
+
+	esq := dataset.EsquemaPorDefecto(c.FormValue("label", dataset.DefaultLabel))
+	if texto := c.FormValue("esquema"); texto != "" {
+		esq, err = dataset.LeerEsquema([]byte(texto))
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+		}
+	}
+
+	if estrategia := c.FormValue("imputar"); estrategia != "" {
+		esq.Imputacion.Imputacion = dataset.Imputacion{Estrategia: estrategia}
+		if err := esq.Validar(); err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+		}
+	}
+
+	hp := entrenamiento.HiperparametrosPorDefecto
+	nIter := float64(hp.NIter)
+	for campo, destino := range map[string]*float64{"lr": &hp.Lr, "n_iter": &nIter, "reg_lambda": &hp.RegLambda} {
+		if v := c.FormValue(campo); v != "" {
+			if *destino, err = strconv.ParseFloat(v, 64); err != nil {
+				return c.Status(400).JSON(fiber.Map{"error": fmt.Sprintf("%s: '%s' no es un numero", campo, v)})
+			}
+		}
+	}
+	hp.NIter = int(nIter)
+	var prop dataset.Proporciones
+	for campo, destino := range map[string]*float64{"validacion": &prop.Validacion, "prueba": &prop.Prueba} {
+		if v := c.FormValue(campo); v != "" {
+			if *destino, err = strconv.ParseFloat(v, 64); err != nil {
+				return c.Status(400).JSON(fiber.Map{"error": fmt.Sprintf("%s: '%s' no es un numero", campo, v)})
+			}
+		}
+	}
+	if err := prop.Validar(); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	if v := c.FormValue("seed"); v != "" {
+		if hp.Seed, err = strconv.ParseInt(v, 10, 64); err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": fmt.Sprintf("seed: '%s' no es un entero", v)})
+		}
+	}
+	if err := hp.Validar(); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	f, err := archivo.Open()
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	defer f.Close()
+	d, err := dataset.Leer(f, archivo.Filename, esq, false)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	validacion := dataset.Revisar(d)
+	if !validacion.Ok() {
+		return c.Status(400).JSON(fiber.Map{"error": "El dataset no pasa la validacion.", "validacion": validacion})
+	}
+	res, err := entrenamiento.EntrenarParticionado(d, hp, prop)
+	if errors.Is(err, dataset.ErrDatasetInvalido) {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	model := res.Modelo
+	setModelo(model)
+	id, err := registrarSoftmax(res, res.Manifiesto(softmaxModelPath, archivo.Filename, nil))
+	if err != nil {
+		return errorRegistro(c, id, model.Seed, err)
+	}
+	met, evaluado := res.Evaluacion()
+	return c.JSON(fiber.Map{
+		"mensaje":     "Modelo Softmax entrenado",
+		"experimento": id,
+		"accuracy":    met.Accuracy,
+		"evaluado":    evaluado,
+		"metricas":    res.Metricas,
+		"particion":   res.Particion,
+		"seed":        model.Seed,
+		"muestras":    d.Muestras(),
+		"features":    model.FeatureNames,
+		"label":       model.Label,
+		"clases":      model.Classes,
+		"categorias":  d.Categorias,
+		"imputacion":  model.Imputation,
+		"validacion":  validacion,
+	})
+}
+
+// guardarSoftmax guarda el modelo de la API y, al lado, su manifiesto
+func guardarSoftmax(model *algorithms.SoftmaxRegression, manifiesto entrenamiento.Manifiesto) error {
+	if err := model.SaveToFile(softmaxModelPath); err != nil {
+		return err
+	}
+	return manifiesto.Guardar(entrenamiento.RutaManifiesto(softmaxModelPath))
+}
+
+// matrizPorNombre arma X con los registros {feature: valor} en el orden
+// de las features del modelo; las que faltan se imputan si el modelo
+// sabe como.
+func matrizPorNombre(model *algorithms.SoftmaxRegression, registros []map[string]float64) (*mat.Dense, error) {
+	features := featuresModelo(model)
+	if features == nil {
+		return nil, fmt.Errorf("el modelo no guarda los nombres de sus features, envie 'x'")
+	}
+	X, err := dataset.Matriz(features, registros)
+	if err != nil {
+		return nil, err
+	}
+	d := &dataset.Dataset{Features: features, X: X}
+	if err := entrenamiento.Preparar(model, d); err != nil {
+		return nil, err
+	}
+	return d.X, nil
+}
+
+// nombresClases traduce las clases predichas a sus nombres (nil si el
+// modelo no los tiene)
+func nombresClases(model *algorithms.SoftmaxRegression, yPred []int) []string {
+	if len(model.Classes) == 0 {
+		return nil
+	}
+	out := make([]string, len(yPred))
+	for i, y := range yPred {
+		out[i] = model.Classes[y]
+	}
+	return out
+}
+
+func boolAFloat(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}